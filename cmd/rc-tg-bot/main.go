@@ -3,12 +3,16 @@ package main
 import (
 	"context"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/channels"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/rules"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/server"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/services"
 	"github.com/c0dered273/automation-remote-controller/pkg/collections"
@@ -26,20 +30,39 @@ func main() {
 
 	config := server.ReadConfig()
 	logger := loggers.NewLogger(server.LogWriter, config.Logger, "remote-control-tg-bot")
-	s := services.NewServices(config.DatabaseUri, logger)
 	clientsMap := collections.NewConcurrentMap[string, *model.ClientEvents]()
+	s := services.NewServices(serverCtx, config, clientsMap, logger)
 
 	// TG
-	bot := server.NewBotServer(serverCtx, config.BotToken, s, clientsMap, logger)
+	bot := server.NewBotServer(serverCtx, config, s, clientsMap, logger)
 	bot.ServeAndNotify()
 	botNotify := bot.GetNotifyChan()
 
+	// Scheduler
+	s.Scheduler.Start(botNotify)
+
+	// Audit
+	s.AuditService.Start()
+
+	// Notifications
+	s.Dispatcher.Register(channels.NewTelegramChannel(botNotify))
+	notifier := channels.NewNotifier(s.Dispatcher, s.ChannelRepo, s.NotifyService, s.HubService, s.AuditService, logger)
+
+	// Rules
+	ruleEngine := rules.NewEngine(
+		serverCtx, s.RuleRepo, s.HubService, s.PolicyService, s.SceneService, notifier, clientsMap,
+		config.Rules.QueueSize, config.Rules.LoopWindow, config.Rules.LoopMaxFires, logger,
+	)
+	ruleEngine.Start()
+
 	// gRPC
 	listen, err := net.Listen("tcp", ":"+config.Port)
 	if err != nil {
 		logger.Fatal().Err(err).Send()
 	}
-	grpcServer, err := server.NewGRPCServer(serverCtx, config, logger, clientsMap, botNotify, s.UserService)
+	grpcServer, err := server.NewGRPCServer(
+		serverCtx, config, logger, clientsMap, notifier, model.Publishers{ruleEngine, s.LiveBroker}, s.UserService, s.HubService,
+	)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("remote-control-tg-bot: server init error")
 	}
@@ -52,6 +75,18 @@ func main() {
 		}
 	}()
 
+	// HTTP
+	httpServer := server.NewHTTPServer(config, s, notifier, logger)
+	go func() {
+		logger.Info().Msgf("http server started at %v", config.HTTPPort)
+		if err := httpServer.Start(":" + config.HTTPPort); err != nil {
+			if err == http.ErrServerClosed {
+				return
+			}
+			logger.Fatal().Err(err).Send()
+		}
+	}()
+
 	go func() {
 		<-shutdown
 		shutdownCtx, shutdownCancelCtx := context.WithTimeout(serverCtx, 30*time.Second)
@@ -64,6 +99,9 @@ func main() {
 		}()
 
 		grpcServer.GracefulStop()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error().Err(err).Msg("server: http shutdown failed")
+		}
 
 		serverStopCtx()
 		shutdownCancelCtx()
@@ -27,21 +27,22 @@ func main() {
 	logger := loggers.NewLogger(client.LogWriter, config.Logger, "rc-client")
 	sendChan := make(chan model.NotifyEvent, 1)
 	receiveChan := make(chan model.ActionEvent)
+	ackChan := make(chan model.AckEvent, 1)
+	stateReqChan := make(chan model.StateRequest)
+	stateChan := make(chan model.StateEvent, 1)
 
-	serverPolling := client.NewPollService(ctx, sendChan, receiveChan, config, logger)
+	serverPolling := client.NewPollService(ctx, sendChan, receiveChan, ackChan, stateReqChan, stateChan, config, logger)
 	serverPolling.Polling()
 
 	driverManager := plc4go.NewPlcDriverManager()
 	drivers.RegisterModbusTcpDriver(driverManager)
-	conn, err := plc.NewPlcConn(driverManager, config)
-	if err != nil {
-		logger.Fatal().Err(err).Send()
-	}
-	plcPolling := plc.NewPLCPollService(ctx, conn, sendChan, receiveChan, logger)
+	plcs := plc.NewPLCs(ctx, driverManager, config, logger)
+	plcPolling := plc.NewPLCPollService(ctx, plcs, sendChan, receiveChan, ackChan, stateReqChan, stateChan, logger)
 	plcPolling.Polling(config)
 
 	<-shutdown
-	_ = conn.Close()
 	cancel()
+	plcPolling.Wait()
+	_ = plcs.Close()
 	logger.Info().Msg("Client shutting down")
 }
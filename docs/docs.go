@@ -55,40 +55,196 @@ const docTemplate = `{
                 }
             }
         },
-        "/public/users/auth": {
+        "/control/hubs": {
+            "get": {
+                "description": "Возвращает хабы пользователя, его роль и признак подключения клиентского приложения.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "control"
+                ],
+                "summary": "Возвращает хабы пользователя.",
+                "operationId": "controlFindHubs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.HubStatus"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Bot API unavailable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/control/hubs/{hub_name}/devices": {
+            "get": {
+                "description": "Запрашивает у хаба текущие значения устройств. Без параметра device_id возвращаются все устройства хаба.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "control"
+                ],
+                "summary": "Возвращает состояние устройств хаба.",
+                "operationId": "controlReadState",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hub name",
+                        "name": "hub_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Device id",
+                        "name": "device_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.StateEvent"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Hub not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "502": {
+                        "description": "Bot API unavailable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Hub is offline",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Hub did not reply in time",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/control/hubs/{hub_name}/devices/{device_id}/actions": {
             "post": {
-                "description": "Аутентифицирует пользователя по паре логин/пароль и возвращает jwt токен.",
+                "description": "Отправляет команду устройству с учетом правил устройства и ожидает подтверждение выполнения хабом.\nДля устройств, требующих подтверждения, нужно передать confirmed, для устройств с PIN-кодом - pin, для команды SetValue - value.",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "user"
+                    "control"
                 ],
-                "summary": "Аутентифицирует существующего пользователя.",
-                "operationId": "authUser",
+                "summary": "Отправляет команду устройству.",
+                "operationId": "controlExecute",
                 "parameters": [
                     {
-                        "description": "User auth request",
+                        "type": "string",
+                        "description": "Hub name",
+                        "name": "hub_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Device id",
+                        "name": "device_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Command",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/users.UserAuthRequest"
+                            "$ref": "#/definitions/model.CommandRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.AckEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
                     "404": {
-                        "description": "User not found",
+                        "description": "Hub not found",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "428": {
+                        "description": "Action must be confirmed or PIN is required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "PIN entry is locked",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "502": {
+                        "description": "Hub failed to execute action",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Hub is offline",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Hub did not reply in time",
                         "schema": {
                             "type": "string"
                         }
@@ -96,34 +252,149 @@ const docTemplate = `{
                 }
             }
         },
-        "/public/users/register": {
-            "post": {
-                "description": "Регистрирует нового пользователя.",
-                "consumes": [
-                    "application/json"
+        "/events": {
+            "get": {
+                "description": "Передает уведомления, подключение и отключение хабов, состояние устройств и результаты команд.\nКаждое событие содержит id, после переподключения поток продолжается с заголовка Last-Event-ID или параметра last_event_id.\nСобытие ping отправляется периодически для проверки соединения, событие reset означает, что часть событий потеряна.",
+                "produces": [
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "user"
+                    "control"
                 ],
-                "summary": "Регистрирует нового пользователя.",
-                "operationId": "registerUser",
+                "summary": "Поток событий хабов в формате Server-Sent Events.",
+                "operationId": "controlEvents",
                 "parameters": [
                     {
-                        "description": "New user request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Hub name",
+                        "name": "hub",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Event type: notification, hub_online, hub_offline, state, command",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Notification severity",
+                        "name": "severity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Device id",
+                        "name": "device",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last received event id",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last received event id",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/users.NewUserRequest"
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "502": {
+                        "description": "Bot API unavailable",
+                        "schema": {
+                            "type": "string"
                         }
                     }
+                }
+            }
+        },
+        "/history": {
+            "get": {
+                "description": "Возвращает постранично записи журнала по хабам, участником которых является пользователь, от новых к старым.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "history"
+                ],
+                "summary": "Возвращает журнал команд и уведомлений.",
+                "operationId": "findHistory",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hub name",
+                        "name": "hub",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Device id",
+                        "name": "device",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "action",
+                            "notification"
+                        ],
+                        "type": "string",
+                        "description": "Entry kind",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries per page, up to 100",
+                        "name": "per_page",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/audit.HistoryPage"
+                        }
                     },
-                    "409": {
-                        "description": "User already exists",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "string"
                         }
@@ -136,31 +407,1783 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "users.NewUserRequest": {
-            "type": "object",
-            "properties": {
-                "password": {
-                    "type": "string"
-                },
-                "tg_user": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
+        },
+        "/hubs": {
+            "get": {
+                "description": "Возвращает хабы, участником которых является пользователь, и его роль в каждом из них.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hub"
+                ],
+                "summary": "Возвращает хабы пользователя.",
+                "operationId": "findHubs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_user-account_hubs.Hub"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
                 }
             }
         },
-        "users.UserAuthRequest": {
-            "type": "object",
-            "properties": {
-                "password": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
+        "/hubs/{hub_name}/devices": {
+            "get": {
+                "description": "Возвращает устройства хаба, для которых заданы ограничения. Остальными устройствами могут управлять владельцы и операторы без подтверждения.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "device"
+                ],
+                "summary": "Возвращает правила управления устройствами хаба.",
+                "operationId": "findPolicies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hub name",
+                        "name": "hub_name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_user-account_devices.Policy"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/hubs/{hub_name}/devices/{device_id}/policy": {
+            "put": {
+                "description": "Задает минимальную роль для управления устройством, необходимость подтверждения команды и PIN-код.\nЕсли PIN-код не указан, сохраняется прежний PIN-код, для отключения проверки PIN-кода нужно передать clear_pin. Доступно владельцам хаба.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "device"
+                ],
+                "summary": "Задает правила управления устройством.",
+                "operationId": "setPolicy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hub name",
+                        "name": "hub_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Device id",
+                        "name": "device_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Device policy",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/devices.PolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_user-account_devices.Policy"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет ограничения, после чего устройством могут управлять владельцы и операторы без подтверждения. Доступно владельцам хаба.",
+                "tags": [
+                    "device"
+                ],
+                "summary": "Удаляет правила управления устройством.",
+                "operationId": "deletePolicy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hub name",
+                        "name": "hub_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Device id",
+                        "name": "device_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Policy not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/hubs/{hub_name}/invitations": {
+            "post": {
+                "description": "Создает одноразовое приглашение в хаб с указанной ролью. Доступно владельцам хаба.\nПриглашение принимается через POST /invitations/{code} или командой бота /join.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hub"
+                ],
+                "summary": "Создает приглашение в хаб.",
+                "operationId": "newInvitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hub name",
+                        "name": "hub_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Invitation role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hubs.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_user-account_hubs.Invitation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/hubs/{hub_name}/members": {
+            "get": {
+                "description": "Возвращает участников хаба и их роли. Доступно владельцам хаба.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hub"
+                ],
+                "summary": "Возвращает участников хаба.",
+                "operationId": "findMembers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hub name",
+                        "name": "hub_name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_user-account_hubs.Member"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/hubs/{hub_name}/members/{username}": {
+            "put": {
+                "description": "Изменяет роль участника хаба. Доступно владельцам хаба, последнего владельца понизить нельзя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hub"
+                ],
+                "summary": "Изменяет роль участника хаба.",
+                "operationId": "setMemberRole",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hub name",
+                        "name": "hub_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hubs.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Member not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Hub must have at least one owner",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет участника хаба. Доступно владельцам хаба, участник может покинуть хаб сам.",
+                "tags": [
+                    "hub"
+                ],
+                "summary": "Удаляет участника хаба.",
+                "operationId": "removeMember",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hub name",
+                        "name": "hub_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Member not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Hub must have at least one owner",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/hubs/{hub_name}/webhook-tokens": {
+            "post": {
+                "description": "Создает токен, с которым внешние системы (Alertmanager и другие) отправляют оповещения получателям хаба.\nТокен передается в заголовке Authorization: Bearer и возвращается только в этом ответе.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Создает токен входящего webhook.",
+                "operationId": "newWebhookToken",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hub name",
+                        "name": "hub_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Token request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.TokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.Token"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Token already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/invitations/{code}": {
+            "post": {
+                "description": "Добавляет пользователя в хаб с ролью из приглашения.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hub"
+                ],
+                "summary": "Принимает приглашение в хаб.",
+                "operationId": "acceptInvitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invitation code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_user-account_hubs.Invitation"
+                        }
+                    },
+                    "404": {
+                        "description": "Invitation not found or expired",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Already a member",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/users/auth": {
+            "post": {
+                "description": "Аутентифицирует пользователя по паре логин/пароль и возвращает jwt токен.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Аутентифицирует существующего пользователя.",
+                "operationId": "authUser",
+                "parameters": [
+                    {
+                        "description": "User auth request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.UserAuthRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/users/register": {
+            "post": {
+                "description": "Регистрирует нового пользователя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Регистрирует нового пользователя.",
+                "operationId": "registerUser",
+                "parameters": [
+                    {
+                        "description": "New user request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.NewUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "409": {
+                        "description": "User already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rules": {
+            "get": {
+                "description": "Возвращает все правила пользователя вместе с условиями и действиями.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rule"
+                ],
+                "summary": "Возвращает правила автоматизации пользователя.",
+                "operationId": "findRules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_user-account_rules.Rule"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает правило: при событии хаба, удовлетворяющем условиям, по порядку выполняются действия. Пользователь должен иметь доступ ко всем упомянутым хабам и сценариям.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rule"
+                ],
+                "summary": "Создает правило автоматизации.",
+                "operationId": "newRule",
+                "parameters": [
+                    {
+                        "description": "New rule request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rules.RuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_user-account_rules.Rule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Rule already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rules/{rule_id}": {
+            "get": {
+                "description": "Возвращает правило пользователя по идентификатору.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rule"
+                ],
+                "summary": "Возвращает правило автоматизации пользователя.",
+                "operationId": "findRule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule id",
+                        "name": "rule_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_user-account_rules.Rule"
+                        }
+                    },
+                    "404": {
+                        "description": "Rule not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет условия и действия правила, сбрасывает время последнего срабатывания.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rule"
+                ],
+                "summary": "Изменяет правило автоматизации.",
+                "operationId": "updateRule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule id",
+                        "name": "rule_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rule request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rules.RuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_user-account_rules.Rule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Rule not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Rule already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет правило пользователя.",
+                "tags": [
+                    "rule"
+                ],
+                "summary": "Удаляет правило автоматизации.",
+                "operationId": "deleteRule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule id",
+                        "name": "rule_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Rule not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/scenes": {
+            "get": {
+                "description": "Возвращает все сценарии пользователя вместе с шагами.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scene"
+                ],
+                "summary": "Возвращает сценарии пользователя.",
+                "operationId": "findScenes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_user-account_scenes.Scene"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает сценарий для хаба пользователя. Шаги выполняются в указанном порядке.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scene"
+                ],
+                "summary": "Создает сценарий.",
+                "operationId": "newScene",
+                "parameters": [
+                    {
+                        "description": "New scene request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/scenes.SceneRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_user-account_scenes.Scene"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Client not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Scene already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/scenes/{scene_id}": {
+            "get": {
+                "description": "Возвращает сценарий пользователя вместе с шагами.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scene"
+                ],
+                "summary": "Возвращает сценарий пользователя.",
+                "operationId": "findScene",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scene id",
+                        "name": "scene_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_user-account_scenes.Scene"
+                        }
+                    },
+                    "404": {
+                        "description": "Scene not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет параметры и шаги сценария пользователя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scene"
+                ],
+                "summary": "Изменяет сценарий.",
+                "operationId": "updateScene",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scene id",
+                        "name": "scene_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Scene request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/scenes.SceneRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_user-account_scenes.Scene"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Scene not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Scene already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет сценарий пользователя.",
+                "tags": [
+                    "scene"
+                ],
+                "summary": "Удаляет сценарий.",
+                "operationId": "deleteScene",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scene id",
+                        "name": "scene_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Scene not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhook-tokens": {
+            "get": {
+                "description": "Возвращает токены пользователя без значений.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Возвращает токены входящих webhook.",
+                "operationId": "findWebhookTokens",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhooks.Token"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhook-tokens/{token_id}": {
+            "delete": {
+                "description": "Удаляет токен, запросы с ним больше не принимаются.",
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Отзывает токен входящего webhook.",
+                "operationId": "deleteWebhookToken",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Token id",
+                        "name": "token_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Token not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "audit.HistoryPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_user-account_audit.Entry"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "devices.PolicyRequest": {
+            "type": "object",
+            "required": [
+                "min_role"
+            ],
+            "properties": {
+                "clear_pin": {
+                    "description": "ClearPIN отключает проверку PIN-кода, не может быть передан вместе с PIN",
+                    "type": "boolean"
+                },
+                "confirm": {
+                    "type": "boolean"
+                },
+                "min_role": {
+                    "type": "string",
+                    "enum": [
+                        "owner",
+                        "operator"
+                    ]
+                },
+                "pin": {
+                    "type": "string",
+                    "maxLength": 12,
+                    "minLength": 4
+                }
+            }
+        },
+        "hubs.RoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "owner",
+                        "operator",
+                        "viewer"
+                    ]
+                }
+            }
+        },
+        "internal_user-account_audit.Entry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "chat_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "hub_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "description": "Kind тип записи: action или notification",
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "result": {
+                    "description": "Result результат: ok, denied, failed, muted",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "Source источник команды: bot, scene, schedule",
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "tg_user": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_user-account_devices.Policy": {
+            "type": "object",
+            "properties": {
+                "confirm": {
+                    "description": "Confirm команда выполняется только после подтверждения пользователем",
+                    "type": "boolean"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "has_pin": {
+                    "description": "HasPIN перед выполнением команды нужно ввести PIN-код",
+                    "type": "boolean"
+                },
+                "min_role": {
+                    "description": "MinRole минимальная роль участника хаба, которой разрешено управлять устройством",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Role"
+                        }
+                    ]
+                }
+            }
+        },
+        "internal_user-account_hubs.Hub": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/model.Role"
+                }
+            }
+        },
+        "internal_user-account_hubs.Invitation": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "hub_name": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/model.Role"
+                }
+            }
+        },
+        "internal_user-account_hubs.Member": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "$ref": "#/definitions/model.Role"
+                },
+                "tg_user": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_user-account_rules.Rule": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RuleAction"
+                    }
+                },
+                "condition": {
+                    "$ref": "#/definitions/model.RuleCondition"
+                },
+                "cooldown_sec": {
+                    "type": "integer"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_fired_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_user-account_scenes.Scene": {
+            "type": "object",
+            "properties": {
+                "client_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_user-account_scenes.Step"
+                    }
+                },
+                "stop_on_failure": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_user-account_scenes.Step": {
+            "type": "object",
+            "required": [
+                "action",
+                "device_id"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "delay_ms": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "device_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.AckEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/model.Action"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.Action": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4,
+                5
+            ],
+            "x-enum-varnames": [
+                "Empty",
+                "SwitchON",
+                "SwitchOFF",
+                "Toggle",
+                "Pulse",
+                "SetValue"
+            ]
+        },
+        "model.CommandRequest": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
+                    "description": "Action название команды, например SwitchON",
+                    "type": "string"
+                },
+                "confirmed": {
+                    "description": "Confirmed подтверждение команды для устройств, требующих подтверждения",
+                    "type": "boolean"
+                },
+                "pin": {
+                    "description": "PIN PIN-код устройства, если он задан",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Value значение для команды SetValue",
+                    "type": "number"
+                }
+            }
+        },
+        "model.DeviceState": {
+            "type": "object",
+            "properties": {
+                "device_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.HubStatus": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "online": {
+                    "type": "boolean"
+                },
+                "role": {
+                    "$ref": "#/definitions/model.Role"
+                }
+            }
+        },
+        "model.Role": {
+            "type": "string",
+            "enum": [
+                "owner",
+                "operator",
+                "viewer"
+            ],
+            "x-enum-varnames": [
+                "RoleOwner",
+                "RoleOperator",
+                "RoleViewer"
+            ]
+        },
+        "model.RuleAction": {
+            "type": "object",
+            "required": [
+                "type"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "hub": {
+                    "description": "Hub хаб, которому отправляется команда или получателям которого отправляется уведомление",
+                    "type": "string"
+                },
+                "scene_id": {
+                    "type": "integer"
+                },
+                "severity": {
+                    "$ref": "#/definitions/model.Severity"
+                },
+                "text": {
+                    "description": "Text текст уведомления, если не указан, пересылается текст исходного уведомления",
+                    "type": "string"
+                },
+                "type": {
+                    "enum": [
+                        "device",
+                        "notify",
+                        "scene"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.RuleActionType"
+                        }
+                    ]
+                }
+            }
+        },
+        "model.RuleActionType": {
+            "type": "string",
+            "enum": [
+                "device",
+                "notify",
+                "scene"
+            ],
+            "x-enum-varnames": [
+                "RuleActionDevice",
+                "RuleActionNotify",
+                "RuleActionScene"
+            ]
+        },
+        "model.RuleCondition": {
+            "type": "object",
+            "required": [
+                "event"
+            ],
+            "properties": {
+                "alert_id": {
+                    "description": "AlertID шаблон идентификатора источника уведомления (тега), например leak_*",
+                    "type": "string"
+                },
+                "event": {
+                    "enum": [
+                        "notification",
+                        "hub_online",
+                        "hub_offline"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.RuleEvent"
+                        }
+                    ]
+                },
+                "from": {
+                    "description": "From, To окно времени ЧЧ:ММ в часовом поясе владельца правила, интервал может переходить через полночь",
+                    "type": "string"
+                },
+                "hub": {
+                    "description": "Hub хаб - источник события",
+                    "type": "string"
+                },
+                "hubs_offline": {
+                    "description": "HubsOffline хабы, которые должны быть отключены в момент события",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "hubs_online": {
+                    "description": "HubsOnline хабы, которые должны быть подключены в момент события",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "text_contains": {
+                    "description": "TextContains подстрока текста уведомления, регистр не учитывается",
+                    "type": "string"
+                },
+                "text_regexp": {
+                    "description": "TextRegexp регулярное выражение для текста уведомления",
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RuleEvent": {
+            "type": "string",
+            "enum": [
+                "notification",
+                "hub_online",
+                "hub_offline",
+                "state",
+                "command"
+            ],
+            "x-enum-varnames": [
+                "EventNotification",
+                "EventHubOnline",
+                "EventHubOffline",
+                "EventState",
+                "EventCommand"
+            ]
+        },
+        "model.Severity": {
+            "type": "string",
+            "enum": [
+                "low",
+                "normal",
+                "critical"
+            ],
+            "x-enum-varnames": [
+                "SeverityLow",
+                "SeverityNormal",
+                "SeverityCritical"
+            ]
+        },
+        "model.StateEvent": {
+            "type": "object",
+            "properties": {
+                "devices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DeviceState"
+                    }
+                }
+            }
+        },
+        "rules.RuleRequest": {
+            "type": "object",
+            "required": [
+                "actions",
+                "name"
+            ],
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/model.RuleAction"
+                    }
+                },
+                "condition": {
+                    "$ref": "#/definitions/model.RuleCondition"
+                },
+                "cooldown_sec": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "scenes.SceneRequest": {
+            "type": "object",
+            "required": [
+                "client_name",
+                "name",
+                "steps"
+            ],
+            "properties": {
+                "client_name": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "steps": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/internal_user-account_scenes.Step"
+                    }
+                },
+                "stop_on_failure": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "users.NewUserRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "tg_user",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "tg_user": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "users.UserAuthRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhooks.Token": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "hub": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "token": {
+                    "description": "Token значение токена, возвращается только при создании",
+                    "type": "string"
+                }
+            }
+        },
+        "webhooks.TokenRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
                 }
             }
         }
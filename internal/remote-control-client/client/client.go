@@ -132,14 +132,22 @@ func newBidirectionalStream(ctx context.Context, config *configs.RClientConfig,
 }
 
 // PollService сервис отвечает за отправку и прием событий с сервера, с остальными компонентами приложения
-// взаимодействует через приемный и передающий каналы
+// взаимодействует через приемные и передающие каналы
 type PollService struct {
-	ctx         context.Context
-	stream      proto.EventMultiService_EventStreamingClient
-	sendChan    chan model.NotifyEvent
+	ctx    context.Context
+	stream proto.EventMultiService_EventStreamingClient
+	// sendChan уведомления для отправки на сервер
+	sendChan chan model.NotifyEvent
+	// receiveChan команды, полученные с сервера
 	receiveChan chan model.ActionEvent
-	config      *configs.RClientConfig
-	logger      zerolog.Logger
+	// ackChan подтверждения выполнения команд для отправки на сервер
+	ackChan chan model.AckEvent
+	// stateReqChan запросы состояния устройств, полученные с сервера
+	stateReqChan chan model.StateRequest
+	// stateChan ответы на запросы состояния для отправки на сервер
+	stateChan chan model.StateEvent
+	config    *configs.RClientConfig
+	logger    zerolog.Logger
 }
 
 func (s *PollService) reconnect() error {
@@ -152,6 +160,35 @@ func (s *PollService) reconnect() error {
 	return nil
 }
 
+func (s *PollService) send(id string, action proto.Action, payload any) {
+	data, _ := json.Marshal(payload)
+	req := proto.Event{
+		Id:      id,
+		Action:  action,
+		Payload: data,
+	}
+
+	if s.stream == nil {
+		s.logger.Warn().Msg("grpc client: connection error")
+		err := s.reconnect()
+		if err != nil {
+			s.logger.Error().Err(err).Send()
+		}
+		return
+	}
+	err := s.stream.Send(&req)
+	if err != nil {
+		s.logger.Warn().Err(err).Send()
+		err = s.reconnect()
+		if err != nil {
+			s.logger.Error().Err(err).Send()
+		}
+		time.Sleep(reconnectDelay)
+		return
+	}
+	s.logger.Info().Str("action", req.Action.String()).RawJSON("payload", data).Msg("send event")
+}
+
 func (s *PollService) continuousSend() {
 	for {
 		select {
@@ -162,32 +199,12 @@ func (s *PollService) continuousSend() {
 				return
 			}
 		case n := <-s.sendChan:
-			payload, _ := json.Marshal(n)
-			req := proto.Event{
-				Id:      uuid.NewString(),
-				Action:  proto.Action_NOTIFICATION,
-				Payload: payload,
-			}
-
-			if s.stream == nil {
-				s.logger.Warn().Msg("grpc client: connection error")
-				err := s.reconnect()
-				if err != nil {
-					s.logger.Error().Err(err).Send()
-				}
-				continue
-			}
-			err := s.stream.Send(&req)
-			if err != nil {
-				s.logger.Warn().Err(err).Send()
-				err = s.reconnect()
-				if err != nil {
-					s.logger.Error().Err(err).Send()
-				}
-				time.Sleep(reconnectDelay)
-				continue
-			}
-			s.logger.Info().Str("action", req.Action.String()).RawJSON("payload", payload).Msg("send event")
+			s.send(uuid.NewString(), proto.Action_NOTIFICATION, n)
+		case a := <-s.ackChan:
+			// Подтверждение отправляется с идентификатором исходной команды
+			s.send(a.ID, proto.Action_ACK, a)
+		case st := <-s.stateChan:
+			s.send(st.ID, proto.Action_STATE, st)
 		}
 	}
 }
@@ -220,14 +237,27 @@ func (s *PollService) continuousRecv() {
 				continue
 			}
 
-			a := model.ActionEvent{}
-			err = json.Unmarshal(resp.Payload, &a)
-			if err != nil {
-				s.logger.Error().Err(err).Send()
-				continue
-			}
-			s.receiveChan <- a
 			s.logger.Info().Str("action", resp.Action.String()).RawJSON("payload", resp.Payload).Msg("incoming event")
+			switch resp.Action {
+			case proto.Action_SWITCH:
+				a := model.ActionEvent{}
+				err = json.Unmarshal(resp.Payload, &a)
+				if err != nil {
+					s.logger.Error().Err(err).Send()
+					continue
+				}
+				a.ID = resp.Id
+				s.receiveChan <- a
+			case proto.Action_STATE:
+				r := model.StateRequest{}
+				err = json.Unmarshal(resp.Payload, &r)
+				if err != nil {
+					s.logger.Error().Err(err).Send()
+					continue
+				}
+				r.ID = resp.Id
+				s.stateReqChan <- r
+			}
 		}
 	}
 }
@@ -243,6 +273,9 @@ func NewPollService(
 	ctx context.Context,
 	sendChan chan model.NotifyEvent,
 	receiveChan chan model.ActionEvent,
+	ackChan chan model.AckEvent,
+	stateReqChan chan model.StateRequest,
+	stateChan chan model.StateEvent,
 	config *configs.RClientConfig,
 	logger zerolog.Logger,
 ) *PollService {
@@ -251,11 +284,14 @@ func NewPollService(
 		logger.Error().Err(err).Msg("grpc client: server connection error")
 	}
 	return &PollService{
-		ctx:         ctx,
-		stream:      stream,
-		sendChan:    sendChan,
-		receiveChan: receiveChan,
-		config:      config,
-		logger:      logger,
+		ctx:          ctx,
+		stream:       stream,
+		sendChan:     sendChan,
+		receiveChan:  receiveChan,
+		ackChan:      ackChan,
+		stateReqChan: stateReqChan,
+		stateChan:    stateChan,
+		config:       config,
+		logger:       logger,
 	}
 }
@@ -5,7 +5,12 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
+	"github.com/c0dered273/automation-remote-controller/internal/remote-control-client/expr"
 	"github.com/c0dered273/automation-remote-controller/pkg/auth"
 	"github.com/c0dered273/automation-remote-controller/pkg/configs"
 	"github.com/c0dered273/automation-remote-controller/pkg/validators"
@@ -21,7 +26,7 @@ var (
 	// SERVER_ADDR - адрес gRPC сервера к которому необходимо подключиться
 	// CA_CERT - путь к коневому сертификату
 	// CLIENT_CERT - путь к клиентскому сертификату
-	// PLC_URI - строка подключения к контроллеру по протоколу ModBus
+	// PLC_URI - строка подключения к единственному контроллеру по протоколу ModBus, если список plcs не задан
 	envVars = []string{
 		"SERVER_ADDR",
 		"CA_CERT",
@@ -32,36 +37,182 @@ var (
 
 // RClientConfig настройки клиентского приложения
 type RClientConfig struct {
-	Name           string          `mapstructure:"name"`
-	ServerAddr     string          `mapstructure:"server_addr"`
-	CACert         string          `mapstructure:"ca_cert" validate:"required"`
-	ClientCert     string          `mapstructure:"client_cert" validate:"required"`
-	TGUsername     string          `validate:"required"`
-	CertID         string          `validate:"required"`
-	PLCUri         string          `mapstructure:"plc_uri" validate:"required"`
-	Devices        []Devices       `mapstructure:"devices" validate:"required"`
-	Notifications  []Notifications `mapstructure:"notifications" validate:"required"`
+	Name          string          `mapstructure:"name"`
+	ServerAddr    string          `mapstructure:"server_addr"`
+	CACert        string          `mapstructure:"ca_cert" validate:"required"`
+	ClientCert    string          `mapstructure:"client_cert" validate:"required"`
+	TGUsername    string          `validate:"required"`
+	CertID        string          `validate:"required"`
+	PLCUri        string          `mapstructure:"plc_uri" validate:"required_without=PLCs"`
+	PLCs          []PLC           `mapstructure:"plcs" validate:"required_without=PLCUri,dive"`
+	Devices       []Devices       `mapstructure:"devices" validate:"required"`
+	Notifications []Notifications `mapstructure:"notifications" validate:"required,dive"`
+	// Tags адреса тегов по псевдонимам для выражений условий уведомлений, псевдонимы не зависят от регистра
+	Tags           map[string]string `mapstructure:"tags"`
 	configs.Logger `mapstructure:"logger"`
 }
 
+// PLC настройки подключения к контроллеру
+type PLC struct {
+	// Name имя контроллера, на которое ссылаются устройства и уведомления
+	Name string `mapstructure:"name" validate:"required"`
+	// URI строка подключения к контроллеру
+	URI string `mapstructure:"uri" validate:"required"`
+	// MaxOpenConns максимальное количество одновременных соединений с контроллером
+	MaxOpenConns int `mapstructure:"max_open_conns" validate:"gte=0"`
+	// ConnTimeout таймаут установки соединения и выполнения запроса
+	ConnTimeout time.Duration `mapstructure:"conn_timeout" validate:"gte=0"`
+	// HealthInterval период проверки доступности контроллера
+	HealthInterval time.Duration `mapstructure:"health_interval" validate:"gte=0"`
+	// PoolMode режим пула соединений: reuse - соединения переиспользуются, per_request - новое соединение на каждый запрос
+	PoolMode string `mapstructure:"pool_mode" validate:"omitempty,oneof=reuse per_request"`
+	// MaxIdleConns максимальное количество простаивающих соединений в режиме reuse
+	MaxIdleConns int `mapstructure:"max_idle_conns" validate:"gte=0"`
+	// ConnMaxLifetime максимальное время жизни соединения, по истечении соединение закрывается и открывается заново
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime" validate:"gte=0"`
+	// ScanInterval период циклического опроса тегов контроллера
+	ScanInterval time.Duration `mapstructure:"scan_interval" validate:"gte=0"`
+	// MaxTagsPerRequest максимальное количество тегов в одном запросе чтения
+	MaxTagsPerRequest int `mapstructure:"max_tags_per_request" validate:"gte=0"`
+	// Monitor способ получения значений тегов: polling - циклический опрос, subscription - подписка на изменения
+	Monitor string `mapstructure:"monitor" validate:"omitempty,oneof=polling subscription"`
+	// Subscription тип подписки: change_of_state - по изменению, cyclic - с периодом scan_interval
+	Subscription string `mapstructure:"subscription" validate:"omitempty,oneof=change_of_state cyclic"`
+}
+
 // Devices перечень устройств, подключенных к контроллеру
 type Devices struct {
 	// DeviceID Идентификатор устройства, с помощью него осуществляется привязка команды из сообщения к конкретному устройству
 	DeviceID string `mapstructure:"device_id"`
+	// PLC имя контроллера, к которому подключено устройство, можно не указывать, если контроллер один
+	PLC string `mapstructure:"plc"`
+	// Room помещение, используется для группировки устройств в панели управления
+	Room string `mapstructure:"room"`
 	// TagAddress Адрес регистра в контроллере с указанием типа данных
+	// адрес вида holding-register:1:WORD/3 указывает на бит регистра, остальные биты регистра при записи не изменяются
 	TagAddress string `mapstructure:"tag_address"`
-	// Values значение передаваемое в контроллер
+	// Values значение передаваемое в контроллер, для битового выхода - true/false или 1/0
 	Values map[string]string `mapstructure:"values"`
+	// Pulse длительность импульса команды Pulse: выход включается значением SwitchON и через pulse выключается значением SwitchOFF,
+	// без значения команда Pulse устройству недоступна
+	Pulse time.Duration `mapstructure:"pulse" validate:"gte=0"`
+	// Setpoint диапазон и пересчет значения команды SetValue, без настроек команда SetValue устройству недоступна
+	Setpoint *Setpoint `mapstructure:"setpoint"`
+}
+
+// Switchable устройство поддерживает переключение: для него заданы значения SwitchON и SwitchOFF
+func (d Devices) Switchable() (on string, off string, ok bool) {
+	on, hasOn := d.Values["switchon"]
+	off, hasOff := d.Values["switchoff"]
+	return on, off, hasOn && hasOff
+}
+
+// Bit разбирает адрес битового выхода на адрес регистра и номер бита, false - выход занимает регистр целиком
+func (d Devices) Bit() (string, int, bool) {
+	address, bitStr, ok := strings.Cut(d.TagAddress, "/")
+	if !ok {
+		return d.TagAddress, 0, false
+	}
+	bit, err := strconv.Atoi(bitStr)
+	if err != nil {
+		return d.TagAddress, 0, false
+	}
+	return address, bit, true
+}
+
+// checkDevice проверяет номер бита и значения битового выхода
+func checkDevice(d Devices) error {
+	if !strings.Contains(d.TagAddress, "/") {
+		return nil
+	}
+	_, bit, ok := d.Bit()
+	if !ok || bit < 0 || bit > maxBit {
+		return fmt.Errorf("client config: device %s: invalid bit number in tag address %s", d.DeviceID, d.TagAddress)
+	}
+	for action, value := range d.Values {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("client config: device %s: value of %s must be true or false for bit output", d.DeviceID, action)
+		}
+	}
+	return nil
 }
 
 // Notifications описывает события, генерируемы е контроллером
 type Notifications struct {
-	// TagAddress адрес регистра, который генерирует события
+	// Name имя уведомления, если задано, используется как идентификатор тревоги вместо адреса тега
+	Name string `mapstructure:"name"`
+	// PLC имя контроллера, можно не указывать, если контроллер один
+	PLC string `mapstructure:"plc"`
+	// TagAddress адрес регистра, который генерирует события, для условия bit адрес дополняется номером бита: .../0
 	TagAddress string `mapstructure:"tag_address"`
-	// Text текст события
+	// Text шаблоны текста события: true - при срабатывании тревоги, false - при ее снятии, без текста false снятие не сообщается
+	// шаблоны в формате text/template, доступные данные описаны в NotificationData
 	Text map[string]string `mapstructure:"text"`
+	// Device название оборудования, к которому относится уведомление, доступно в шаблоне текста
+	Device string `mapstructure:"device"`
+	// Severity уровень важности события: low, normal, critical
+	Severity string `mapstructure:"severity" validate:"omitempty,oneof=low normal critical"`
+	// Condition условие тревоги: bit - бит регистра установлен, high - значение не ниже threshold, low - значение не выше threshold,
+	// expr - выполняется выражение expression, условие expr выбирается по умолчанию, если задано выражение
+	Condition string `mapstructure:"condition" validate:"omitempty,oneof=bit high low expr"`
+	// Expression выражение условия expr, синтаксис описан в пакете expr
+	Expression string `mapstructure:"expression"`
+	// Threshold порог срабатывания аналоговой тревоги
+	Threshold *float64 `mapstructure:"threshold"`
+	// Hysteresis тревога high снимается при значении ниже threshold - hysteresis, low - выше threshold + hysteresis
+	Hysteresis float64 `mapstructure:"hysteresis" validate:"gte=0"`
+	// Deadband изменения аналогового значения меньше deadband не учитываются
+	Deadband float64 `mapstructure:"deadband" validate:"gte=0"`
+	// Delay тревога срабатывает, если условие выполняется не меньше delay
+	Delay time.Duration `mapstructure:"delay" validate:"gte=0"`
+	// ClearDelay тревога снимается, если условие не выполняется не меньше clear_delay
+	ClearDelay time.Duration `mapstructure:"clear_delay" validate:"gte=0"`
+	// texts разобранные шаблоны Text
+	texts map[string]*template.Template
+	// expr скомпилированное выражение Expression
+	expr *expr.Expr
 }
 
+// ID идентификатор уведомления: имя или адрес тега
+func (n Notifications) ID() string {
+	if len(n.Name) != 0 {
+		return n.Name
+	}
+	return n.TagAddress
+}
+
+// Expr скомпилированное выражение условия expr
+func (n Notifications) Expr() *expr.Expr {
+	return n.expr
+}
+
+// Условия тревог уведомлений
+const (
+	ConditionBit  = "bit"
+	ConditionHigh = "high"
+	ConditionLow  = "low"
+	ConditionExpr = "expr"
+)
+
+const (
+	// DefaultPLCName имя контроллера, заданного через plc_uri
+	DefaultPLCName        = "default"
+	defaultMaxOpenConns   = 4
+	defaultConnTimeout    = 3 * time.Second
+	defaultHealthInterval = 10 * time.Second
+	defaultPoolMode       = "reuse"
+	defaultMaxIdleConns   = 2
+	// defaultConnMaxLifetime соединения периодически пересоздаются, чтобы не упираться в таймауты простоя на стороне контроллера
+	defaultConnMaxLifetime = 10 * time.Minute
+	defaultScanInterval    = 500 * time.Millisecond
+	// defaultMaxTagsPerRequest ограничение размера запроса, большие запросы драйвер может разбить на несколько кадров протокола
+	defaultMaxTagsPerRequest = 16
+	defaultMonitor           = "polling"
+	// maxBit максимальный номер бита в адресе битового выхода
+	maxBit              = 63
+	defaultSubscription = "change_of_state"
+)
+
 func setDefaults() {
 	viper.SetDefault("server_addr", "8080")
 	viper.SetDefault("logger.level", "info")
@@ -95,6 +246,144 @@ func postProcessing(config *RClientConfig) error {
 
 	config.TGUsername = tgName
 	config.CertID = certID
+
+	return setPLCs(config)
+}
+
+// setPLCs заполняет настройки контроллеров значениями по умолчанию и проверяет ссылки устройств и уведомлений на контроллеры
+// строка plc_uri без списка plcs описывает единственный контроллер с именем default
+func setPLCs(config *RClientConfig) error {
+	if len(config.PLCs) != 0 && len(config.PLCUri) != 0 {
+		return errors.New("client config: plc_uri and plcs can't be used together")
+	}
+	if len(config.PLCUri) != 0 {
+		config.PLCs = []PLC{{Name: DefaultPLCName, URI: config.PLCUri}}
+	}
+
+	names := make(map[string]struct{}, len(config.PLCs))
+	for i := range config.PLCs {
+		p := &config.PLCs[i]
+		if _, ok := names[p.Name]; ok {
+			return fmt.Errorf("client config: duplicate plc name <%s>", p.Name)
+		}
+		names[p.Name] = struct{}{}
+		if p.MaxOpenConns == 0 {
+			p.MaxOpenConns = defaultMaxOpenConns
+		}
+		if p.ConnTimeout == 0 {
+			p.ConnTimeout = defaultConnTimeout
+		}
+		if p.HealthInterval == 0 {
+			p.HealthInterval = defaultHealthInterval
+		}
+		if len(p.PoolMode) == 0 {
+			p.PoolMode = defaultPoolMode
+		}
+		if p.MaxIdleConns == 0 {
+			p.MaxIdleConns = defaultMaxIdleConns
+		}
+		if p.ConnMaxLifetime == 0 {
+			p.ConnMaxLifetime = defaultConnMaxLifetime
+		}
+		if p.ScanInterval == 0 {
+			p.ScanInterval = defaultScanInterval
+		}
+		if p.MaxTagsPerRequest == 0 {
+			p.MaxTagsPerRequest = defaultMaxTagsPerRequest
+		}
+		if len(p.Monitor) == 0 {
+			p.Monitor = defaultMonitor
+		}
+		if len(p.Subscription) == 0 {
+			p.Subscription = defaultSubscription
+		}
+	}
+
+	plcName := func(name string, owner string) (string, error) {
+		if len(name) == 0 && len(config.PLCs) == 1 {
+			return config.PLCs[0].Name, nil
+		}
+		if len(name) == 0 {
+			return "", fmt.Errorf("client config: %s: plc is required when several plcs are configured", owner)
+		}
+		if _, ok := names[name]; !ok {
+			return "", fmt.Errorf("client config: %s: unknown plc <%s>", owner, name)
+		}
+		return name, nil
+	}
+	var err error
+	for i := range config.Devices {
+		d := &config.Devices[i]
+		if d.PLC, err = plcName(d.PLC, "device "+d.DeviceID); err != nil {
+			return err
+		}
+		if err = checkDevice(*d); err != nil {
+			return err
+		}
+		if _, _, ok := d.Switchable(); d.Pulse > 0 && !ok {
+			return fmt.Errorf("client config: device %s: pulse requires SwitchON and SwitchOFF values", d.DeviceID)
+		}
+		if err = setSetpoint(d); err != nil {
+			return err
+		}
+	}
+	for i := range config.Notifications {
+		n := &config.Notifications[i]
+		if len(n.ID()) == 0 {
+			return fmt.Errorf("client config: notifications[%d]: name or tag_address is required", i)
+		}
+		if n.PLC, err = plcName(n.PLC, "notification "+n.ID()); err != nil {
+			return err
+		}
+		if err = setCondition(n, config.Tags); err != nil {
+			return err
+		}
+		if err = compileTexts(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setCondition проверяет адрес тега уведомления на соответствие условию тревоги, по умолчанию используется условие bit
+// выражение условия expr компилируется с псевдонимами тегов tags
+func setCondition(n *Notifications, tags map[string]string) error {
+	switch {
+	case len(n.Condition) == 0 && len(n.Expression) != 0:
+		n.Condition = ConditionExpr
+	case len(n.Condition) == 0:
+		n.Condition = ConditionBit
+	}
+	if len(n.Expression) != 0 && n.Condition != ConditionExpr {
+		return fmt.Errorf("client config: notification %s: expression can't be used with condition %s", n.ID(), n.Condition)
+	}
+
+	_, bit, hasBit := strings.Cut(n.TagAddress, "/")
+	switch n.Condition {
+	case ConditionExpr:
+		if len(n.Expression) == 0 {
+			return fmt.Errorf("client config: notification %s: expression is required for condition expr", n.ID())
+		}
+		e, err := expr.Compile(n.Expression, tags)
+		if err != nil {
+			return fmt.Errorf("client config: notification %s: expression %q: %w", n.ID(), n.Expression, err)
+		}
+		n.expr = e
+	case ConditionBit:
+		if num, err := strconv.Atoi(bit); !hasBit || err != nil || num < 0 {
+			return fmt.Errorf("client config: notification %s: tag address must end with bit number", n.ID())
+		}
+	default:
+		if hasBit {
+			return fmt.Errorf("client config: notification %s: bit number can't be used with condition %s", n.ID(), n.Condition)
+		}
+		if len(n.TagAddress) == 0 {
+			return fmt.Errorf("client config: notification %s: tag_address is required for condition %s", n.ID(), n.Condition)
+		}
+		if n.Threshold == nil {
+			return fmt.Errorf("client config: notification %s: threshold is required for condition %s", n.ID(), n.Condition)
+		}
+	}
 	return nil
 }
 
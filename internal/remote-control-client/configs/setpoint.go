@@ -0,0 +1,118 @@
+package configs
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Setpoint настройки аналогового выхода для команды SetValue
+// значение в единицах устройства пересчитывается в единицы регистра: raw = value * scale + offset
+type Setpoint struct {
+	// Min и Max допустимый диапазон значения в единицах устройства
+	Min float64 `mapstructure:"min"`
+	Max float64 `mapstructure:"max"`
+	// Step шаг значения от Min, 0 - шаг не ограничен
+	Step float64 `mapstructure:"step"`
+	// Scale множитель пересчета в единицы регистра, по умолчанию 1
+	Scale float64 `mapstructure:"scale"`
+	// Offset смещение в единицах регистра
+	Offset float64 `mapstructure:"offset"`
+	// Unit единица измерения значения, например °C
+	Unit string `mapstructure:"unit"`
+	// DataType тип данных регистра, по умолчанию тип из адреса тега
+	DataType string `mapstructure:"data_type"`
+}
+
+// rawRange диапазон целочисленного типа данных контроллера и преобразование в тип, ожидаемый драйвером
+type rawRange struct {
+	min     float64
+	max     float64
+	convert func(float64) any
+}
+
+// rawTypes целочисленные типы данных контроллера
+var rawTypes = map[string]rawRange{
+	"SINT":  {math.MinInt8, math.MaxInt8, func(v float64) any { return int8(v) }},
+	"USINT": {0, math.MaxUint8, func(v float64) any { return uint8(v) }},
+	"BYTE":  {0, math.MaxUint8, func(v float64) any { return uint8(v) }},
+	"INT":   {math.MinInt16, math.MaxInt16, func(v float64) any { return int16(v) }},
+	"UINT":  {0, math.MaxUint16, func(v float64) any { return uint16(v) }},
+	"WORD":  {0, math.MaxUint16, func(v float64) any { return uint16(v) }},
+	"DINT":  {math.MinInt32, math.MaxInt32, func(v float64) any { return int32(v) }},
+	"UDINT": {0, math.MaxUint32, func(v float64) any { return uint32(v) }},
+	"DWORD": {0, math.MaxUint32, func(v float64) any { return uint32(v) }},
+	"LINT":  {math.MinInt64, math.MaxInt64, func(v float64) any { return int64(v) }},
+	"ULINT": {0, math.MaxUint64, func(v float64) any { return uint64(v) }},
+	"LWORD": {0, math.MaxUint64, func(v float64) any { return uint64(v) }},
+}
+
+// Raw пересчитывает значение в единицы регистра и приводит его к типу данных регистра,
+// целые значения округляются до ближайшего
+func (s Setpoint) Raw(v float64) (any, error) {
+	raw := v*s.Scale + s.Offset
+	switch s.DataType {
+	case "REAL":
+		if math.Abs(raw) > math.MaxFloat32 {
+			return nil, fmt.Errorf("setpoint: raw value %g is out of range of %s", raw, s.DataType)
+		}
+		return float32(raw), nil
+	case "LREAL":
+		return raw, nil
+	}
+	t, ok := rawTypes[s.DataType]
+	if !ok {
+		return nil, fmt.Errorf("setpoint: unsupported data type %s", s.DataType)
+	}
+	raw = math.Round(raw)
+	// верхние границы 64-битных типов не представимы в float64 точно и округляются вверх
+	if raw < t.min || raw > t.max || (t.max >= math.MaxInt64 && raw >= t.max) {
+		return nil, fmt.Errorf("setpoint: raw value %g is out of range of %s", raw, s.DataType)
+	}
+	return t.convert(raw), nil
+}
+
+// Value пересчитывает значение регистра в единицы устройства
+func (s Setpoint) Value(raw float64) float64 {
+	return (raw - s.Offset) / s.Scale
+}
+
+// tagDataType тип данных из адреса тега вида holding-register:1:INT или holding-register:1:INT[2]
+func tagDataType(address string) string {
+	parts := strings.Split(address, ":")
+	if len(parts) < 3 {
+		return ""
+	}
+	dataType, _, _ := strings.Cut(parts[len(parts)-1], "[")
+	return strings.ToUpper(dataType)
+}
+
+// setSetpoint заполняет настройки уставки значениями по умолчанию и проверяет, что границы диапазона записываются в регистр
+func setSetpoint(d *Devices) error {
+	s := d.Setpoint
+	if s == nil {
+		return nil
+	}
+	if _, _, ok := d.Bit(); ok {
+		return fmt.Errorf("client config: device %s: setpoint can't be used with bit output", d.DeviceID)
+	}
+	if s.Scale == 0 {
+		s.Scale = 1
+	}
+	if len(s.DataType) == 0 {
+		s.DataType = tagDataType(d.TagAddress)
+	}
+	s.DataType = strings.ToUpper(s.DataType)
+	switch {
+	case math.IsNaN(s.Min) || math.IsNaN(s.Max) || s.Min >= s.Max:
+		return fmt.Errorf("client config: device %s: setpoint min must be less than max", d.DeviceID)
+	case s.Step < 0 || s.Step > s.Max-s.Min:
+		return fmt.Errorf("client config: device %s: setpoint step must be between 0 and max - min", d.DeviceID)
+	}
+	for _, v := range []float64{s.Min, s.Max} {
+		if _, err := s.Raw(v); err != nil {
+			return fmt.Errorf("client config: device %s: %w", d.DeviceID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package configs
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSetpointRaw(t *testing.T) {
+	tests := []struct {
+		name     string
+		setpoint Setpoint
+		value    float64
+		want     any
+		wantErr  bool
+	}{
+		{name: "scale and offset", setpoint: Setpoint{Scale: 10, Offset: -5, DataType: "INT"}, value: 21.5, want: int16(210)},
+		{name: "rounds to nearest", setpoint: Setpoint{Scale: 10, DataType: "INT"}, value: 21.46, want: int16(215)},
+		{name: "rounds half away from zero", setpoint: Setpoint{Scale: 1, DataType: "INT"}, value: -2.5, want: int16(-3)},
+		{name: "INT min", setpoint: Setpoint{Scale: 1, DataType: "INT"}, value: math.MinInt16, want: int16(math.MinInt16)},
+		{name: "INT max", setpoint: Setpoint{Scale: 1, DataType: "INT"}, value: math.MaxInt16, want: int16(math.MaxInt16)},
+		{name: "INT above max", setpoint: Setpoint{Scale: 1, DataType: "INT"}, value: math.MaxInt16 + 1, wantErr: true},
+		{name: "INT below min", setpoint: Setpoint{Scale: 1, DataType: "INT"}, value: math.MinInt16 - 1, wantErr: true},
+		{name: "rounding leaves range", setpoint: Setpoint{Scale: 1, DataType: "INT"}, value: math.MaxInt16 + 0.5, wantErr: true},
+		{name: "WORD negative", setpoint: Setpoint{Scale: 1, DataType: "WORD"}, value: -1, wantErr: true},
+		{name: "WORD max", setpoint: Setpoint{Scale: 1, DataType: "WORD"}, value: math.MaxUint16, want: uint16(math.MaxUint16)},
+		{name: "USINT", setpoint: Setpoint{Scale: 2.55, DataType: "USINT"}, value: 100, want: uint8(255)},
+		{name: "DINT", setpoint: Setpoint{Scale: 1000, DataType: "DINT"}, value: -1.5, want: int32(-1500)},
+		{name: "LINT max is not representable", setpoint: Setpoint{Scale: 1, DataType: "LINT"}, value: math.MaxInt64, wantErr: true},
+		{name: "ULINT max is not representable", setpoint: Setpoint{Scale: 1, DataType: "ULINT"}, value: math.MaxUint64, wantErr: true},
+		{name: "REAL", setpoint: Setpoint{Scale: 1, Offset: 0.5, DataType: "REAL"}, value: 21, want: float32(21.5)},
+		{name: "REAL out of range", setpoint: Setpoint{Scale: 1, DataType: "REAL"}, value: math.MaxFloat64, wantErr: true},
+		{name: "LREAL", setpoint: Setpoint{Scale: 0.1, DataType: "LREAL"}, value: 215, want: 21.5},
+		{name: "unsupported type", setpoint: Setpoint{Scale: 1, DataType: "STRING"}, value: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.setpoint.Raw(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Raw(%v) = %v, expected error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Raw(%v) error = %v", tt.value, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Raw(%v) = %v (%T), want %v (%T)", tt.value, got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetSetpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		setpoint Setpoint
+		wantType string
+		wantErr  bool
+	}{
+		{name: "type from address", address: "holding-register:1:INT", setpoint: Setpoint{Min: 5, Max: 30}, wantType: "INT"},
+		{name: "type from array address", address: "holding-register:1:word[2]", setpoint: Setpoint{Min: 0, Max: 100}, wantType: "WORD"},
+		{name: "explicit type", address: "holding-register:1:INT", setpoint: Setpoint{Min: 0, Max: 1, DataType: "real"}, wantType: "REAL"},
+		{name: "min not less than max", address: "holding-register:1:INT", setpoint: Setpoint{Min: 30, Max: 30}, wantErr: true},
+		{name: "negative step", address: "holding-register:1:INT", setpoint: Setpoint{Min: 5, Max: 30, Step: -1}, wantErr: true},
+		{name: "step above range", address: "holding-register:1:INT", setpoint: Setpoint{Min: 5, Max: 30, Step: 26}, wantErr: true},
+		{name: "max out of register range", address: "holding-register:1:INT", setpoint: Setpoint{Min: 0, Max: 100, Scale: 1000}, wantErr: true},
+		{name: "bit output", address: "coil:1:WORD/3", setpoint: Setpoint{Min: 0, Max: 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.setpoint
+			d := &Devices{DeviceID: "heater", TagAddress: tt.address, Setpoint: &s}
+			err := setSetpoint(d)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("setSetpoint() expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setSetpoint() error = %v", err)
+			}
+			if s.Scale == 0 {
+				t.Error("setSetpoint() scale is not set")
+			}
+			if s.DataType != tt.wantType {
+				t.Errorf("setSetpoint() data type = %s, want %s", s.DataType, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package configs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// NotificationData данные, доступные в шаблоне текста уведомления
+// например: "Температура {{.Value | printf "%.1f"}} °C превысила {{.Limit}}"
+type NotificationData struct {
+	// Value значение тега уведомления: bool для условия bit и результата выражения expr, float64 для аналоговых условий
+	Value any
+	// Limit порог срабатывания аналоговой тревоги
+	Limit float64
+	// Active true - тревога сработала, false - снята
+	Active bool
+	// Tags значения тегов контроллера, полученные вместе со значением тега уведомления, по адресам:
+	// {{index .Tags "holding-register:2:INT"}}
+	Tags map[string]any
+	// Device название оборудования из настроек уведомления
+	Device string
+	// PLC имя контроллера
+	PLC string
+	// Time время события
+	Time time.Time
+}
+
+// Render формирует текст уведомления для data.Active, false - текст для этого состояния не задан
+func (n Notifications) Render(data NotificationData) (string, bool, error) {
+	t, ok := n.texts[strconv.FormatBool(data.Active)]
+	if !ok {
+		return "", false, nil
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", true, fmt.Errorf("notification %s: %w", n.ID(), err)
+	}
+	return sb.String(), true, nil
+}
+
+// compileTexts разбирает шаблоны текстов уведомления и проверяет их на тестовых данных,
+// чтобы ошибка в шаблоне обнаружилась при загрузке конфигурации, а не при срабатывании тревоги
+func compileTexts(n *Notifications) error {
+	sample := NotificationData{
+		Value:  false,
+		PLC:    n.PLC,
+		Device: n.Device,
+		Tags:   map[string]any{},
+		Time:   time.Now(),
+	}
+	if n.Threshold != nil {
+		sample.Value = *n.Threshold
+		sample.Limit = *n.Threshold
+	}
+
+	n.texts = make(map[string]*template.Template, len(n.Text))
+	for key, text := range n.Text {
+		if key != "true" && key != "false" {
+			return fmt.Errorf("client config: notification %s: unknown text key <%s>, expected true or false", n.ID(), key)
+		}
+		t, err := template.New(key).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			return fmt.Errorf("client config: notification %s: text %s: %w", n.ID(), key, err)
+		}
+		sample.Active = key == "true"
+		if err := t.Execute(&strings.Builder{}, sample); err != nil {
+			return fmt.Errorf("client config: notification %s: text %s: %w", n.ID(), key, err)
+		}
+		n.texts[key] = t
+	}
+	return nil
+}
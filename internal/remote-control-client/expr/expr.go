@@ -0,0 +1,214 @@
+// Package expr язык выражений для условий уведомлений клиентского приложения.
+//
+// Выражение вычисляется по значениям тегов контроллера и возвращает true или false, например:
+//
+//	pump && pressure < 2.5
+//	ton(tag("holding-register:1:WORD")[3] && !tag("coil:5"), 10s)
+//
+// Значения тегов и результаты операций - числа, логические значения представлены числами 1 и 0,
+// любое ненулевое число считается истинным.
+// Теги задаются псевдонимами из настроек или функцией tag("адрес"), адрес вида .../N и оператор [N]
+// выделяют N-й бит значения. Таймеры ton(условие, задержка) - истина, если условие выполняется не меньше задержки,
+// tof(условие, задержка) - истина, пока условие выполняется, и еще задержку после его снятия.
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// maxBit максимальный номер бита для выделения из значения тега
+const maxBit = 63
+
+var ErrNoValue = errors.New("expr: tag has no value")
+
+// Lookup возвращает числовое значение тега по адресу, false - значение не получено
+type Lookup func(address string) (float64, bool)
+
+// Expr скомпилированное выражение
+// выражение хранит состояние таймеров, поэтому каждое условие должно использовать свой экземпляр
+type Expr struct {
+	src  string
+	root node
+}
+
+// String исходный текст выражения
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Addresses адреса тегов, на которые ссылается выражение, без номеров битов и повторов
+func (e *Expr) Addresses() []string {
+	seen := make(map[string]struct{})
+	walk(e.root, func(n node) {
+		if ref, ok := n.(*tagRef); ok {
+			seen[ref.address] = struct{}{}
+		}
+	})
+	addresses := make([]string, 0, len(seen))
+	for address := range seen {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// Eval вычисляет выражение на момент now, все таймеры выражения обновляются при каждом вычислении
+func (e *Expr) Eval(lookup Lookup, now time.Time) (bool, error) {
+	v, err := e.root.eval(lookup, now)
+	if err != nil {
+		return false, err
+	}
+	return v != 0, nil
+}
+
+// Compile разбирает выражение, aliases - адреса тегов по псевдонимам, псевдонимы не зависят от регистра
+func Compile(src string, aliases map[string]string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, aliases: aliases}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, p.errorf("unexpected symbol")
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+type node interface {
+	eval(lookup Lookup, now time.Time) (float64, error)
+}
+
+// walk обходит узлы выражения
+func walk(n node, fn func(node)) {
+	fn(n)
+	switch n := n.(type) {
+	case *not:
+		walk(n.x, fn)
+	case *bitOf:
+		walk(n.x, fn)
+	case *timer:
+		walk(n.x, fn)
+	case *binary:
+		walk(n.left, fn)
+		walk(n.right, fn)
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+type number struct {
+	v float64
+}
+
+func (n *number) eval(Lookup, time.Time) (float64, error) {
+	return n.v, nil
+}
+
+type tagRef struct {
+	address string
+}
+
+func (n *tagRef) eval(lookup Lookup, _ time.Time) (float64, error) {
+	v, ok := lookup(n.address)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrNoValue, n.address)
+	}
+	return v, nil
+}
+
+type bitOf struct {
+	x   node
+	bit int
+}
+
+func (n *bitOf) eval(lookup Lookup, now time.Time) (float64, error) {
+	v, err := n.x.eval(lookup, now)
+	if err != nil {
+		return 0, err
+	}
+	return boolValue(int64(v)>>n.bit&1 == 1), nil
+}
+
+type not struct {
+	x node
+}
+
+func (n *not) eval(lookup Lookup, now time.Time) (float64, error) {
+	v, err := n.x.eval(lookup, now)
+	if err != nil {
+		return 0, err
+	}
+	return boolValue(v == 0), nil
+}
+
+// binary логические операции и сравнения
+// обе части вычисляются всегда, чтобы таймеры в правой части не пропускали вычисления
+type binary struct {
+	op    string
+	left  node
+	right node
+}
+
+func (n *binary) eval(lookup Lookup, now time.Time) (float64, error) {
+	l, lErr := n.left.eval(lookup, now)
+	r, rErr := n.right.eval(lookup, now)
+	if err := errors.Join(lErr, rErr); err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case "&&":
+		return boolValue(l != 0 && r != 0), nil
+	case "||":
+		return boolValue(l != 0 || r != 0), nil
+	case "==":
+		return boolValue(l == r), nil
+	case "!=":
+		return boolValue(l != r), nil
+	case "<":
+		return boolValue(l < r), nil
+	case "<=":
+		return boolValue(l <= r), nil
+	case ">":
+		return boolValue(l > r), nil
+	case ">=":
+		return boolValue(l >= r), nil
+	}
+	return 0, fmt.Errorf("expr: unknown operator %s", n.op)
+}
+
+// timer таймер с задержкой включения (ton) или выключения (tof)
+type timer struct {
+	off   bool
+	x     node
+	d     time.Duration
+	on    bool
+	since time.Time
+}
+
+func (n *timer) eval(lookup Lookup, now time.Time) (float64, error) {
+	v, err := n.x.eval(lookup, now)
+	if err != nil {
+		return 0, err
+	}
+	in := v != 0
+	if in != n.on {
+		n.on = in
+		n.since = now
+	}
+	if n.off {
+		return boolValue(in || (!n.since.IsZero() && now.Sub(n.since) < n.d)), nil
+	}
+	return boolValue(in && now.Sub(n.since) >= n.d), nil
+}
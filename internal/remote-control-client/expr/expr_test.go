@@ -0,0 +1,251 @@
+package expr
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testAliases = map[string]string{
+	"pump":     "coil:1",
+	"pressure": "holding-register:2:REAL",
+	"status":   "holding-register:3:WORD",
+	"alarm":    "holding-register:3:WORD/4",
+}
+
+func testLookup(tags map[string]float64) Lookup {
+	return func(address string) (float64, bool) {
+		v, ok := tags[address]
+		return v, ok
+	}
+}
+
+func TestEval(t *testing.T) {
+	tags := map[string]float64{
+		"coil:1":                   1,
+		"holding-register:2:REAL":  2.5,
+		"holding-register:3:WORD":  0b10010,
+		"holding-register:4:DWORD": 0,
+	}
+	tests := []struct {
+		name string
+		src  string
+		want bool
+	}{
+		{name: "and binds tighter than or", src: "true || false && false", want: true},
+		{name: "and binds tighter than or on the left", src: "false && false || true", want: true},
+		{name: "parentheses override precedence", src: "(true || false) && false", want: false},
+		{name: "not binds tighter than and", src: "!false && false", want: false},
+		{name: "not applies to comparison", src: "!pressure < 2", want: true},
+		{name: "comparison binds tighter than and", src: "pump && pressure < 3", want: true},
+		{name: "comparison binds tighter than or", src: "pressure > 3 || pressure == 2.5", want: true},
+		{name: "equality", src: "pressure == 2.5", want: true},
+		{name: "inequality", src: "pressure != 2.5", want: false},
+		{name: "less or equal at boundary", src: "pressure <= 2.5", want: true},
+		{name: "greater or equal at boundary", src: "pressure >= 2.5", want: true},
+		{name: "negative number", src: "-1 < pressure", want: true},
+		{name: "bit operator", src: "status[1] && status[4] && !status[0]", want: true},
+		{name: "bit in tag address", src: "alarm", want: true},
+		{name: "tag function", src: `tag("holding-register:3:WORD")[1]`, want: true},
+		{name: "tag function with bit", src: `tag("holding-register:3:WORD/2")`, want: false},
+		{name: "aliases ignore case", src: "PUMP && Pressure > 2", want: true},
+		{name: "nonzero number is true", src: "status", want: true},
+		{name: "zero is false", src: `tag("holding-register:4:DWORD")`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Compile(tt.src, testAliases)
+			if err != nil {
+				t.Fatalf("Compile(%q) error = %v", tt.src, err)
+			}
+			got, err := e.Eval(testLookup(tags), time.Now())
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tt.src, err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval(%q) = %v, want %v", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "unexpected symbol", src: "pump & pressure", want: "pos 6: unexpected symbol '&'"},
+		{name: "unterminated string", src: `tag("coil:1)`, want: "pos 5: unterminated string"},
+		{name: "invalid duration", src: "ton(pump, 10x)", want: "pos 11: invalid duration 10x"},
+		{name: "invalid number", src: "pressure > 1.2.3", want: "pos 12: invalid number 1.2.3"},
+		{name: "missing operand", src: "pump &&", want: "pos 8: expected value, found end of expression"},
+		{name: "missing closing parenthesis", src: "(pump || alarm", want: "pos 15: expected ), found end of expression"},
+		{name: "trailing token", src: "pump pressure", want: "pos 6: unexpected symbol, found pressure"},
+		{name: "chained comparison", src: "1 < pressure < 3", want: "pos 14: unexpected symbol, found <"},
+		{name: "unknown alias", src: "pump && valve", want: "pos 9: unknown tag alias valve"},
+		{name: "bit out of range", src: "status[64]", want: "pos 8: expected bit number 0..63, found 64"},
+		{name: "fractional bit", src: "status[1.5]", want: "pos 8: expected bit number 0..63, found 1.5"},
+		{name: "tag without quotes", src: "tag(coil)", want: "pos 5: expected tag address in quotes, found coil"},
+		{name: "empty tag address", src: `tag("")`, want: "pos 5: empty tag address"},
+		{name: "invalid bit in address", src: `tag("coil:1/x")`, want: "pos 5: invalid bit number in tag address coil:1/x"},
+		{name: "timer without duration", src: "ton(pump, 10)", want: "pos 11: expected duration, found 10"},
+		{name: "timer without comma", src: "tof(pump 10s)", want: "pos 10: expected ,, found 10s"},
+		{name: "minus before alias", src: "-pressure", want: "pos 2: expected number, found pressure"},
+		{name: "positions count runes", src: `tag("насос") & pump`, want: "pos 14: unexpected symbol '&'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.src, testAliases)
+			if err == nil {
+				t.Fatalf("Compile(%q) expected error", tt.src)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("Compile(%q) error = %q, want %q", tt.src, err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalNoValue(t *testing.T) {
+	e, err := Compile("pump || pressure > 1", testAliases)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.Eval(testLookup(map[string]float64{"coil:1": 1}), time.Now())
+	if !errors.Is(err, ErrNoValue) {
+		t.Fatalf("Eval() error = %v, want %v", err, ErrNoValue)
+	}
+	if !strings.Contains(err.Error(), "holding-register:2:REAL") {
+		t.Errorf("Eval() error = %v, want tag address", err)
+	}
+}
+
+func TestAddresses(t *testing.T) {
+	e, err := Compile(`pump && alarm || status[0] || tag("coil:1") || ton(tag("coil:9/1"), 1s)`, testAliases)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"coil:1", "coil:9", "holding-register:3:WORD"}
+	if got := e.Addresses(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Addresses() = %v, want %v", got, want)
+	}
+}
+
+func TestTimers(t *testing.T) {
+	type step struct {
+		at   time.Duration
+		in   bool
+		want bool
+	}
+	tests := []struct {
+		name  string
+		src   string
+		steps []step
+	}{
+		{
+			name: "ton turns on after delay",
+			src:  "ton(pump, 10s)",
+			steps: []step{
+				{at: 0, in: true, want: false},
+				{at: 9999 * time.Millisecond, in: true, want: false},
+				{at: 10 * time.Second, in: true, want: true},
+				{at: 11 * time.Second, in: false, want: false},
+			},
+		},
+		{
+			name: "ton restarts when input drops",
+			src:  "ton(pump, 10s)",
+			steps: []step{
+				{at: 0, in: true, want: false},
+				{at: 5 * time.Second, in: false, want: false},
+				{at: 6 * time.Second, in: true, want: false},
+				{at: 15 * time.Second, in: true, want: false},
+				{at: 16 * time.Second, in: true, want: true},
+			},
+		},
+		{
+			name: "ton stays off while input is off",
+			src:  "ton(pump, 1s)",
+			steps: []step{
+				{at: 0, in: false, want: false},
+				{at: time.Hour, in: false, want: false},
+			},
+		},
+		{
+			name: "tof is off before input first turns on",
+			src:  "tof(pump, 10s)",
+			steps: []step{
+				{at: 0, in: false, want: false},
+				{at: time.Second, in: false, want: false},
+			},
+		},
+		{
+			name: "tof holds for delay after input drops",
+			src:  "tof(pump, 10s)",
+			steps: []step{
+				{at: 0, in: true, want: true},
+				{at: 5 * time.Second, in: false, want: true},
+				{at: 14999 * time.Millisecond, in: false, want: true},
+				{at: 15 * time.Second, in: false, want: false},
+			},
+		},
+		{
+			name: "tof restarts delay when input returns",
+			src:  "tof(pump, 10s)",
+			steps: []step{
+				{at: 0, in: true, want: true},
+				{at: time.Second, in: false, want: true},
+				{at: 5 * time.Second, in: true, want: true},
+				{at: 6 * time.Second, in: false, want: true},
+				{at: 15 * time.Second, in: false, want: true},
+				{at: 16 * time.Second, in: false, want: false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Compile(tt.src, testAliases)
+			if err != nil {
+				t.Fatal(err)
+			}
+			start := time.Now()
+			for i, s := range tt.steps {
+				tags := map[string]float64{"coil:1": 0}
+				if s.in {
+					tags["coil:1"] = 1
+				}
+				got, err := e.Eval(testLookup(tags), start.Add(s.at))
+				if err != nil {
+					t.Fatalf("step #%d: Eval() error = %v", i, err)
+				}
+				if got != s.want {
+					t.Errorf("step #%d at %v: Eval() = %v, want %v", i, s.at, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTimerWithoutShortCircuit(t *testing.T) {
+	e, err := Compile("pump || ton(pressure > 2, 10s)", testAliases)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	tags := map[string]float64{"coil:1": 1, "holding-register:2:REAL": 3}
+	if _, err := e.Eval(testLookup(tags), start); err != nil {
+		t.Fatal(err)
+	}
+	// таймер правой части запущен, хотя результат определила левая часть
+	tags["coil:1"] = 0
+	got, err := e.Eval(testLookup(tags), start.Add(10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got {
+		t.Errorf("Eval() = %v, want true", got)
+	}
+}
@@ -0,0 +1,315 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokDuration
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+	num  float64
+	dur  time.Duration
+}
+
+// operators операторы языка, двухсимвольные проверяются первыми
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "-", "(", ")", "[", "]", ","}
+
+// lex разбивает выражение на лексемы, позиции считаются в символах с единицы
+func lex(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// число с единицей измерения времени: 10s, 1m30s, 500ms
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			if strings.IndexFunc(text, unicode.IsLetter) >= 0 {
+				d, err := time.ParseDuration(text)
+				if err != nil {
+					return nil, fmt.Errorf("pos %d: invalid duration %s", start+1, text)
+				}
+				tokens = append(tokens, token{kind: tokDuration, text: text, pos: start + 1, dur: d})
+				continue
+			}
+			n, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("pos %d: invalid number %s", start+1, text)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, pos: start + 1, num: n})
+		case r == '"':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				i++
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("pos %d: unterminated string", start+1)
+			}
+			i++
+			tokens = append(tokens, token{kind: tokString, text: string(runes[start+1 : i-1]), pos: start + 1})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: strings.ToLower(string(runes[start:i])), pos: start + 1})
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(string(runes[i:]), o) {
+					op = o
+					break
+				}
+			}
+			if len(op) == 0 {
+				return nil, fmt.Errorf("pos %d: unexpected symbol %q", i+1, r)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i + 1})
+			i += len([]rune(op))
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(runes) + 1}), nil
+}
+
+// parser разбор выражения методом рекурсивного спуска
+//
+//	or      = and { "||" and }
+//	and     = not { "&&" not }
+//	not     = "!" not | cmp
+//	cmp     = postfix [ ("==" | "!=" | "<" | "<=" | ">" | ">=") postfix ]
+//	postfix = primary { "[" number "]" }
+//	primary = number | "true" | "false" | "-" number | alias | call | "(" or ")"
+//	call    = "tag" "(" string ")" | ("ton" | "tof") "(" or "," duration ")"
+type parser struct {
+	tokens  []token
+	pos     int
+	aliases map[string]string
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		return p.errorf("expected %s", op)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	t := p.peek()
+	found := t.text
+	if t.kind == tokEOF {
+		found = "end of expression"
+	}
+	return fmt.Errorf("pos %d: %s, found %s", t.pos, fmt.Sprintf(format, args...), found)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.accept("!") {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &not{x: x}, nil
+	}
+	return p.parseCmp()
+}
+
+func (p *parser) parseCmp() (node, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.parsePostfix()
+			if err != nil {
+				return nil, err
+			}
+			return &binary{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("[") {
+		t := p.peek()
+		if t.kind != tokNumber || t.num != float64(int(t.num)) || t.num < 0 || t.num > maxBit {
+			return nil, p.errorf("expected bit number 0..%d", maxBit)
+		}
+		p.next()
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		x = &bitOf{x: x, bit: int(t.num)}
+	}
+	return x, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokNumber:
+		p.next()
+		return &number{v: t.num}, nil
+	case t.kind == tokOp && t.text == "-":
+		p.next()
+		n := p.peek()
+		if n.kind != tokNumber {
+			return nil, p.errorf("expected number")
+		}
+		p.next()
+		return &number{v: -n.num}, nil
+	case t.kind == tokOp && t.text == "(":
+		p.next()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expect(")")
+	case t.kind != tokIdent:
+		return nil, p.errorf("expected value")
+	}
+
+	p.next()
+	switch t.text {
+	case "true":
+		return &number{v: 1}, nil
+	case "false":
+		return &number{v: 0}, nil
+	case "tag":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		s := p.peek()
+		if s.kind != tokString {
+			return nil, p.errorf("expected tag address in quotes")
+		}
+		p.next()
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return newTagRef(s.text, s.pos)
+	case "ton", "tof":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		d := p.peek()
+		if d.kind != tokDuration {
+			return nil, p.errorf("expected duration")
+		}
+		p.next()
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &timer{off: t.text == "tof", x: x, d: d.dur}, nil
+	}
+	address, ok := p.aliases[t.text]
+	if !ok {
+		return nil, fmt.Errorf("pos %d: unknown tag alias %s", t.pos, t.text)
+	}
+	return newTagRef(address, t.pos)
+}
+
+// newTagRef ссылка на тег, адрес вида holding-register:1:WORD/3 ссылается на бит регистра
+func newTagRef(address string, pos int) (node, error) {
+	address, bitStr, hasBit := strings.Cut(address, "/")
+	if len(address) == 0 {
+		return nil, fmt.Errorf("pos %d: empty tag address", pos)
+	}
+	ref := &tagRef{address: address}
+	if !hasBit {
+		return ref, nil
+	}
+	bit, err := strconv.Atoi(bitStr)
+	if err != nil || bit < 0 || bit > maxBit {
+		return nil, fmt.Errorf("pos %d: invalid bit number in tag address %s/%s", pos, address, bitStr)
+	}
+	return &bitOf{x: ref, bit: bit}, nil
+}
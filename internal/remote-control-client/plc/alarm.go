@@ -0,0 +1,181 @@
+package plc
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/plc4x/plc4go/pkg/api/values"
+	"github.com/c0dered273/automation-remote-controller/internal/remote-control-client/configs"
+	"github.com/c0dered273/automation-remote-controller/internal/remote-control-client/expr"
+)
+
+// alarm состояние тревоги одного уведомления
+// условие вычисляется по значениям тега, тревога срабатывает или снимается, если новое состояние условия
+// сохраняется не меньше задержки delay или clearDelay
+type alarm struct {
+	notification configs.Notifications
+	// address адрес регистра без номера бита
+	address string
+	bit     int
+	// active тревога сработала
+	active bool
+	// cond последнее вычисленное состояние условия, since - время, с которого оно отличается от active
+	cond  bool
+	since time.Time
+	// last последнее учтенное аналоговое значение для зоны нечувствительности
+	last    float64
+	hasLast bool
+	// value последнее значение тега для шаблона текста
+	value any
+}
+
+func newAlarm(n configs.Notifications) *alarm {
+	a := &alarm{notification: n}
+	address, bit, _ := strings.Cut(n.TagAddress, "/")
+	a.address = address
+	// номер бита проверяется при загрузке конфигурации
+	a.bit, _ = strconv.Atoi(bit)
+	return a
+}
+
+// observe вычисляет состояние условия по значениям тегов на момент now
+func (a *alarm) observe(tags map[string]values.PlcValue, now time.Time) error {
+	n := a.notification
+	if n.Condition == configs.ConditionExpr {
+		cond, err := n.Expr().Eval(func(address string) (float64, bool) {
+			v, ok := tags[address]
+			if !ok {
+				return 0, false
+			}
+			return numericValue(v)
+		}, now)
+		if err != nil {
+			return err
+		}
+		a.cond, a.value = cond, cond
+		return nil
+	}
+
+	v, ok := tags[a.address]
+	if !ok {
+		return fmt.Errorf("%w: %s", expr.ErrNoValue, a.address)
+	}
+	if !a.update(v) {
+		return fmt.Errorf("plc polling: tag value doesn't match condition %s: %s", n.Condition, n.TagAddress)
+	}
+	return nil
+}
+
+// update вычисляет состояние условия по новому значению тега, возвращает false, если значение не подходит условию
+func (a *alarm) update(v values.PlcValue) bool {
+	n := a.notification
+	if n.Condition == configs.ConditionBit {
+		bits := v.GetBoolArray()
+		if a.bit >= len(bits) {
+			return false
+		}
+		a.cond = bits[a.bit]
+		a.value = a.cond
+		return true
+	}
+
+	if !v.IsFloat64() {
+		return false
+	}
+	x := v.GetFloat64()
+	a.value = x
+	if a.hasLast && math.Abs(x-a.last) < n.Deadband {
+		return true
+	}
+	a.last, a.hasLast = x, true
+
+	// Сработавшая тревога удерживается, пока значение не выйдет за threshold с учетом гистерезиса,
+	// поэтому при нулевом гистерезисе значение, равное threshold, не снимает тревогу
+	threshold := *n.Threshold
+	switch {
+	case n.Condition == configs.ConditionHigh && a.active:
+		a.cond = x >= threshold-n.Hysteresis
+	case n.Condition == configs.ConditionHigh:
+		a.cond = x >= threshold
+	case n.Condition == configs.ConditionLow && a.active:
+		a.cond = x <= threshold+n.Hysteresis
+	case n.Condition == configs.ConditionLow:
+		a.cond = x <= threshold
+	}
+	return true
+}
+
+// step применяет задержки срабатывания и снятия, возвращает true, если состояние тревоги изменилось
+func (a *alarm) step(now time.Time) bool {
+	if a.cond == a.active {
+		a.since = time.Time{}
+		return false
+	}
+	if a.since.IsZero() {
+		a.since = now
+	}
+	delay := a.notification.Delay
+	if !a.cond {
+		delay = a.notification.ClearDelay
+	}
+	if now.Sub(a.since) < delay {
+		return false
+	}
+	a.active = a.cond
+	a.since = time.Time{}
+	return true
+}
+
+// pending тревога ожидает истечения задержки срабатывания или снятия
+func (a *alarm) pending() bool {
+	return !a.since.IsZero()
+}
+
+// data данные для шаблона текста уведомления о текущем состоянии тревоги
+func (a *alarm) data(plcName string, tags map[string]values.PlcValue, now time.Time) configs.NotificationData {
+	n := a.notification
+	data := configs.NotificationData{
+		Value:  a.value,
+		Active: a.active,
+		Tags:   make(map[string]any, len(tags)),
+		Device: n.Device,
+		PLC:    plcName,
+		Time:   now,
+	}
+	if n.Threshold != nil {
+		data.Limit = *n.Threshold
+	}
+	for address, v := range tags {
+		data.Tags[address] = plainValue(v)
+	}
+	return data
+}
+
+// numericValue значение тега для выражений: логические значения преобразуются в 1 и 0
+func numericValue(v values.PlcValue) (float64, bool) {
+	switch {
+	case v.GetPlcValueType() == values.BOOL && v.GetBool():
+		return 1, true
+	case v.GetPlcValueType() == values.BOOL:
+		return 0, true
+	case v.IsFloat64():
+		return v.GetFloat64(), true
+	}
+	return 0, false
+}
+
+// plainValue преобразует значение тега в тип Go для шаблонов: bool, float64 или строку
+func plainValue(v values.PlcValue) any {
+	switch {
+	case v.GetPlcValueType() == values.BOOL:
+		return v.GetBool()
+	case v.IsFloat64():
+		return v.GetFloat64()
+	case v.IsString():
+		return v.GetString()
+	}
+	return fmt.Sprint(v)
+}
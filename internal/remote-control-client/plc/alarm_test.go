@@ -0,0 +1,152 @@
+package plc
+
+import (
+	"testing"
+	"time"
+
+	spiValues "github.com/apache/plc4x/plc4go/spi/values"
+	"github.com/c0dered273/automation-remote-controller/internal/remote-control-client/configs"
+)
+
+func TestAlarmThreshold(t *testing.T) {
+	threshold := 10.0
+	tests := []struct {
+		name       string
+		condition  string
+		hysteresis float64
+		deadband   float64
+		values     []float64
+		want       []bool
+	}{
+		{
+			name:      "high raises at threshold",
+			condition: configs.ConditionHigh,
+			values:    []float64{9.9, 10, 10.1},
+			want:      []bool{false, true, true},
+		},
+		{
+			name:      "high holds at threshold without hysteresis",
+			condition: configs.ConditionHigh,
+			values:    []float64{10, 10, 10, 9.9},
+			want:      []bool{true, true, true, false},
+		},
+		{
+			name:       "high holds down to threshold minus hysteresis",
+			condition:  configs.ConditionHigh,
+			hysteresis: 2,
+			values:     []float64{11, 9, 8, 7.9, 9, 10},
+			want:       []bool{true, true, true, false, false, true},
+		},
+		{
+			name:      "low raises at threshold",
+			condition: configs.ConditionLow,
+			values:    []float64{10.1, 10, 9.9},
+			want:      []bool{false, true, true},
+		},
+		{
+			name:      "low holds at threshold without hysteresis",
+			condition: configs.ConditionLow,
+			values:    []float64{10, 10, 10.1},
+			want:      []bool{true, true, false},
+		},
+		{
+			name:       "low holds up to threshold plus hysteresis",
+			condition:  configs.ConditionLow,
+			hysteresis: 2,
+			values:     []float64{9, 11, 12, 12.1, 11, 10},
+			want:       []bool{true, true, true, false, false, true},
+		},
+		{
+			name:      "deadband ignores small changes",
+			condition: configs.ConditionHigh,
+			deadband:  0.5,
+			values:    []float64{9.8, 10.1, 10.4},
+			want:      []bool{false, false, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAlarm(configs.Notifications{
+				TagAddress: "%DB1:0:REAL",
+				Condition:  tt.condition,
+				Threshold:  &threshold,
+				Hysteresis: tt.hysteresis,
+				Deadband:   tt.deadband,
+			})
+			now := time.Now()
+			for i, x := range tt.values {
+				if !a.update(spiValues.NewPlcLREAL(x)) {
+					t.Fatalf("update(%v) rejected value", x)
+				}
+				a.step(now)
+				if a.active != tt.want[i] {
+					t.Errorf("value #%d %v: active = %v, want %v", i, x, a.active, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAlarmDelay(t *testing.T) {
+	threshold := 10.0
+	a := newAlarm(configs.Notifications{
+		TagAddress: "%DB1:0:REAL",
+		Condition:  configs.ConditionHigh,
+		Threshold:  &threshold,
+		Delay:      time.Second,
+		ClearDelay: 2 * time.Second,
+	})
+	start := time.Now()
+	tests := []struct {
+		name    string
+		value   float64
+		at      time.Duration
+		changed bool
+		active  bool
+	}{
+		{name: "condition met, delay starts", value: 11, at: 0},
+		{name: "delay not elapsed", value: 11, at: 999 * time.Millisecond},
+		{name: "delay elapsed", value: 11, at: time.Second, changed: true, active: true},
+		{name: "condition cleared, clear delay starts", value: 9, at: 2 * time.Second, active: true},
+		{name: "condition returns, clear delay resets", value: 11, at: 3 * time.Second, active: true},
+		{name: "condition cleared again", value: 9, at: 4 * time.Second, active: true},
+		{name: "clear delay elapsed", value: 9, at: 6 * time.Second, changed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a.update(spiValues.NewPlcLREAL(tt.value))
+			if changed := a.step(start.Add(tt.at)); changed != tt.changed {
+				t.Errorf("step() changed = %v, want %v", changed, tt.changed)
+			}
+			if a.active != tt.active {
+				t.Errorf("active = %v, want %v", a.active, tt.active)
+			}
+		})
+	}
+}
+
+func TestAlarmBit(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		value   uint16
+		want    bool
+		ok      bool
+	}{
+		{name: "bit set", address: "%DB1:0:WORD/0", value: 0b1, want: true, ok: true},
+		{name: "bit cleared", address: "%DB1:0:WORD/1", value: 0b1, want: false, ok: true},
+		{name: "high bit", address: "%DB1:0:WORD/15", value: 0x8000, want: true, ok: true},
+		{name: "bit out of range", address: "%DB1:0:WORD/16", value: 0xffff, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAlarm(configs.Notifications{TagAddress: tt.address, Condition: configs.ConditionBit})
+			if ok := a.update(spiValues.NewPlcWORD(tt.value)); ok != tt.ok {
+				t.Fatalf("update() = %v, want %v", ok, tt.ok)
+			}
+			if tt.ok && a.cond != tt.want {
+				t.Errorf("cond = %v, want %v", a.cond, tt.want)
+			}
+		})
+	}
+}
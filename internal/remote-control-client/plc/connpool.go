@@ -3,13 +3,19 @@ package plc
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
 	plc4go "github.com/apache/plc4x/plc4go/pkg/api"
 	"github.com/apache/plc4x/plc4go/pkg/api/model"
+	"github.com/apache/plc4x/plc4go/pkg/api/values"
 )
 
+// defaultMaxIdleConns количество простаивающих соединений по умолчанию
+const defaultMaxIdleConns = 2
+
 var (
 	ErrPoolConnFailed    = errors.New("conn_pool: connection failed")
 	ErrPoolClosed        = errors.New("conn_pool: pool is closed")
@@ -18,22 +24,52 @@ var (
 	ErrConnAlreadyClosed = errors.New("plc_conn: connection already closed")
 	ErrConnWriteOnly     = errors.New("plc_conn: can't read, write only connection")
 	ErrConnReadOnly      = errors.New("plc_conn: can't write, read only connection")
+	ErrConnNoSubscribe   = errors.New("plc_conn: subscriptions are not supported")
+	ErrBitsChanged       = errors.New("plc_conn: other bits of register changed during write")
+)
+
+// SubscriptionKind тип подписки на изменения тегов контроллера
+type SubscriptionKind string
+
+const (
+	// SubscriptionChangeOfState контроллер присылает значение тега при его изменении
+	SubscriptionChangeOfState SubscriptionKind = "change_of_state"
+	// SubscriptionCyclic контроллер присылает значение тега с заданным периодом
+	SubscriptionCyclic SubscriptionKind = "cyclic"
+)
+
+// PoolMode режим работы пула соединений
+type PoolMode string
+
+const (
+	// PoolModeReuse после выполнения запроса соединение возвращается в пул и используется для следующих запросов
+	PoolModeReuse PoolMode = "reuse"
+	// PoolModePerRequest на каждый запрос создается новое соединение, после выполнения запроса соединение закрывается
+	PoolModePerRequest PoolMode = "per_request"
 )
 
 // ConnPool пул соединений для подключения к контроллеру.
 // Большинство промышленных контроллеров имеет ограниченное количество одновременных соединения и их превышение может привести к потере данных.
-// В текущей реализации соединения не пере используются, на каждый запрос создается новое соединение, после выполнения запроса соединение закрывается.
-// Пул работает в режиме ограничения количества одновременных соединений с ПЛК.
-// При превышении лимита соединений, запросы помещаются в очередь и каждое закрытое соединение запускает новое для запроса из очереди.
+// В режиме PoolModeReuse отработавшие соединения остаются открытыми и переиспользуются, перед повторным использованием
+// соединение проверяется через Ping, соединения с ошибкой запроса или истекшим сроком жизни закрываются.
+// В режиме PoolModePerRequest соединения не переиспользуются, на каждый запрос создается новое соединение.
+// Пул ограничивает количество одновременных соединений с ПЛК.
+// При превышении лимита соединений, запросы помещаются в очередь и каждое освободившееся соединение передается запросу из очереди.
 type ConnPool struct {
 	plcURI       string
 	plcDriver    plc4go.PlcDriverManager
 	connTimeout  time.Duration
+	mode         PoolMode
 	maxOpen      int
+	maxIdle      int
+	maxLifetime  time.Duration
+	freeConns    []*driverConn
 	connRequests map[chan connRequest]struct{}
-	numOpen      int
-	mu           *sync.Mutex
-	closed       bool
+	// regLocks блокировки регистров для записи отдельных битов
+	regLocks map[string]*sync.Mutex
+	numOpen  int
+	mu       *sync.Mutex
+	closed   bool
 }
 
 // SetMaxOpenConns устанавливает максимальное количество одновременно открытых соединений
@@ -54,6 +90,52 @@ func (c *ConnPool) SetConnTimeout(t time.Duration) {
 	c.mu.Unlock()
 }
 
+// SetMode устанавливает режим работы пула, при переходе в PoolModePerRequest простаивающие соединения закрываются
+func (c *ConnPool) SetMode(mode PoolMode) {
+	c.mu.Lock()
+	c.mode = mode
+	c.mu.Unlock()
+	if mode == PoolModePerRequest {
+		c.closeIdle(0)
+	}
+}
+
+// SetMaxIdleConns устанавливает максимальное количество простаивающих соединений, лишние соединения закрываются
+func (c *ConnPool) SetMaxIdleConns(n int) {
+	if n < 0 {
+		n = 0
+	}
+	c.mu.Lock()
+	c.maxIdle = n
+	c.mu.Unlock()
+	c.closeIdle(n)
+}
+
+// SetConnMaxLifetime устанавливает максимальное время жизни соединения, 0 - без ограничения
+func (c *ConnPool) SetConnMaxLifetime(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	c.mu.Lock()
+	c.maxLifetime = d
+	c.mu.Unlock()
+}
+
+// closeIdle закрывает простаивающие соединения сверх указанного количества
+func (c *ConnPool) closeIdle(keep int) {
+	c.mu.Lock()
+	if len(c.freeConns) <= keep {
+		c.mu.Unlock()
+		return
+	}
+	closing := append([]*driverConn(nil), c.freeConns[keep:]...)
+	c.freeConns = c.freeConns[:keep]
+	c.mu.Unlock()
+	for _, dc := range closing {
+		_ = c.discardConn(dc)
+	}
+}
+
 // newConn создает новое соединение с контроллером, используя переданный драйвер протокола
 func (c *ConnPool) newConn() (plc4go.PlcConnection, error) {
 	plcConnChan := c.plcDriver.GetConnection(c.plcURI)
@@ -65,7 +147,8 @@ func (c *ConnPool) newConn() (plc4go.PlcConnection, error) {
 	return plcConnResult.GetConnection(), nil
 }
 
-// conn создает новое соединение или помещает запрос в очередь и блокирует корутину, если лимит превышен
+// conn возвращает проверенное простаивающее соединение, создает новое соединение
+// или помещает запрос в очередь и блокирует корутину, если лимит превышен
 func (c *ConnPool) conn(ctx context.Context) (*driverConn, error) {
 	select {
 	default:
@@ -73,6 +156,20 @@ func (c *ConnPool) conn(ctx context.Context) (*driverConn, error) {
 		return nil, ctx.Err()
 	}
 	c.mu.Lock()
+	for len(c.freeConns) > 0 && !c.closed {
+		n := len(c.freeConns)
+		dc := c.freeConns[n-1]
+		c.freeConns = c.freeConns[:n-1]
+		lifetime := c.maxLifetime
+		c.mu.Unlock()
+
+		if !dc.expired(lifetime) && dc.ping(ctx) == nil {
+			return dc, nil
+		}
+		// Соединение устарело или разорвано контроллером
+		_ = c.discardConn(dc)
+		c.mu.Lock()
+	}
 	if c.closed {
 		c.mu.Unlock()
 		return nil, ErrPoolClosed
@@ -83,60 +180,97 @@ func (c *ConnPool) conn(ctx context.Context) (*driverConn, error) {
 		c.connRequests[req] = struct{}{}
 		c.mu.Unlock()
 
-		ret, ok := <-req
-		if !ok {
-			return nil, ErrPoolClosed
-		}
-		if ret.conn == nil {
-			return nil, ret.err
+		select {
+		case ret, ok := <-req:
+			if !ok {
+				return nil, ErrPoolClosed
+			}
+			return ret.conn, ret.err
+		case <-ctx.Done():
+			c.mu.Lock()
+			delete(c.connRequests, req)
+			c.mu.Unlock()
+			// Соединение могло быть передано запросу до его удаления из очереди
+			select {
+			case ret, ok := <-req:
+				if ok && ret.conn != nil {
+					_ = c.putConn(ret.conn, nil)
+				}
+			default:
+			}
+			return nil, ctx.Err()
 		}
-
-		return ret.conn, ret.err
 	}
 
+	c.numOpen++
 	c.mu.Unlock()
 	plcConn, err := c.newConn()
 	if err != nil {
+		c.mu.Lock()
+		c.numOpen--
+		c.mu.Unlock()
 		return nil, err
 	}
-	c.mu.Lock()
-
-	c.numOpen++
-	drvConn := newDriveConn(c, plcConn)
-	c.mu.Unlock()
-	return drvConn, nil
+	return newDriveConn(c, plcConn), nil
 }
 
-// releaseConn закрывает отработавшее соединение и если есть запросы в очереди, создает новое соединение для этого запроса
-func (c *ConnPool) releaseConn(drvConn *driverConn) error {
-	err := drvConn.closeConn()
-	if err != nil {
-		return err
-	}
+// putConn возвращает соединение в пул после выполнения запроса.
+// Соединение передается запросу из очереди или остается простаивать в пуле, но закрывается,
+// если пул работает в режиме PoolModePerRequest, запрос завершился ошибкой, истек срок жизни соединения
+// или превышено количество простаивающих соединений
+func (c *ConnPool) putConn(dc *driverConn, reqErr error) error {
 	c.mu.Lock()
-	c.numOpen--
-	if c.maxOpen > 0 && c.numOpen > c.maxOpen {
+	reuse := !c.closed &&
+		c.mode == PoolModeReuse &&
+		reqErr == nil &&
+		!dc.expired(c.maxLifetime) &&
+		(c.maxOpen == 0 || c.numOpen <= c.maxOpen)
+	if reuse && len(c.connRequests) > 0 {
+		req := c.takeRequest()
 		c.mu.Unlock()
+		req <- connRequest{conn: dc}
 		return nil
 	}
-	if l := len(c.connRequests); l > 0 {
-		var req chan connRequest
-		for req = range c.connRequests {
-			break
-		}
-		delete(c.connRequests, req)
-		c.numOpen++
+	if reuse && len(c.freeConns) < c.maxIdle {
+		c.freeConns = append(c.freeConns, dc)
+		c.mu.Unlock()
+		return nil
+	}
+	c.mu.Unlock()
+	return c.discardConn(dc)
+}
 
+// discardConn закрывает соединение и, если есть запросы в очереди, создает новое соединение для запроса из очереди.
+// Если создать соединение не удалось, ошибка передается запросу и соединение создается для следующего запроса
+func (c *ConnPool) discardConn(dc *driverConn) error {
+	err := dc.closeConn()
+	c.mu.Lock()
+	c.numOpen--
+	for !c.closed && len(c.connRequests) > 0 && (c.maxOpen == 0 || c.numOpen < c.maxOpen) {
+		req := c.takeRequest()
+		c.numOpen++
 		c.mu.Unlock()
-		plcConn, err := c.newConn()
-		c.mu.Lock()
 
-		req <- connRequest{
-			conn: newDriveConn(c, plcConn),
-			err:  err,
+		plcConn, connErr := c.newConn()
+		if connErr == nil {
+			req <- connRequest{conn: newDriveConn(c, plcConn)}
+			return err
 		}
+
+		req <- connRequest{err: connErr}
+		c.mu.Lock()
+		c.numOpen--
 	}
 	c.mu.Unlock()
+	return err
+}
+
+// takeRequest извлекает запрос из очереди, вызывается под блокировкой пула
+func (c *ConnPool) takeRequest() chan connRequest {
+	for req := range c.connRequests {
+		delete(c.connRequests, req)
+		return req
+	}
 	return nil
 }
 
@@ -151,9 +285,16 @@ func (c *ConnPool) Close() error {
 	for req := range c.connRequests {
 		close(req)
 	}
-
+	c.connRequests = make(map[chan connRequest]struct{})
+	idle := c.freeConns
+	c.freeConns = nil
 	c.mu.Unlock()
-	return nil
+
+	var errs []error
+	for _, dc := range idle {
+		errs = append(errs, c.discardConn(dc))
+	}
+	return errors.Join(errs...)
 }
 
 // ReadTagAddress вычитывает указанный в tagAddress тэг из контроллера
@@ -169,16 +310,33 @@ func (c *ConnPool) ReadTagAddress(ctx context.Context, tagName string, tagAddres
 		return nil, err
 	}
 
-	response, err := conn.readTagAddress(ctx, tagName, tagAddress)
+	response, err := conn.readTags(ctx, map[string]string{tagName: tagAddress})
+	err = errors.Join(err, c.putConn(conn, err))
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ReadTagAddresses вычитывает несколько тегов из контроллера одним запросом, имя каждого тега в ответе совпадает с его адресом
+func (c *ConnPool) ReadTagAddresses(ctx context.Context, tagAddresses []string) (model.PlcReadResponse, error) {
+	select {
+	default:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	tags := make(map[string]string, len(tagAddresses))
+	for _, address := range tagAddresses {
+		tags[address] = address
+	}
+	conn, err := c.conn(ctx)
 	if err != nil {
-		rlErr := c.releaseConn(conn)
-		if rlErr != nil {
-			return nil, errors.Join(err, rlErr)
-		}
 		return nil, err
 	}
 
-	err = c.releaseConn(conn)
+	response, err := conn.readTags(ctx, tags)
+	err = errors.Join(err, c.putConn(conn, err))
 	if err != nil {
 		return nil, err
 	}
@@ -197,20 +355,143 @@ func (c *ConnPool) WriteTagAddress(ctx context.Context, tagName string, tagAddre
 	if err != nil {
 		return nil, err
 	}
+
 	response, err := conn.writeTagAddress(ctx, tagName, tagAddress, value)
+	err = errors.Join(err, c.putConn(conn, err))
 	if err != nil {
-		rlErr := c.releaseConn(conn)
-		if rlErr != nil {
-			return nil, errors.Join(err, rlErr)
-		}
 		return nil, err
 	}
+	return response, nil
+}
+
+// WriteTagBit записывает бит регистра чтением-изменением-записью под блокировкой регистра,
+// чтобы одновременные записи разных битов одного регистра не затирали друг друга.
+// После записи регистр читается повторно и проверяется, что остальные биты не изменились.
+// Биты адресуются в беззнаковых регистрах, например WORD или DWORD
+func (c *ConnPool) WriteTagBit(ctx context.Context, tagAddress string, bit int, value bool) error {
+	return c.UpdateTagBit(ctx, tagAddress, bit, func(bool) bool {
+		return value
+	})
+}
+
+// UpdateTagBit записывает бит регистра, новое значение бита вычисляет update по текущему,
+// запись выполняется так же, как в WriteTagBit
+func (c *ConnPool) UpdateTagBit(ctx context.Context, tagAddress string, bit int, update func(current bool) bool) error {
+	lock := c.registerLock(tagAddress)
+	lock.Lock()
+	defer lock.Unlock()
+
+	before, width, err := c.readRegister(ctx, tagAddress)
+	if err != nil {
+		return err
+	}
+	if bit < 0 || bit >= width {
+		return fmt.Errorf("plc_conn: bit %d is out of range of %d-bit register %s", bit, width, tagAddress)
+	}
+	mask := uint64(1) << bit
+	after := before &^ mask
+	if update(before&mask != 0) {
+		after |= mask
+	}
+	if after != before {
+		if _, err := c.WriteTagAddress(ctx, "write", tagAddress, strconv.FormatUint(after, 10)); err != nil {
+			return err
+		}
+	}
 
-	err = c.releaseConn(conn)
+	check, _, err := c.readRegister(ctx, tagAddress)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("plc_conn: failed to verify register %s: %w", tagAddress, err)
 	}
-	return response, nil
+	if check&^mask != before&^mask {
+		return fmt.Errorf("%w: %s: before %0*b, after %0*b", ErrBitsChanged, tagAddress, width, before, width, check)
+	}
+	if check != after {
+		return fmt.Errorf("plc_conn: bit %d of register %s wasn't written", bit, tagAddress)
+	}
+	return nil
+}
+
+// UpdateTagAddress записывает в тег значение, вычисленное update по текущему значению тега,
+// чтение и запись выполняются под блокировкой регистра
+func (c *ConnPool) UpdateTagAddress(ctx context.Context, tagAddress string, update func(current values.PlcValue) (any, error)) error {
+	lock := c.registerLock(tagAddress)
+	lock.Lock()
+	defer lock.Unlock()
+
+	resp, err := c.ReadTagAddress(ctx, "read", tagAddress)
+	if err != nil {
+		return err
+	}
+	if code := resp.GetResponseCode("read"); code != model.PlcResponseCode_OK {
+		return fmt.Errorf("plc_conn: failed to read tag %s: %s", tagAddress, code.GetName())
+	}
+	value, err := update(resp.GetValue("read"))
+	if err != nil {
+		return err
+	}
+	_, err = c.WriteTagAddress(ctx, "write", tagAddress, value)
+	return err
+}
+
+// registerLock возвращает блокировку регистра
+func (c *ConnPool) registerLock(tagAddress string) *sync.Mutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lock, ok := c.regLocks[tagAddress]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.regLocks[tagAddress] = lock
+	}
+	return lock
+}
+
+// readRegister вычитывает регистр как беззнаковое целое, возвращает значение и разрядность регистра
+func (c *ConnPool) readRegister(ctx context.Context, tagAddress string) (uint64, int, error) {
+	resp, err := c.ReadTagAddress(ctx, "read", tagAddress)
+	if err != nil {
+		return 0, 0, err
+	}
+	if code := resp.GetResponseCode("read"); code != model.PlcResponseCode_OK {
+		return 0, 0, fmt.Errorf("plc_conn: failed to read register %s: %s", tagAddress, code.GetName())
+	}
+	v := resp.GetValue("read")
+	if !v.IsUint64() || !v.IsBool() {
+		return 0, 0, fmt.Errorf("plc_conn: register %s is not an unsigned integer", tagAddress)
+	}
+	width := len(v.GetBoolArray())
+	raw := v.GetUint64()
+	if width < 64 {
+		raw &= uint64(1)<<width - 1
+	}
+	return raw, width, nil
+}
+
+// Subscribe подписывается на изменения тегов, имя каждого тега в событии совпадает с его адресом.
+// Соединение подписки не возвращается в пул: вызов блокирует до отмены контекста, после чего соединение закрывается,
+// так как отмена подписки драйверами plc4go не поддерживается
+func (c *ConnPool) Subscribe(
+	ctx context.Context,
+	kind SubscriptionKind,
+	interval time.Duration,
+	tagAddresses []string,
+	consumer model.PlcSubscriptionEventConsumer,
+) error {
+	conn, err := c.conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	registrations, err := conn.subscribe(ctx, kind, interval, tagAddresses, consumer)
+	if err != nil {
+		return errors.Join(err, c.discardConn(conn))
+	}
+	<-ctx.Done()
+	for _, r := range registrations {
+		r.Unregister()
+	}
+	_ = c.discardConn(conn)
+	return ctx.Err()
 }
 
 // Ping проверяет наличие соединения с контроллером
@@ -225,20 +506,9 @@ func (c *ConnPool) Ping(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	err = conn.ping(ctx)
-	if err != nil {
-		rlErr := c.releaseConn(conn)
-		if rlErr != nil {
-			return errors.Join(err, rlErr)
-		}
-		return err
-	}
 
-	err = c.releaseConn(conn)
-	if err != nil {
-		return err
-	}
-	return nil
+	err = conn.ping(ctx)
+	return errors.Join(err, c.putConn(conn, err))
 }
 
 type responseWithErr interface {
@@ -246,13 +516,20 @@ type responseWithErr interface {
 }
 
 type driverConn struct {
-	connPool *ConnPool
-	conn     plc4go.PlcConnection
-	closed   bool
-	mu       sync.Mutex
+	connPool  *ConnPool
+	conn      plc4go.PlcConnection
+	createdAt time.Time
+	closed    bool
+	mu        sync.Mutex
 }
 
-func (dc *driverConn) readTagAddress(ctx context.Context, tagName string, tagAddress string) (model.PlcReadResponse, error) {
+// expired проверяет, истек ли срок жизни соединения
+func (dc *driverConn) expired(lifetime time.Duration) bool {
+	return lifetime > 0 && time.Since(dc.createdAt) > lifetime
+}
+
+// readTags вычитывает теги одним запросом, tags - адреса тегов по именам
+func (dc *driverConn) readTags(ctx context.Context, tags map[string]string) (model.PlcReadResponse, error) {
 	select {
 	default:
 	case <-ctx.Done():
@@ -268,9 +545,11 @@ func (dc *driverConn) readTagAddress(ctx context.Context, tagName string, tagAdd
 		dc.mu.Unlock()
 		return nil, ErrConnWriteOnly
 	}
-	req, err := dc.conn.ReadRequestBuilder().
-		AddTagAddress(tagName, tagAddress).
-		Build()
+	builder := dc.conn.ReadRequestBuilder()
+	for tagName, tagAddress := range tags {
+		builder.AddTagAddress(tagName, tagAddress)
+	}
+	req, err := builder.Build()
 	if err != nil {
 		dc.mu.Unlock()
 		return nil, err
@@ -320,6 +599,69 @@ func (dc *driverConn) writeTagAddress(ctx context.Context, tagName string, tagAd
 	return reqResult.GetResponse(), nil
 }
 
+// subscribe оформляет подписку на теги и регистрирует обработчик событий
+func (dc *driverConn) subscribe(
+	ctx context.Context,
+	kind SubscriptionKind,
+	interval time.Duration,
+	tagAddresses []string,
+	consumer model.PlcSubscriptionEventConsumer,
+) ([]model.PlcConsumerRegistration, error) {
+	select {
+	default:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	if dc.closed {
+		return nil, ErrConnClosed
+	}
+
+	if !dc.conn.GetMetadata().CanSubscribe() {
+		return nil, ErrConnNoSubscribe
+	}
+	builder := dc.conn.SubscriptionRequestBuilder()
+	for _, address := range tagAddresses {
+		if kind == SubscriptionCyclic {
+			builder.AddCyclicTagAddress(address, address, interval)
+		} else {
+			builder.AddChangeOfStateTagAddress(address, address)
+		}
+	}
+	req, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	respChan := req.ExecuteWithContext(ctx)
+	reqResult, err := resultWithTimeout(respChan, dc.connPool.connTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := reqResult.GetResponse()
+	registrations := make([]model.PlcConsumerRegistration, 0, len(tagAddresses))
+	for _, address := range tagAddresses {
+		if code := resp.GetResponseCode(address); code != model.PlcResponseCode_OK {
+			err = errors.Join(err, fmt.Errorf("plc_conn: failed to subscribe tag %s: %s", address, code.GetName()))
+			continue
+		}
+		handle, handleErr := resp.GetSubscriptionHandle(address)
+		if handleErr != nil {
+			err = errors.Join(err, handleErr)
+			continue
+		}
+		registrations = append(registrations, handle.Register(consumer))
+	}
+	if err != nil {
+		for _, r := range registrations {
+			r.Unregister()
+		}
+		return nil, err
+	}
+	return registrations, nil
+}
+
 func (dc *driverConn) ping(ctx context.Context) error {
 	select {
 	default:
@@ -390,8 +732,9 @@ func resultWithTimeout[T responseWithErr](respChan <-chan T, timeout time.Durati
 
 func newDriveConn(connPool *ConnPool, conn plc4go.PlcConnection) *driverConn {
 	return &driverConn{
-		connPool: connPool,
-		conn:     conn,
+		connPool:  connPool,
+		conn:      conn,
+		createdAt: time.Now(),
 	}
 }
 
@@ -400,15 +743,23 @@ type connRequest struct {
 	err  error
 }
 
-// NewConnPool возвращает настроенный пул соединений
-func NewConnPool(driver plc4go.PlcDriverManager, plcURI string) (*ConnPool, error) {
-	connPool := &ConnPool{
+// newConnPool создает пул соединений без проверки доступности контроллера
+func newConnPool(driver plc4go.PlcDriverManager, plcURI string) *ConnPool {
+	return &ConnPool{
 		plcURI:       plcURI,
 		plcDriver:    driver,
 		connTimeout:  5 * time.Second,
+		mode:         PoolModeReuse,
+		maxIdle:      defaultMaxIdleConns,
 		connRequests: make(map[chan connRequest]struct{}),
+		regLocks:     make(map[string]*sync.Mutex),
 		mu:           &sync.Mutex{},
 	}
+}
+
+// NewConnPool возвращает настроенный пул соединений, если контроллер недоступен, возвращается ошибка
+func NewConnPool(driver plc4go.PlcDriverManager, plcURI string) (*ConnPool, error) {
+	connPool := newConnPool(driver, plcURI)
 
 	err := connPool.Ping(context.Background())
 	if err != nil {
@@ -0,0 +1,302 @@
+package plc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	plc4go "github.com/apache/plc4x/plc4go/pkg/api"
+	"github.com/apache/plc4x/plc4go/pkg/api/model"
+	"github.com/apache/plc4x/plc4go/pkg/api/values"
+	spiValues "github.com/apache/plc4x/plc4go/spi/values"
+)
+
+// fakePLC контроллер с WORD регистрами в памяти, реализует только используемые пулом методы plc4go
+type fakePLC struct {
+	plc4go.PlcDriverManager
+	mu        sync.Mutex
+	registers map[string]uint16
+	writes    int
+	reads     int
+	// afterWrite вызывается после записи под блокировкой контроллера, имитирует изменения другим клиентом
+	afterWrite func(registers map[string]uint16)
+	// readOnly запись игнорируется контроллером
+	readOnly bool
+	// readDelay задержка ответа на чтение, позволяет параллельным запросам пересечься
+	readDelay time.Duration
+}
+
+func (p *fakePLC) register(address string) uint16 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.registers[address]
+}
+
+func (p *fakePLC) GetConnection(string) <-chan plc4go.PlcConnectionConnectResult {
+	ch := make(chan plc4go.PlcConnectionConnectResult, 1)
+	ch <- fakeConnectResult{conn: &fakeConn{plc: p}}
+	return ch
+}
+
+type fakeConnectResult struct {
+	plc4go.PlcConnectionConnectResult
+	conn plc4go.PlcConnection
+}
+
+func (r fakeConnectResult) GetConnection() plc4go.PlcConnection { return r.conn }
+func (r fakeConnectResult) GetErr() error                       { return nil }
+
+type fakeConn struct {
+	plc4go.PlcConnection
+	plc *fakePLC
+}
+
+type fakeMetadata struct {
+	model.PlcConnectionMetadata
+}
+
+func (fakeMetadata) CanRead() bool  { return true }
+func (fakeMetadata) CanWrite() bool { return true }
+
+type fakeResult struct{}
+
+func (fakeResult) GetErr() error { return nil }
+
+func (c *fakeConn) GetMetadata() model.PlcConnectionMetadata { return fakeMetadata{} }
+
+func (c *fakeConn) Ping() <-chan plc4go.PlcConnectionPingResult {
+	ch := make(chan plc4go.PlcConnectionPingResult, 1)
+	ch <- fakeResult{}
+	return ch
+}
+
+func (c *fakeConn) Close() <-chan plc4go.PlcConnectionCloseResult {
+	ch := make(chan plc4go.PlcConnectionCloseResult, 1)
+	ch <- fakeCloseResult{}
+	return ch
+}
+
+type fakeCloseResult struct {
+	plc4go.PlcConnectionCloseResult
+}
+
+func (fakeCloseResult) GetErr() error { return nil }
+
+func (c *fakeConn) ReadRequestBuilder() model.PlcReadRequestBuilder {
+	return &fakeReadBuilder{plc: c.plc, tags: map[string]string{}}
+}
+
+func (c *fakeConn) WriteRequestBuilder() model.PlcWriteRequestBuilder {
+	return &fakeWriteBuilder{plc: c.plc, tags: map[string]string{}, values: map[string]any{}}
+}
+
+type fakeReadBuilder struct {
+	model.PlcReadRequestBuilder
+	plc  *fakePLC
+	tags map[string]string
+}
+
+func (b *fakeReadBuilder) AddTagAddress(name string, address string) model.PlcReadRequestBuilder {
+	b.tags[name] = address
+	return b
+}
+
+func (b *fakeReadBuilder) Build() (model.PlcReadRequest, error) {
+	return &fakeReadRequest{plc: b.plc, tags: b.tags}, nil
+}
+
+type fakeReadRequest struct {
+	model.PlcReadRequest
+	plc  *fakePLC
+	tags map[string]string
+}
+
+func (r *fakeReadRequest) ExecuteWithContext(context.Context) <-chan model.PlcReadRequestResult {
+	r.plc.mu.Lock()
+	r.plc.reads++
+	resp := &fakeReadResponse{values: make(map[string]values.PlcValue, len(r.tags))}
+	for name, address := range r.tags {
+		resp.values[name] = spiValues.NewPlcWORD(r.plc.registers[address])
+	}
+	r.plc.mu.Unlock()
+	time.Sleep(r.plc.readDelay)
+	ch := make(chan model.PlcReadRequestResult, 1)
+	ch <- fakeReadResult{resp: resp}
+	return ch
+}
+
+type fakeReadResult struct {
+	model.PlcReadRequestResult
+	resp model.PlcReadResponse
+}
+
+func (r fakeReadResult) GetResponse() model.PlcReadResponse { return r.resp }
+func (r fakeReadResult) GetErr() error                      { return nil }
+
+type fakeReadResponse struct {
+	model.PlcReadResponse
+	values map[string]values.PlcValue
+}
+
+func (r *fakeReadResponse) GetResponseCode(string) model.PlcResponseCode {
+	return model.PlcResponseCode_OK
+}
+
+func (r *fakeReadResponse) GetValue(name string) values.PlcValue {
+	return r.values[name]
+}
+
+type fakeWriteBuilder struct {
+	model.PlcWriteRequestBuilder
+	plc    *fakePLC
+	tags   map[string]string
+	values map[string]any
+}
+
+func (b *fakeWriteBuilder) AddTagAddress(name string, address string, value any) model.PlcWriteRequestBuilder {
+	b.tags[name] = address
+	b.values[name] = value
+	return b
+}
+
+func (b *fakeWriteBuilder) Build() (model.PlcWriteRequest, error) {
+	return &fakeWriteRequest{builder: b}, nil
+}
+
+type fakeWriteRequest struct {
+	model.PlcWriteRequest
+	builder *fakeWriteBuilder
+}
+
+func (r *fakeWriteRequest) ExecuteWithContext(context.Context) <-chan model.PlcWriteRequestResult {
+	p := r.builder.plc
+	ch := make(chan model.PlcWriteRequestResult, 1)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for name, address := range r.builder.tags {
+		v, err := strconv.ParseUint(r.builder.values[name].(string), 10, 16)
+		if err != nil {
+			ch <- fakeWriteResult{err: err}
+			return ch
+		}
+		p.writes++
+		if !p.readOnly {
+			p.registers[address] = uint16(v)
+		}
+	}
+	if p.afterWrite != nil {
+		p.afterWrite(p.registers)
+	}
+	ch <- fakeWriteResult{}
+	return ch
+}
+
+type fakeWriteResult struct {
+	model.PlcWriteRequestResult
+	err error
+}
+
+func (r fakeWriteResult) GetResponse() model.PlcWriteResponse { return nil }
+func (r fakeWriteResult) GetErr() error                       { return r.err }
+
+const testRegister = "holding-register:1:WORD"
+
+func TestUpdateTagBit(t *testing.T) {
+	tests := []struct {
+		name       string
+		before     uint16
+		bit        int
+		update     func(bool) bool
+		afterWrite func(registers map[string]uint16)
+		readOnly   bool
+		want       uint16
+		wantWrites int
+		wantErr    error
+		anyErr     bool
+	}{
+		{name: "set bit", before: 0b1000, bit: 0, update: func(bool) bool { return true }, want: 0b1001, wantWrites: 1},
+		{name: "clear bit", before: 0b1001, bit: 3, update: func(bool) bool { return false }, want: 0b0001, wantWrites: 1},
+		{name: "high bit", before: 0x00ff, bit: 15, update: func(bool) bool { return true }, want: 0x80ff, wantWrites: 1},
+		{name: "toggle uses current value", before: 0b0100, bit: 2, update: func(v bool) bool { return !v }, want: 0, wantWrites: 1},
+		{name: "unchanged bit is not written", before: 0b0100, bit: 2, update: func(bool) bool { return true }, want: 0b0100},
+		{name: "bit out of range", before: 0xffff, bit: 16, update: func(bool) bool { return false }, want: 0xffff, anyErr: true},
+		{name: "negative bit", before: 0, bit: -1, update: func(bool) bool { return true }, want: 0, anyErr: true},
+		{
+			name:   "other bits changed during write",
+			before: 0b0001,
+			bit:    1,
+			update: func(bool) bool { return true },
+			afterWrite: func(registers map[string]uint16) {
+				registers[testRegister] |= 0b1000
+			},
+			want:       0b1011,
+			wantWrites: 1,
+			wantErr:    ErrBitsChanged,
+		},
+		{
+			name:       "write ignored by controller",
+			before:     0,
+			bit:        4,
+			update:     func(bool) bool { return true },
+			readOnly:   true,
+			want:       0,
+			wantWrites: 1,
+			anyErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &fakePLC{
+				registers:  map[string]uint16{testRegister: tt.before},
+				afterWrite: tt.afterWrite,
+				readOnly:   tt.readOnly,
+			}
+			pool := newConnPool(p, "fake://plc")
+			err := pool.UpdateTagBit(context.Background(), testRegister, tt.bit, tt.update)
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("UpdateTagBit() error = %v, want %v", err, tt.wantErr)
+			case tt.anyErr && err == nil:
+				t.Error("UpdateTagBit() expected error")
+			case tt.wantErr == nil && !tt.anyErr && err != nil:
+				t.Errorf("UpdateTagBit() error = %v", err)
+			}
+			if got := p.register(testRegister); got != tt.want {
+				t.Errorf("register = %016b, want %016b", got, tt.want)
+			}
+			if p.writes != tt.wantWrites {
+				t.Errorf("writes = %d, want %d", p.writes, tt.wantWrites)
+			}
+		})
+	}
+}
+
+func TestWriteTagBitConcurrent(t *testing.T) {
+	p := &fakePLC{registers: map[string]uint16{testRegister: 0}, readDelay: time.Millisecond}
+	pool := newConnPool(p, "fake://plc")
+	pool.SetMaxOpenConns(4)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for bit := 0; bit < 16; bit++ {
+		wg.Add(1)
+		go func(bit int) {
+			defer wg.Done()
+			errs <- pool.WriteTagBit(context.Background(), testRegister, bit, true)
+		}(bit)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("WriteTagBit() error = %v", err)
+		}
+	}
+	// запись каждого бита не затирает биты, записанные параллельно
+	if got := p.register(testRegister); got != 0xffff {
+		t.Errorf("register = %016b, want all bits set", got)
+	}
+}
@@ -0,0 +1,176 @@
+package plc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	plc4go "github.com/apache/plc4x/plc4go/pkg/api"
+	"github.com/apache/plc4x/plc4go/pkg/api/values"
+	"github.com/c0dered273/automation-remote-controller/internal/remote-control-client/configs"
+	"github.com/rs/zerolog"
+)
+
+var ErrPLCUnavailable = errors.New("plc: controller is unavailable")
+
+// PLC именованный контроллер со своим пулом соединений
+// доступность контроллера проверяется периодически, запросы к недоступному контроллеру сразу завершаются ошибкой,
+// чтобы не ждать таймаута соединения и не задерживать опрос остальных контроллеров
+type PLC struct {
+	Name           string
+	conn           *ConnPool
+	healthInterval time.Duration
+	healthy        atomic.Bool
+	// changed закрывается и пересоздается при каждом изменении доступности контроллера
+	changed atomic.Pointer[chan struct{}]
+	// monitor способ получения значений тегов, subscription - тип подписки для MonitorSubscription
+	monitor      MonitorKind
+	subscription SubscriptionKind
+	// scanInterval и maxTags настройки циклического опроса тегов
+	scanInterval time.Duration
+	maxTags      int
+	// values последние значения тегов по адресам, expires - срок их актуальности
+	values  map[string]values.PlcValue
+	expires time.Time
+	mu      sync.RWMutex
+	logger  zerolog.Logger
+}
+
+// Healthy возвращает результат последней проверки доступности контроллера
+func (p *PLC) Healthy() bool {
+	return p.healthy.Load()
+}
+
+// Conn возвращает пул соединений с контроллером, если контроллер доступен
+func (p *PLC) Conn() (*ConnPool, error) {
+	if !p.Healthy() {
+		return nil, fmt.Errorf("%w: %s", ErrPLCUnavailable, p.Name)
+	}
+	return p.conn, nil
+}
+
+// WaitHealthy блокирует до восстановления доступности контроллера или отмены контекста
+func (p *PLC) WaitHealthy(ctx context.Context) error {
+	return p.waitHealth(ctx, true)
+}
+
+// waitUnhealthy блокирует до потери связи с контроллером или отмены контекста
+func (p *PLC) waitUnhealthy(ctx context.Context) error {
+	return p.waitHealth(ctx, false)
+}
+
+func (p *PLC) waitHealth(ctx context.Context, healthy bool) error {
+	for {
+		changed := *p.changed.Load()
+		if p.Healthy() == healthy {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (p *PLC) setHealthy(healthy bool, err error) {
+	if p.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		p.logger.Info().Msgf("plc: controller %s is available", p.Name)
+	} else {
+		p.logger.Error().Err(err).Msgf("plc: controller %s is unavailable", p.Name)
+	}
+	next := make(chan struct{})
+	close(*p.changed.Swap(&next))
+}
+
+// setValues сохраняет последние полученные значения тегов, значения актуальны в течение ttl, 0 - без ограничения
+func (p *PLC) setValues(v map[string]values.PlcValue, ttl time.Duration) {
+	p.mu.Lock()
+	p.values = v
+	p.expires = time.Time{}
+	if ttl > 0 {
+		p.expires = time.Now().Add(ttl)
+	}
+	p.mu.Unlock()
+}
+
+// lastValue возвращает последнее актуальное значение тега, полученное монитором
+func (p *PLC) lastValue(address string) (values.PlcValue, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if !p.expires.IsZero() && time.Now().After(p.expires) {
+		return nil, false
+	}
+	v, ok := p.values[address]
+	return v, ok
+}
+
+// healthCheck периодически проверяет доступность контроллера
+func (p *PLC) healthCheck(ctx context.Context) {
+	ticker := time.NewTicker(p.healthInterval)
+	defer ticker.Stop()
+	for first := true; ; first = false {
+		err := p.conn.Ping(ctx)
+		if first && err != nil {
+			p.logger.Error().Err(err).Msgf("plc: controller %s is unavailable", p.Name)
+		}
+		p.setHealthy(err == nil, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PLCs контроллеры клиентского приложения по именам
+type PLCs map[string]*PLC
+
+// Close закрывает пулы соединений всех контроллеров
+func (p PLCs) Close() error {
+	var errs []error
+	for _, c := range p {
+		errs = append(errs, c.conn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// NewPlcConn возвращает настроенный пул соединений с контроллером
+func NewPlcConn(driverManager plc4go.PlcDriverManager, config configs.PLC) *ConnPool {
+	conn := newConnPool(driverManager, config.URI)
+	conn.SetMaxOpenConns(config.MaxOpenConns)
+	conn.SetConnTimeout(config.ConnTimeout)
+	conn.SetMode(PoolMode(config.PoolMode))
+	conn.SetMaxIdleConns(config.MaxIdleConns)
+	conn.SetConnMaxLifetime(config.ConnMaxLifetime)
+	return conn
+}
+
+// NewPLCs создает пулы соединений для всех контроллеров из конфигурации и запускает проверку их доступности
+// недоступный при старте контроллер не прерывает запуск, опрос начнется после восстановления связи
+func NewPLCs(ctx context.Context, driverManager plc4go.PlcDriverManager, config *configs.RClientConfig, logger zerolog.Logger) PLCs {
+	plcs := make(PLCs, len(config.PLCs))
+	for _, cfg := range config.PLCs {
+		p := &PLC{
+			Name:           cfg.Name,
+			conn:           NewPlcConn(driverManager, cfg),
+			healthInterval: cfg.HealthInterval,
+			scanInterval:   cfg.ScanInterval,
+			maxTags:        cfg.MaxTagsPerRequest,
+			monitor:        MonitorKind(cfg.Monitor),
+			subscription:   SubscriptionKind(cfg.Subscription),
+			logger:         logger,
+		}
+		changed := make(chan struct{})
+		p.changed.Store(&changed)
+		plcs[cfg.Name] = p
+		go p.healthCheck(ctx)
+	}
+	return plcs
+}
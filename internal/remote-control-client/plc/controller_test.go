@@ -0,0 +1,124 @@
+package plc
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/remote-control-client/configs"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+)
+
+// parseConfig разбирает настройки клиента из yaml так же, как конфигурационный файл
+func parseConfig(t *testing.T, yaml string) *configs.RClientConfig {
+	t.Helper()
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader(yaml)); err != nil {
+		t.Fatalf("ReadConfig() error = %v", err)
+	}
+	cfg := &configs.RClientConfig{}
+	if err := v.Unmarshal(cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	return cfg
+}
+
+func TestNewPLCsScan(t *testing.T) {
+	cfg := parseConfig(t, `
+plcs:
+  - name: boiler
+    uri: fake://boiler
+    health_interval: 1h
+    scan_interval: 10ms
+    max_tags_per_request: 2
+`)
+	addresses := []string{
+		"holding-register:1:WORD",
+		"holding-register:2:WORD",
+		"holding-register:3:WORD",
+	}
+	fake := &fakePLC{registers: map[string]uint16{}}
+	for i, address := range addresses {
+		fake.registers[address] = uint16(i + 1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	plcs := NewPLCs(ctx, fake, cfg, zerolog.Nop())
+	defer plcs.Close()
+	p := plcs["boiler"]
+	if p.scanInterval != 10*time.Millisecond || p.maxTags != 2 {
+		t.Fatalf("NewPLCs() scanInterval = %v, maxTags = %d", p.scanInterval, p.maxTags)
+	}
+
+	var event TagEvent
+	NewTagMonitor(p, zerolog.Nop()).Monitor(ctx, addresses, func(e TagEvent) {
+		event = e
+		cancel()
+	})
+	if ctx.Err() != context.Canceled {
+		t.Fatalf("Monitor() stopped without scan: %v", ctx.Err())
+	}
+	changed := append([]string(nil), event.Changed...)
+	sort.Strings(changed)
+	if strings.Join(changed, ",") != strings.Join(addresses, ",") {
+		t.Errorf("scan changed = %v, want %v", changed, addresses)
+	}
+	// три тега при ограничении в два тега на запрос читаются двумя запросами
+	fake.mu.Lock()
+	reads := fake.reads
+	fake.mu.Unlock()
+	if reads != 2 {
+		t.Errorf("scan reads = %d, want 2", reads)
+	}
+	if _, ok := p.lastValue(addresses[2]); !ok {
+		t.Error("scan values are not stored")
+	}
+}
+
+func TestNewPLCsMonitor(t *testing.T) {
+	cfg := parseConfig(t, `
+plcs:
+  - name: boiler
+    uri: fake://boiler
+    health_interval: 1h
+    scan_interval: 10ms
+    monitor: subscription
+    subscription: cyclic
+  - name: pump
+    uri: fake://pump
+    health_interval: 1h
+    scan_interval: 10ms
+    monitor: polling
+`)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	plcs := NewPLCs(ctx, &fakePLC{registers: map[string]uint16{}}, cfg, zerolog.Nop())
+	defer plcs.Close()
+
+	tests := []struct {
+		name             string
+		plc              string
+		wantSubscription bool
+		wantKind         SubscriptionKind
+	}{
+		{name: "subscription", plc: "boiler", wantSubscription: true, wantKind: SubscriptionCyclic},
+		{name: "polling", plc: "pump"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := plcs[tt.plc]
+			_, ok := NewTagMonitor(p, zerolog.Nop()).(*subscriptionMonitor)
+			if ok != tt.wantSubscription {
+				t.Errorf("NewTagMonitor() subscription = %v, want %v", ok, tt.wantSubscription)
+			}
+			if tt.wantSubscription && p.subscription != tt.wantKind {
+				t.Errorf("NewPLCs() subscription = %s, want %s", p.subscription, tt.wantKind)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package plc
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/apache/plc4x/plc4go/pkg/api/values"
+	"github.com/rs/zerolog"
+)
+
+// MonitorKind способ получения значений тегов контроллера
+type MonitorKind string
+
+const (
+	// MonitorPolling циклический опрос тегов запросами чтения
+	MonitorPolling MonitorKind = "polling"
+	// MonitorSubscription подписка на изменения тегов, если протокол контроллера ее поддерживает
+	MonitorSubscription MonitorKind = "subscription"
+)
+
+// TagEvent событие изменения тегов контроллера
+// Values последние известные значения всех отслеживаемых тегов по адресам, Changed - адреса изменившихся тегов
+type TagEvent struct {
+	Values  map[string]values.PlcValue
+	Changed []string
+}
+
+// TagMonitor отслеживает значения тегов контроллера и передает события изменений обработчику.
+// Monitor блокирует до отмены контекста, обработчик вызывается последовательно из горутины монитора
+type TagMonitor interface {
+	Monitor(ctx context.Context, addresses []string, handle func(TagEvent))
+}
+
+// NewTagMonitor возвращает монитор тегов, выбранный в настройках контроллера
+func NewTagMonitor(p *PLC, logger zerolog.Logger) TagMonitor {
+	if p.monitor == MonitorSubscription {
+		return &subscriptionMonitor{plc: p, logger: logger}
+	}
+	return &pollingMonitor{plc: p, logger: logger}
+}
+
+// changedAddresses возвращает адреса тегов из next, значения которых отличаются от prev
+func changedAddresses(prev map[string]values.PlcValue, next map[string]values.PlcValue, addresses []string) []string {
+	var changed []string
+	for _, address := range addresses {
+		v, ok := next[address]
+		if !ok {
+			continue
+		}
+		if p, ok := prev[address]; ok && reflect.DeepEqual(p, v) {
+			continue
+		}
+		changed = append(changed, address)
+	}
+	return changed
+}
@@ -2,118 +2,494 @@ package plc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	plc4go "github.com/apache/plc4x/plc4go/pkg/api"
+	"github.com/apache/plc4x/plc4go/pkg/api/values"
 	"github.com/c0dered273/automation-remote-controller/internal/remote-control-client/configs"
+	"github.com/c0dered273/automation-remote-controller/internal/remote-control-client/expr"
 	"github.com/c0dered273/automation-remote-controller/pkg/model"
 	"github.com/rs/zerolog"
 )
 
+var ErrPollingStopped = errors.New("plc polling: client is stopping")
+
 var (
-	plcReadDelay = 100 * time.Millisecond
+	// plcWriteQueue количество команд, ожидающих выполнения на одном контроллере
+	plcWriteQueue = 16
+	// pulseReleaseTimeout время на выключение выхода после импульса, в том числе при остановке клиента
+	pulseReleaseTimeout = 5 * time.Second
+	// alarmTick период проверки тревог, ожидающих истечения задержки срабатывания или снятия
+	alarmTick = time.Second
 )
 
-// NewPlcConn возвращает настроенный пул соединений с контроллером
-func NewPlcConn(driverManager plc4go.PlcDriverManager, config *configs.RClientConfig) (*ConnPool, error) {
-	conn, err := NewConnPool(driverManager, config.PLCUri)
+// PollService сервис организует прием и передачу событий на контроллеры используя пулы соединений
+// события приходят из других компонентов приложения через приемный и передающий каналы,
+// каждый контроллер опрашивается и получает команды независимо от остальных
+type PollService struct {
+	ctx          context.Context
+	plcs         PLCs
+	sendChan     chan model.NotifyEvent
+	receiveChan  chan model.ActionEvent
+	ackChan      chan model.AckEvent
+	stateReqChan chan model.StateRequest
+	stateChan    chan model.StateEvent
+	// writes выполняющиеся команды, остановка клиента дожидается их завершения
+	// writesMu защищает регистрацию команд, после установки closing новые команды не выполняются
+	writes   sync.WaitGroup
+	writesMu sync.Mutex
+	closing  bool
+	logger   zerolog.Logger
+}
+
+// continuousRead отслеживает теги одного контроллера монитором, выбранным в настройках контроллера,
+// и по событиям изменения тегов вычисляет условия тревог уведомлений
+func (s *PollService) continuousRead(p *PLC, notifications []configs.Notifications, addresses []string) {
+	alarms := make([]*alarm, 0, len(notifications))
+	for _, n := range notifications {
+		alarms = append(alarms, newAlarm(n))
+	}
+
+	events := make(chan TagEvent)
+	go NewTagMonitor(p, s.logger).Monitor(s.ctx, addresses, func(e TagEvent) {
+		select {
+		case events <- e:
+		case <-s.ctx.Done():
+		}
+	})
+
+	// Тревоги с задержкой срабатывания и выражения с таймерами вычисляются периодически,
+	// так как монитор присылает только изменения тегов
+	tick := alarmTick
+	if p.scanInterval < tick {
+		tick = p.scanInterval
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	var last TagEvent
+	for {
+		var now time.Time
+		select {
+		case <-s.ctx.Done():
+			return
+		case last = <-events:
+			now = time.Now()
+			for _, a := range alarms {
+				s.observe(p, a, last, now)
+			}
+		case now = <-ticker.C:
+			// без связи с контроллером выражения не вычисляются по устаревшим значениям тегов
+			if !p.Healthy() {
+				last = TagEvent{}
+			}
+			for _, a := range alarms {
+				if a.notification.Condition == configs.ConditionExpr {
+					s.observe(p, a, last, now)
+				} else if a.pending() && a.step(now) {
+					s.notify(p, a, last, now)
+				}
+			}
+		}
+	}
+}
+
+// observe вычисляет условие тревоги по значениям тегов события и отправляет уведомление при изменении состояния тревоги
+func (s *PollService) observe(p *PLC, a *alarm, e TagEvent, now time.Time) {
+	if err := a.observe(e.Values, now); err != nil {
+		// значения тегов, которые не удалось прочитать, отсутствуют в событии, ошибка чтения уже записана в журнал
+		if !errors.Is(err, expr.ErrNoValue) {
+			s.logger.Error().Err(err).Msgf("plc polling: notification %s/%s", p.Name, a.notification.ID())
+		}
+		return
+	}
+	if a.step(now) {
+		s.notify(p, a, e, now)
+	}
+}
+
+// notify отправляет сообщение о срабатывании или снятии тревоги, если для него задан текст
+// текст формируется по шаблону из значений тегов события e
+func (s *PollService) notify(p *PLC, a *alarm, e TagEvent, now time.Time) {
+	n := a.notification
+	text, ok, err := n.Render(a.data(p.Name, e.Values, now))
+	if !ok {
+		return
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to init plc connection pool: %w", err)
+		s.logger.Error().Err(err).Msg("plc polling: failed to render notification text")
+		text = n.ID()
+	}
+	severity, _ := model.NewSeverity(n.Severity)
+	s.sendChan <- model.NotifyEvent{
+		AlertID:  s.alertID(p, n),
+		Text:     text,
+		Severity: severity,
 	}
-	conn.SetMaxOpenConns(4)
-	conn.SetConnTimeout(3 * time.Second)
-	return conn, nil
 }
 
-// PollService сервис организует прием и передачу событий на контроллер используя пул соединений
-// события приходят из других компонентов приложения через приемный и передающий каналы
-type PollService struct {
-	ctx         context.Context
-	conn        *ConnPool
-	sendChan    chan model.NotifyEvent
-	receiveChan chan model.ActionEvent
-	logger      zerolog.Logger
+// alertID идентификатор источника уведомления, при нескольких контроллерах идентификатор уведомления дополняется именем контроллера
+func (s *PollService) alertID(p *PLC, n configs.Notifications) string {
+	if len(s.plcs) == 1 {
+		return n.ID()
+	}
+	return p.Name + "/" + n.ID()
 }
 
-func (s *PollService) continuousRead(config *configs.RClientConfig) {
-	riseTrig := make(map[string]bool)
+// continuousWrite распределяет команды по очередям контроллеров, к которым подключены устройства
+func (s *PollService) continuousWrite(config *configs.RClientConfig) {
+	queues := make(map[string]chan model.ActionEvent, len(s.plcs))
+	for name, p := range s.plcs {
+		queue := make(chan model.ActionEvent, plcWriteQueue)
+		queues[name] = queue
+		go s.plcWrite(config, p, queue)
+	}
 	for {
-		for _, n := range config.Notifications {
-			tag := strings.Split(n.TagAddress, "/")
-			tagAddress := tag[0]
-			bit, err := strconv.Atoi(tag[1])
-			if err != nil {
-				s.logger.Error().Err(err).Msgf("plc polling: failed to parse bit number: %s", tag[1])
-				continue
-			}
+		var a model.ActionEvent
+		select {
+		case <-s.ctx.Done():
+			return
+		case a = <-s.receiveChan:
+		}
+		cfg, ok := findDevice(config, a.DeviceID)
+		if !ok {
+			s.ack(a, fmt.Errorf("plc polling: device %s not found", a.DeviceID))
+			continue
+		}
+		select {
+		case queues[cfg.PLC] <- a:
+		default:
+			s.ack(a, fmt.Errorf("plc polling: too many pending commands for plc %s", cfg.PLC))
+		}
+	}
+}
 
-			resp, err := s.conn.ReadTagAddress(s.ctx, "read", tagAddress)
-			if err != nil {
-				s.logger.Error().Err(err).Msgf("plc polling: failed to read tag: %s", tagAddress)
-				continue
+// plcWrite выполняет команды одного контроллера в порядке поступления
+func (s *PollService) plcWrite(config *configs.RClientConfig, p *PLC, queue <-chan model.ActionEvent) {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case a := <-queue:
+			if !s.beginWrite() {
+				s.ack(a, ErrPollingStopped)
+				return
 			}
-			value := resp.GetValue("read").GetBoolArray()
+			s.ack(a, s.write(config, p, a))
+			s.writes.Done()
+		}
+	}
+}
 
-			// Rise trigger нужен для исключения повторной отправки сообщения, если тег в контроллере все еще активен,
-			// отправка события происходит по переднему фронту сигнала
-			_, ok := riseTrig[n.TagAddress]
-			if !ok {
-				riseTrig[n.TagAddress] = false
-			}
-			if value[bit] && !riseTrig[n.TagAddress] {
-				s.sendChan <- model.NotifyEvent{
-					Text: n.Text[strconv.FormatBool(value[bit])],
-				}
-				riseTrig[n.TagAddress] = true
+// ack отправляет серверу результат выполнения команды, при остановке клиента результат не отправляется
+func (s *PollService) ack(a model.ActionEvent, err error) {
+	ack := model.AckEvent{
+		ID:       a.ID,
+		DeviceID: a.DeviceID,
+		Action:   a.Action,
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Send()
+		ack.Error = err.Error()
+	} else {
+		ack.Success = true
+	}
+	select {
+	case s.ackChan <- ack:
+	case <-s.ctx.Done():
+	}
+}
+
+// write выполняет команду: Toggle и Pulse вычисляются по значениям SwitchON и SwitchOFF,
+// SetValue записывает значение из команды, остальные команды записывают в тег значение из настроек устройства
+func (s *PollService) write(config *configs.RClientConfig, p *PLC, a model.ActionEvent) error {
+	cfg, ok := findDevice(config, a.DeviceID)
+	if !ok {
+		return fmt.Errorf("plc polling: device %s not found", a.DeviceID)
+	}
+	conn, err := p.Conn()
+	if err != nil {
+		return err
+	}
+	switch {
+	case a.Action == model.Toggle:
+		return s.toggle(conn, cfg)
+	case a.Action == model.Pulse && cfg.Pulse > 0:
+		return s.pulse(conn, cfg)
+	case a.Action == model.SetValue:
+		return s.setValue(conn, cfg, a.Value)
+	}
+
+	value, ok := cfg.Values[strings.ToLower(a.Action.String())]
+	if !ok {
+		return fmt.Errorf("plc polling: action %s not found", a.Action.String())
+	}
+	return s.writeValue(s.ctx, conn, cfg, value)
+}
+
+// writeValue записывает значение в тег устройства, для битового выхода записывается только его бит
+func (s *PollService) writeValue(ctx context.Context, conn *ConnPool, cfg configs.Devices, value string) error {
+	if address, bit, ok := cfg.Bit(); ok {
+		// значения битовых выходов проверяются при загрузке конфигурации
+		on, _ := strconv.ParseBool(value)
+		if err := conn.WriteTagBit(ctx, address, bit, on); err != nil {
+			return fmt.Errorf("plc polling: failed to writing plc tag bit: %s, value %s, %w", cfg.TagAddress, value, err)
+		}
+		return nil
+	}
+	if _, err := conn.WriteTagAddress(ctx, "write", cfg.TagAddress, value); err != nil {
+		return fmt.Errorf("plc polling: failed to writing plc tag: %s, value %s, %w", cfg.TagAddress, value, err)
+	}
+	return nil
+}
+
+// setValue проверяет значение по диапазону и шагу уставки устройства и записывает его в тег в единицах регистра
+func (s *PollService) setValue(conn *ConnPool, cfg configs.Devices, value *float64) error {
+	if cfg.Setpoint == nil {
+		return fmt.Errorf("plc polling: device %s: setpoint is not configured", cfg.DeviceID)
+	}
+	if value == nil {
+		return fmt.Errorf("plc polling: device %s: value is required", cfg.DeviceID)
+	}
+	if err := setpointModel(cfg.Setpoint).Check(*value); err != nil {
+		return fmt.Errorf("plc polling: device %s: %w", cfg.DeviceID, err)
+	}
+	raw, err := cfg.Setpoint.Raw(*value)
+	if err != nil {
+		return fmt.Errorf("plc polling: device %s: %w", cfg.DeviceID, err)
+	}
+	if _, err := conn.WriteTagAddress(s.ctx, "write", cfg.TagAddress, raw); err != nil {
+		return fmt.Errorf("plc polling: failed to writing plc tag: %s, value %v, %w", cfg.TagAddress, raw, err)
+	}
+	return nil
+}
+
+// setpointModel диапазон уставки для проверки значения и передачи серверу
+func setpointModel(s *configs.Setpoint) *model.Setpoint {
+	return &model.Setpoint{Min: s.Min, Max: s.Max, Step: s.Step, Unit: s.Unit}
+}
+
+// toggle переключает выход: если в теге значение SwitchON, записывается SwitchOFF, иначе SwitchON
+// текущее значение читается из контроллера под блокировкой регистра, чтобы переключение не пересекалось с другими записями
+func (s *PollService) toggle(conn *ConnPool, cfg configs.Devices) error {
+	on, off, ok := cfg.Switchable()
+	if !ok {
+		return fmt.Errorf("plc polling: device %s: toggle requires SwitchON and SwitchOFF values", cfg.DeviceID)
+	}
+	if address, bit, ok := cfg.Bit(); ok {
+		onBit, _ := strconv.ParseBool(on)
+		offBit, _ := strconv.ParseBool(off)
+		err := conn.UpdateTagBit(s.ctx, address, bit, func(current bool) bool {
+			if current == onBit {
+				return offBit
 			}
-			riseTrig[n.TagAddress] = value[bit]
-			time.Sleep(plcReadDelay)
+			return onBit
+		})
+		if err != nil {
+			return fmt.Errorf("plc polling: failed to toggle plc tag bit: %s, %w", cfg.TagAddress, err)
+		}
+		return nil
+	}
+	err := conn.UpdateTagAddress(s.ctx, cfg.TagAddress, func(current values.PlcValue) (any, error) {
+		if sameValue(current, on) {
+			return off, nil
 		}
+		return on, nil
+	})
+	if err != nil {
+		return fmt.Errorf("plc polling: failed to toggle plc tag: %s, %w", cfg.TagAddress, err)
 	}
+	return nil
 }
 
-func (s *PollService) continuousWrite(config *configs.RClientConfig) {
+// pulse включает выход на время импульса и выключает его.
+// Выключение выполняется и при отмене контекста или ошибке включения, с отдельным таймаутом,
+// чтобы остановка клиента во время импульса не оставила выход включенным
+func (s *PollService) pulse(conn *ConnPool, cfg configs.Devices) error {
+	on, off, _ := cfg.Switchable()
+	onErr := s.writeValue(s.ctx, conn, cfg, on)
+	if onErr == nil {
+		timer := time.NewTimer(cfg.Pulse)
+		select {
+		case <-timer.C:
+		case <-s.ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pulseReleaseTimeout)
+	defer cancel()
+	offErr := s.writeValue(ctx, conn, cfg, off)
+	if offErr != nil {
+		offErr = fmt.Errorf("plc polling: device %s: failed to release pulse output: %w", cfg.DeviceID, offErr)
+	}
+	return errors.Join(onErr, offErr)
+}
+
+// sameValue сравнивает значение тега со значением из настроек, числа сравниваются численно
+func sameValue(current values.PlcValue, value string) bool {
+	if f, err := strconv.ParseFloat(value, 64); err == nil && current.IsFloat64() {
+		return current.GetFloat64() == f
+	}
+	if b, err := strconv.ParseBool(value); err == nil && current.GetPlcValueType() == values.BOOL {
+		return current.GetBool() == b
+	}
+	return current.IsString() && current.GetString() == value
+}
+
+// continuousState отвечает на запросы сервера о текущем состоянии устройств
+func (s *PollService) continuousState(config *configs.RClientConfig) {
 	for {
-		a := <-s.receiveChan
-		for _, cfg := range config.Devices {
-			if strings.EqualFold(a.DeviceID, cfg.DeviceID) {
-				value, ok := cfg.Values[strings.ToLower(a.Action.String())]
-				if !ok {
-					s.logger.Error().Msgf("plc polling: action %s not found", a.Action.String())
+		select {
+		case <-s.ctx.Done():
+			return
+		case r := <-s.stateReqChan:
+			state := model.StateEvent{ID: r.ID}
+			if len(r.DeviceIDs) == 0 {
+				for _, cfg := range config.Devices {
+					state.Devices = append(state.Devices, s.readState(cfg))
 				}
-
-				_, err := s.conn.WriteTagAddress(s.ctx, "write", cfg.TagAddress, value)
-				if err != nil {
-					s.logger.Error().Err(err).Msgf("plc polling: failed to writing plc tag: %s, value %s", cfg.TagAddress, value)
+			}
+			for _, id := range r.DeviceIDs {
+				cfg, ok := findDevice(config, id)
+				if !ok {
+					state.Devices = append(state.Devices, model.DeviceState{DeviceID: id, Error: "device not found"})
 					continue
 				}
+				state.Devices = append(state.Devices, s.readState(cfg))
 			}
+			s.stateChan <- state
 		}
 	}
 }
 
-// Polling запускает циклический опрос событий с контроллера и запись данных в теги контроллера
+func (s *PollService) readState(cfg configs.Devices) model.DeviceState {
+	state := model.DeviceState{
+		DeviceID: cfg.DeviceID,
+		Room:     cfg.Room,
+	}
+	for action := range cfg.Values {
+		state.Actions = append(state.Actions, action)
+	}
+	if _, _, ok := cfg.Switchable(); ok {
+		for _, action := range []model.Action{model.Toggle, model.Pulse} {
+			name := strings.ToLower(action.String())
+			if _, ok := cfg.Values[name]; ok || (action == model.Pulse && cfg.Pulse == 0) {
+				continue
+			}
+			state.Actions = append(state.Actions, name)
+		}
+	}
+	if cfg.Setpoint != nil {
+		state.Actions = append(state.Actions, strings.ToLower(model.SetValue.String()))
+		state.Setpoint = setpointModel(cfg.Setpoint)
+	}
+	sort.Strings(state.Actions)
+	p := s.plcs[cfg.PLC]
+	address, bit, isBit := cfg.Bit()
+	v, ok := p.lastValue(address)
+	if !ok {
+		conn, err := p.Conn()
+		if err != nil {
+			state.Error = err.Error()
+			return state
+		}
+		resp, err := conn.ReadTagAddress(s.ctx, "read", address)
+		if err != nil {
+			s.logger.Error().Err(err).Msgf("plc polling: failed to read tag: %s", address)
+			state.Error = err.Error()
+			return state
+		}
+		v = resp.GetValue("read")
+	}
+	if cfg.Setpoint != nil && v.IsFloat64() {
+		// значение уставки передается в единицах устройства
+		state.Value = model.FormatValue(cfg.Setpoint.Value(v.GetFloat64()))
+		return state
+	}
+	if !isBit {
+		state.Value = v.GetString()
+		return state
+	}
+	bits := v.GetBoolArray()
+	if bit >= len(bits) {
+		state.Error = fmt.Sprintf("bit %d is out of range of register %s", bit, address)
+		return state
+	}
+	state.Value = strconv.FormatBool(bits[bit])
+	return state
+}
+
+// findDevice поиск устройства в конфигурации по идентификатору без учета регистра
+func findDevice(config *configs.RClientConfig, deviceID string) (configs.Devices, bool) {
+	for _, cfg := range config.Devices {
+		if strings.EqualFold(deviceID, cfg.DeviceID) {
+			return cfg, true
+		}
+	}
+	return configs.Devices{}, false
+}
+
+// Polling запускает циклический опрос событий с контроллеров и запись данных в теги контроллеров
 func (s *PollService) Polling(config *configs.RClientConfig) {
-	go s.continuousRead(config)
+	notifications := make(map[string][]configs.Notifications)
+	for _, n := range config.Notifications {
+		notifications[n.PLC] = append(notifications[n.PLC], n)
+	}
+	for name, p := range s.plcs {
+		addresses := scanAddresses(name, config)
+		if len(addresses) == 0 {
+			continue
+		}
+		go s.continuousRead(p, notifications[name], addresses)
+	}
 	go s.continuousWrite(config)
+	go s.continuousState(config)
+}
+
+// beginWrite регистрирует выполняемую команду, false - сервис останавливается и команду выполнять нельзя
+func (s *PollService) beginWrite() bool {
+	s.writesMu.Lock()
+	defer s.writesMu.Unlock()
+	if s.closing || s.ctx.Err() != nil {
+		return false
+	}
+	s.writes.Add(1)
+	return true
+}
+
+// Wait запрещает выполнение новых команд и дожидается завершения выполняющихся,
+// вызывается после отмены контекста сервиса и до закрытия пулов соединений, чтобы импульсные выходы успели выключиться
+func (s *PollService) Wait() {
+	s.writesMu.Lock()
+	s.closing = true
+	s.writesMu.Unlock()
+	s.writes.Wait()
 }
 
 // NewPLCPollService возвращает настроенный сервис опроса ПЛК
 func NewPLCPollService(
 	ctx context.Context,
-	conn *ConnPool,
+	plcs PLCs,
 	sendChan chan model.NotifyEvent,
 	receiveChan chan model.ActionEvent,
+	ackChan chan model.AckEvent,
+	stateReqChan chan model.StateRequest,
+	stateChan chan model.StateEvent,
 	logger zerolog.Logger,
 ) *PollService {
 	return &PollService{
-		ctx:         ctx,
-		conn:        conn,
-		sendChan:    sendChan,
-		receiveChan: receiveChan,
-		logger:      logger,
+		ctx:          ctx,
+		plcs:         plcs,
+		sendChan:     sendChan,
+		receiveChan:  receiveChan,
+		ackChan:      ackChan,
+		stateReqChan: stateReqChan,
+		stateChan:    stateChan,
+		logger:       logger,
 	}
 }
@@ -0,0 +1,123 @@
+package plc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	plcModel "github.com/apache/plc4x/plc4go/pkg/api/model"
+	"github.com/apache/plc4x/plc4go/pkg/api/values"
+	"github.com/c0dered273/automation-remote-controller/internal/remote-control-client/configs"
+	"github.com/rs/zerolog"
+)
+
+// stateMaxAge количество циклов опроса, в течение которых значение тега считается актуальным для запроса состояния
+const stateMaxAge = 2
+
+// scanAddresses адреса тегов контроллера для циклического опроса: регистры уведомлений, выражений и устройств без повторов
+// в порядке их появления в конфигурации, номер бита в адресе уведомления отбрасывается
+func scanAddresses(plcName string, config *configs.RClientConfig) []string {
+	var addresses []string
+	seen := make(map[string]struct{})
+	add := func(address string) {
+		if _, ok := seen[address]; ok {
+			return
+		}
+		seen[address] = struct{}{}
+		addresses = append(addresses, address)
+	}
+	for _, n := range config.Notifications {
+		switch {
+		case n.PLC != plcName:
+		case n.Condition == configs.ConditionExpr:
+			for _, address := range n.Expr().Addresses() {
+				add(address)
+			}
+		default:
+			address, _, _ := strings.Cut(n.TagAddress, "/")
+			add(address)
+		}
+	}
+	for _, d := range config.Devices {
+		if d.PLC == plcName {
+			address, _, _ := d.Bit()
+			add(address)
+		}
+	}
+	return addresses
+}
+
+// batchAddresses разбивает адреса на группы не более maxTags адресов, каждая группа читается одним запросом
+func batchAddresses(addresses []string, maxTags int) [][]string {
+	if maxTags <= 0 {
+		maxTags = len(addresses)
+	}
+	var batches [][]string
+	for len(addresses) > maxTags {
+		batches = append(batches, addresses[:maxTags])
+		addresses = addresses[maxTags:]
+	}
+	if len(addresses) > 0 {
+		batches = append(batches, addresses)
+	}
+	return batches
+}
+
+// pollingMonitor получает значения тегов циклическим опросом контроллера с периодом scanInterval
+type pollingMonitor struct {
+	plc    *PLC
+	logger zerolog.Logger
+}
+
+// Monitor опрашивает теги, пока контроллер недоступен, опрос приостанавливается
+// за цикл каждый адрес читается один раз, событие формируется, если значение хотя бы одного тега изменилось
+func (m *pollingMonitor) Monitor(ctx context.Context, addresses []string, handle func(TagEvent)) {
+	p := m.plc
+	batches := batchAddresses(addresses, p.maxTags)
+	ticker := time.NewTicker(p.scanInterval)
+	defer ticker.Stop()
+	var prev map[string]values.PlcValue
+	for {
+		if err := p.WaitHealthy(ctx); err != nil {
+			return
+		}
+		values := m.scan(ctx, batches)
+		p.setValues(values, time.Duration(stateMaxAge)*p.scanInterval)
+		if changed := changedAddresses(prev, values, addresses); len(changed) > 0 {
+			handle(TagEvent{Values: values, Changed: changed})
+		}
+		prev = values
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan вычитывает все теги контроллера за один цикл опроса
+// теги, которые не удалось прочитать, отсутствуют в результате
+func (m *pollingMonitor) scan(ctx context.Context, batches [][]string) map[string]values.PlcValue {
+	p := m.plc
+	result := make(map[string]values.PlcValue)
+	for _, batch := range batches {
+		conn, err := p.Conn()
+		if err != nil {
+			break
+		}
+		resp, err := conn.ReadTagAddresses(ctx, batch)
+		if err != nil {
+			m.logger.Error().Err(err).Msgf("plc polling: failed to read tags: %s/%s", p.Name, strings.Join(batch, ", "))
+			continue
+		}
+		for _, address := range batch {
+			if code := resp.GetResponseCode(address); code != plcModel.PlcResponseCode_OK {
+				m.logger.Error().Msgf("plc polling: failed to read tag: %s/%s: %s", p.Name, address, code.GetName())
+				continue
+			}
+			result[address] = resp.GetValue(address)
+		}
+	}
+	return result
+}
@@ -0,0 +1,108 @@
+package plc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	plcModel "github.com/apache/plc4x/plc4go/pkg/api/model"
+	"github.com/apache/plc4x/plc4go/pkg/api/values"
+	"github.com/rs/zerolog"
+)
+
+// subscriptionQueue количество полученных от драйвера событий, ожидающих обработки
+const subscriptionQueue = 64
+
+// subscriptionMonitor получает значения тегов по подписке, контроллер сам присылает изменения тегов
+// подписка держит отдельное соединение пула и оформляется заново после восстановления связи с контроллером
+type subscriptionMonitor struct {
+	plc    *PLC
+	logger zerolog.Logger
+}
+
+// Monitor оформляет подписку на теги и передает обработчику изменения их значений
+// если протокол контроллера не поддерживает подписки, теги опрашиваются циклически
+func (m *subscriptionMonitor) Monitor(ctx context.Context, addresses []string, handle func(TagEvent)) {
+	p := m.plc
+	for {
+		if err := p.WaitHealthy(ctx); err != nil {
+			return
+		}
+		err := m.subscribe(ctx, addresses, handle)
+		p.setValues(nil, 0)
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, ErrConnNoSubscribe) {
+			m.logger.Warn().Err(err).Msgf("plc monitor: controller %s doesn't support subscriptions, fallback to polling", p.Name)
+			(&pollingMonitor{plc: p, logger: m.logger}).Monitor(ctx, addresses, handle)
+			return
+		}
+		m.logger.Error().Err(err).Msgf("plc monitor: subscription to controller %s interrupted", p.Name)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.healthInterval):
+		}
+	}
+}
+
+// subscribe держит подписку до потери связи с контроллером или отмены контекста
+func (m *subscriptionMonitor) subscribe(ctx context.Context, addresses []string, handle func(TagEvent)) error {
+	p := m.plc
+	conn, err := p.Conn()
+	if err != nil {
+		return err
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		if err := p.waitUnhealthy(subCtx); err == nil {
+			cancel()
+		}
+	}()
+
+	events := make(chan map[string]values.PlcValue, subscriptionQueue)
+	done := make(chan error, 1)
+	go func() {
+		done <- conn.Subscribe(subCtx, p.subscription, p.scanInterval, addresses, func(e plcModel.PlcSubscriptionEvent) {
+			received := make(map[string]values.PlcValue)
+			for _, name := range e.GetTagNames() {
+				if e.GetResponseCode(name) == plcModel.PlcResponseCode_OK {
+					received[name] = e.GetValue(name)
+				}
+			}
+			select {
+			case events <- received:
+			case <-subCtx.Done():
+			}
+		})
+	}()
+
+	current := make(map[string]values.PlcValue, len(addresses))
+	for {
+		select {
+		case err := <-done:
+			if subCtx.Err() != nil && ctx.Err() == nil {
+				return ErrPLCUnavailable
+			}
+			return err
+		case received := <-events:
+			next := make(map[string]values.PlcValue, len(addresses))
+			for address, v := range current {
+				next[address] = v
+			}
+			for address, v := range received {
+				next[address] = v
+			}
+			changed := changedAddresses(current, next, addresses)
+			current = next
+			p.setValues(current, 0)
+			if len(changed) > 0 {
+				handle(TagEvent{Values: current, Changed: changed})
+			}
+		}
+	}
+}
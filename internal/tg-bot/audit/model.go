@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"time"
+)
+
+// Kind тип записи журнала
+type Kind string
+
+const (
+	// KindAction команда устройству
+	KindAction Kind = "action"
+	// KindNotification уведомление от хаба
+	KindNotification Kind = "notification"
+)
+
+// Result результат команды или доставки уведомления
+type Result string
+
+const (
+	ResultOK     Result = "ok"
+	ResultDenied Result = "denied"
+	ResultFailed Result = "failed"
+	ResultMuted  Result = "muted"
+	// ResultLocked команда отклонена из-за блокировки ввода PIN-кода после нескольких ошибок
+	ResultLocked Result = "locked"
+)
+
+// Entry запись журнала команд и уведомлений
+type Entry struct {
+	ID        int64     `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+	Kind      Kind      `db:"kind"`
+	// UserID пользователь, отправивший команду или получивший уведомление
+	// при записи можно указать только TGUser
+	UserID   int64  `db:"user_id"`
+	TGUser   string `db:"tg_user"`
+	ChatID   int64  `db:"chat_id"`
+	HubID    int64  `db:"client_id"`
+	HubName  string `db:"client_name"`
+	DeviceID string `db:"device_id"`
+	Action   string `db:"action"`
+	// Source источник команды: bot, scene, schedule
+	Source string `db:"source"`
+	Result Result `db:"result"`
+	Error  string `db:"error"`
+	// LatencyMs время отправки команды на хаб
+	LatencyMs int64 `db:"latency_ms"`
+	// Text текст уведомления
+	Text string `db:"text"`
+}
+
+// Filter условия выборки журнала, пустые поля не учитываются
+type Filter struct {
+	HubName  string
+	DeviceID string
+	Kind     Kind
+	Limit    int
+	Offset   int
+}
@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// AuditRepository описывает методы работы с журналом команд и уведомлений
+type AuditRepository interface {
+	// SaveEntry сохраняет запись журнала
+	SaveEntry(ctx context.Context, e Entry) error
+	// FindEntriesByTGUser возвращает записи журнала по хабам, участником которых является пользователь, от новых к старым
+	FindEntriesByTGUser(ctx context.Context, tgUser string, f Filter) ([]Entry, error)
+	// DeleteEntriesBefore удаляет записи старше указанного времени и возвращает их количество
+	DeleteEntriesBefore(ctx context.Context, t time.Time) (int64, error)
+}
+
+type SQLAuditRepo struct {
+	db *sqlx.DB
+}
+
+func (r SQLAuditRepo) SaveEntry(ctx context.Context, e Entry) error {
+	const sqlQuery = `INSERT INTO audit_log(kind, user_id, chat_id, client_id, device_id, action, source, result, error, latency_ms, text)
+		VALUES($1,
+		       COALESCE(NULLIF($2::int, 0), (SELECT id FROM users WHERE tg_user = NULLIF($3::text, ''))),
+		       NULLIF($4::bigint, 0), NULLIF($5::int, 0), $6, $7, $8, $9, $10, $11, $12)`
+
+	_, err := r.db.ExecContext(ctx, sqlQuery,
+		e.Kind, e.UserID, e.TGUser, e.ChatID, e.HubID, e.DeviceID, e.Action, e.Source, e.Result, e.Error, e.LatencyMs, e.Text)
+	return err
+}
+
+func (r SQLAuditRepo) FindEntriesByTGUser(ctx context.Context, tgUser string, f Filter) ([]Entry, error) {
+	const sqlQuery = `SELECT a.id, a.created_at, a.kind, COALESCE(a.user_id, 0) AS user_id, COALESCE(u.tg_user, '') AS tg_user,
+		       COALESCE(a.chat_id, 0) AS chat_id, a.client_id, c.name AS client_name, a.device_id, a.action, a.source,
+		       a.result, a.error, a.latency_ms, a.text
+		FROM audit_log a
+		         JOIN clients c ON c.id = a.client_id
+		         JOIN hub_members m ON m.client_id = a.client_id
+		         JOIN users mu ON mu.id = m.user_id
+		         LEFT JOIN users u ON u.id = a.user_id
+		WHERE mu.tg_user = $1
+		  AND ($2::text = '' OR c.name = $2)
+		  AND ($3::text = '' OR a.device_id = $3)
+		  AND ($4::text = '' OR a.kind = $4)
+		ORDER BY a.id DESC
+		LIMIT $5 OFFSET $6`
+
+	result := make([]Entry, 0)
+	err := r.db.SelectContext(ctx, &result, sqlQuery, tgUser, f.HubName, f.DeviceID, f.Kind, f.Limit, f.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r SQLAuditRepo) DeleteEntriesBefore(ctx context.Context, t time.Time) (int64, error) {
+	const sqlQuery = `DELETE FROM audit_log WHERE created_at < $1`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, t)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func NewRepo(db *sqlx.DB) SQLAuditRepo {
+	return SQLAuditRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultLimit количество записей журнала на странице по умолчанию
+const DefaultLimit = 10
+
+// AuditService журнал команд устройствам и уведомлений
+type AuditService interface {
+	// Record сохраняет запись журнала, ошибка записи не прерывает выполнение команды и только логируется
+	Record(ctx context.Context, e Entry)
+	// FindHistory возвращает записи журнала по хабам пользователя
+	FindHistory(ctx context.Context, tgName string, f Filter) ([]Entry, error)
+	// Start запускает периодическое удаление записей старше срока хранения
+	Start()
+}
+
+type AuditServiceImpl struct {
+	ctx       context.Context
+	auditRepo AuditRepository
+	retention time.Duration
+	interval  time.Duration
+	logger    zerolog.Logger
+}
+
+func (s AuditServiceImpl) Record(ctx context.Context, e Entry) {
+	if err := s.auditRepo.SaveEntry(ctx, e); err != nil {
+		s.logger.Error().Err(err).Str("kind", string(e.Kind)).Msg("audit: failed to save entry")
+	}
+}
+
+func (s AuditServiceImpl) FindHistory(ctx context.Context, tgName string, f Filter) ([]Entry, error) {
+	if f.Limit <= 0 {
+		f.Limit = DefaultLimit
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	return s.auditRepo.FindEntriesByTGUser(ctx, tgName, f)
+}
+
+func (s AuditServiceImpl) cleanup(now time.Time) {
+	n, err := s.auditRepo.DeleteEntriesBefore(s.ctx, now.Add(-s.retention))
+	if err != nil {
+		s.logger.Error().Err(err).Msg("audit: failed to delete expired entries")
+		return
+	}
+	if n > 0 {
+		s.logger.Info().Int64("deleted", n).Msg("audit: expired entries deleted")
+	}
+}
+
+func (s AuditServiceImpl) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		s.cleanup(time.Now())
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.cleanup(now)
+			}
+		}
+	}()
+}
+
+// NewAuditService создает сервис журнала, записи старше retention удаляются с периодом interval
+func NewAuditService(ctx context.Context, auditRepo AuditRepository, retention time.Duration, interval time.Duration, logger zerolog.Logger) AuditServiceImpl {
+	return AuditServiceImpl{
+		ctx:       ctx,
+		auditRepo: auditRepo,
+		retention: retention,
+		interval:  interval,
+		logger:    logger,
+	}
+}
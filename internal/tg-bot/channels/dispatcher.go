@@ -0,0 +1,77 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrChannelDisabled = errors.New("channels: channel is not configured")
+)
+
+// Dispatcher выбирает реализацию канала по типу адресата
+type Dispatcher struct {
+	ctx      context.Context
+	mu       sync.RWMutex
+	channels map[Kind]Channel
+	logger   zerolog.Logger
+}
+
+// Register добавляет канал, telegram регистрируется после запуска бота
+func (d *Dispatcher) Register(ch Channel) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.channels[ch.Kind()] = ch
+}
+
+// Enabled проверяет, настроен ли канал
+func (d *Dispatcher) Enabled(kind Kind) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	_, ok := d.channels[kind]
+	return ok
+}
+
+// Send отправляет сообщение и ждет результата
+func (d *Dispatcher) Send(ctx context.Context, dst Destination, msg Message) error {
+	d.mu.RLock()
+	ch, ok := d.channels[dst.Kind]
+	d.mu.RUnlock()
+	if !ok {
+		return ErrChannelDisabled
+	}
+	return ch.Send(ctx, dst, msg)
+}
+
+// Dispatch отправляет сообщение, не дожидаясь внешних каналов, ошибки доставки записываются в лог
+// telegram только ставит сообщение в очередь бота, поэтому отправляется сразу
+func (d *Dispatcher) Dispatch(dst Destination, msg Message) {
+	log := d.logger.With().Str("channel", string(dst.Kind)).Int64("userID", dst.UserID).Logger()
+	if dst.Kind == KindTelegram {
+		if err := d.Send(d.ctx, dst, msg); err != nil {
+			log.Error().Err(err).Msg("dispatcher: failed to send notification")
+		}
+		return
+	}
+	go func() {
+		if err := d.Send(d.ctx, dst, msg); err != nil {
+			log.Error().Err(err).Int64("channelID", dst.ID).Msg("dispatcher: failed to send notification")
+		}
+	}()
+}
+
+// NewDispatcher создает диспетчер с указанными каналами
+func NewDispatcher(ctx context.Context, logger zerolog.Logger, channels ...Channel) *Dispatcher {
+	d := &Dispatcher{
+		ctx:      ctx,
+		channels: make(map[Kind]Channel),
+		logger:   logger,
+	}
+	for _, ch := range channels {
+		d.Register(ch)
+	}
+	return d
+}
@@ -0,0 +1,105 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/configs"
+)
+
+// EmailChannel отправляет уведомления по SMTP
+type EmailChannel struct {
+	config configs.SMTPCfg
+}
+
+func (c EmailChannel) Kind() Kind {
+	return KindEmail
+}
+
+// subject тема письма: важность, хаб и начало текста
+func subject(msg Message) string {
+	text := []rune(strings.SplitN(msg.Text, "\n", 2)[0])
+	if len(text) > 64 {
+		text = append(text[:64], '…')
+	}
+	return mime.QEncoding.Encode("utf-8", fmt.Sprintf("[%s] %s: %s", msg.Severity, msg.HubName, string(text)))
+}
+
+func (c EmailChannel) build(to string, msg Message) []byte {
+	var b bytes.Buffer
+	b.WriteString("From: " + c.config.From + "\r\n")
+	b.WriteString("To: " + to + "\r\n")
+	b.WriteString("Subject: " + subject(msg) + "\r\n")
+	b.WriteString("Date: " + msg.At.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	body := base64.StdEncoding.EncodeToString([]byte(msg.Text))
+	for len(body) > 76 {
+		b.WriteString(body[:76] + "\r\n")
+		body = body[76:]
+	}
+	b.WriteString(body + "\r\n")
+	return b.Bytes()
+}
+
+func (c EmailChannel) Send(ctx context.Context, dst Destination, msg Message) error {
+	dialer := net.Dialer{Timeout: c.config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.config.Addr)
+	if err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(c.config.Timeout))
+
+	host, _, _ := net.SplitHostPort(c.config.Addr)
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("email: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("email: %w", err)
+		}
+	}
+	if len(c.config.Username) != 0 {
+		if err := client.Auth(smtp.PlainAuth("", c.config.Username, c.config.Password, host)); err != nil {
+			return fmt.Errorf("email: %w", err)
+		}
+	}
+	if err := client.Mail(c.config.From); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	if err := client.Rcpt(dst.Address); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	if _, err := w.Write(c.build(dst.Address, msg)); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	return client.Quit()
+}
+
+// NewEmailChannel создает канал доставки по email
+func NewEmailChannel(config configs.SMTPCfg) EmailChannel {
+	return EmailChannel{
+		config: config,
+	}
+}
@@ -0,0 +1,139 @@
+package channels
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// Kind тип канала доставки уведомлений
+type Kind string
+
+const (
+	KindTelegram Kind = "telegram"
+	KindEmail    Kind = "email"
+	KindWebhook  Kind = "webhook"
+)
+
+// NewKind создает тип канала из строки
+func NewKind(s string) (Kind, error) {
+	switch k := Kind(strings.ToLower(s)); k {
+	case KindTelegram, KindEmail, KindWebhook:
+		return k, nil
+	}
+	return "", fmt.Errorf("channels: unknown channel <%s>", s)
+}
+
+// Channel канал доставки уведомлений
+type Channel interface {
+	Kind() Kind
+	// Send доставляет сообщение адресату, повторные попытки выполняются внутри канала
+	Send(ctx context.Context, dst Destination, msg Message) error
+}
+
+// Message уведомление, подготовленное к отправке в канал
+type Message struct {
+	HubID    int64
+	HubName  string
+	AlertID  string
+	AlertKey string
+	Text     string
+	Severity pkgmodel.Severity
+	// Silent отправить без звука, учитывается только telegram
+	Silent bool
+	At     time.Time
+}
+
+// Destination адресат уведомлений пользователя в канале
+// для telegram адрес - идентификатор чата, в БД хранится только выбор уровней важности с пустым адресом
+type Destination struct {
+	ID      int64  `db:"id"`
+	UserID  int64  `db:"user_id"`
+	Kind    Kind   `db:"kind"`
+	Address string `db:"address"`
+	// Secret ключ подписи HMAC для webhook
+	Secret     string     `db:"secret"`
+	Severities Severities `db:"severities"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+// Severities уровни важности уведомлений, которые получает адресат
+type Severities []pkgmodel.Severity
+
+// AllSeverities значение по умолчанию - все уведомления
+var AllSeverities = Severities{pkgmodel.SeverityLow, pkgmodel.SeverityNormal, pkgmodel.SeverityCritical}
+
+// ParseSeverities разбирает список уровней через запятую, all - все уровни
+func ParseSeverities(s string) (Severities, error) {
+	if strings.EqualFold(s, "all") {
+		return AllSeverities, nil
+	}
+	var result Severities
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			return nil, fmt.Errorf("channels: invalid severities <%s>", s)
+		}
+		sev, err := pkgmodel.NewSeverity(part)
+		if err != nil {
+			return nil, err
+		}
+		if !result.Contains(sev) {
+			result = append(result, sev)
+		}
+	}
+	return result, nil
+}
+
+// Contains проверяет, входит ли уровень в список
+func (s Severities) Contains(sev pkgmodel.Severity) bool {
+	for _, v := range s {
+		if v == sev {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Severities) String() string {
+	parts := make([]string, len(s))
+	for i, v := range s {
+		parts[i] = string(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Value сохраняет уровни в БД строкой через запятую
+func (s Severities) Value() (driver.Value, error) {
+	return s.String(), nil
+}
+
+// Scan читает уровни из строки через запятую
+func (s *Severities) Scan(src any) error {
+	var raw string
+	switch v := src.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	case nil:
+		*s = nil
+		return nil
+	default:
+		return fmt.Errorf("channels: unsupported severities source %T", src)
+	}
+	if len(raw) == 0 {
+		*s = Severities{}
+		return nil
+	}
+	parsed, err := ParseSeverities(raw)
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
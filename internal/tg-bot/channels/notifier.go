@@ -0,0 +1,150 @@
+package channels
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/audit"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/notifications"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+	"github.com/rs/zerolog"
+)
+
+// NotifyPolicy правила доставки уведомлений пользователю
+type NotifyPolicy interface {
+	Check(ctx context.Context, userID int64, event pkgmodel.NotifyEvent) notifications.Decision
+}
+
+// Recipients получатели уведомлений хаба: участники и подписанные чаты
+type Recipients interface {
+	Recipients(ctx context.Context, hubID int64) ([]hubs.Recipient, error)
+}
+
+// AuditLog журнал, в который записывается доставка уведомлений
+type AuditLog interface {
+	Record(ctx context.Context, e audit.Entry)
+}
+
+// Notifier рассылает уведомления хаба получателям с учетом правил и каналов каждого получателя
+type Notifier struct {
+	// recipients участники хаба и подписанные чаты, которым отправляются уведомления
+	recipients Recipients
+	// channelRepo каналы уведомлений получателей
+	channelRepo ChannelRepository
+	// dispatcher отправка уведомлений в каналы
+	dispatcher *Dispatcher
+	// policy правила получателя: часы тишины, отключенные уведомления, уровень важности
+	policy NotifyPolicy
+	// auditLog журнал доставки уведомлений
+	auditLog AuditLog
+	logger   zerolog.Logger
+}
+
+// destinations возвращает настройки telegram и внешние каналы пользователя
+func (n *Notifier) destinations(ctx context.Context, userID int64) (Destination, []Destination) {
+	telegram := Destination{UserID: userID, Kind: KindTelegram, Severities: AllSeverities}
+	dsts, err := n.channelRepo.FindDestinationsByUserID(ctx, userID)
+	if err != nil {
+		n.logger.Error().Err(err).Int64("userID", userID).Msg("notifier: failed to find notification channels")
+		return telegram, nil
+	}
+
+	var external []Destination
+	for _, dst := range dsts {
+		if dst.Kind == KindTelegram {
+			telegram.Severities = dst.Severities
+			continue
+		}
+		external = append(external, dst)
+	}
+	return telegram, external
+}
+
+// Notify отправляет уведомление всем получателям хаба
+// внешние каналы пользователя получают уведомление один раз, даже если он получает его в нескольких чатах
+// для подписанных групп применяются каналы и уровни важности пользователя, добавившего подписку
+func (n *Notifier) Notify(ctx context.Context, hub hubs.Hub, event pkgmodel.NotifyEvent) {
+	recipients, err := n.recipients.Recipients(ctx, hub.ID)
+	if err != nil {
+		n.logger.Error().Err(err).Msg("notifier: failed to get notification recipients")
+		return
+	}
+
+	severity, err := pkgmodel.NewSeverity(string(event.Severity))
+	if err != nil {
+		severity = pkgmodel.SeverityNormal
+	}
+	msg := Message{
+		HubID:    hub.ID,
+		HubName:  hub.Name,
+		AlertID:  event.AlertID,
+		AlertKey: notifications.AlertKey(event),
+		Text:     event.Text,
+		Severity: severity,
+		At:       time.Now(),
+	}
+
+	// telegramByUser настройки telegram уже обработанных пользователей
+	telegramByUser := make(map[int64]Destination)
+	for _, r := range recipients {
+		entry := audit.Entry{
+			Kind:   audit.KindNotification,
+			UserID: r.UserID,
+			ChatID: r.ChatID,
+			HubID:  hub.ID,
+			Result: audit.ResultOK,
+			Text:   event.Text,
+		}
+		decision := n.policy.Check(ctx, r.UserID, event)
+		if !decision.Deliver {
+			n.logger.Debug().Str("alertID", event.AlertID).Int64("chatID", r.ChatID).Msg("notifier: notification muted")
+			entry.Result = audit.ResultMuted
+			n.auditLog.Record(ctx, entry)
+			continue
+		}
+
+		telegram, ok := telegramByUser[r.UserID]
+		if !ok {
+			var external []Destination
+			telegram, external = n.destinations(ctx, r.UserID)
+			telegramByUser[r.UserID] = telegram
+			for _, dst := range external {
+				if dst.Severities.Contains(severity) {
+					n.dispatcher.Dispatch(dst, msg)
+				}
+			}
+		}
+
+		if !telegram.Severities.Contains(severity) {
+			entry.Result = audit.ResultMuted
+			n.auditLog.Record(ctx, entry)
+			continue
+		}
+		n.auditLog.Record(ctx, entry)
+		telegram.Address = strconv.FormatInt(r.ChatID, 10)
+		tgMsg := msg
+		tgMsg.Silent = decision.Silent
+		n.dispatcher.Dispatch(telegram, tgMsg)
+	}
+}
+
+// NewNotifier создает рассылку уведомлений хабов
+func NewNotifier(
+	dispatcher *Dispatcher,
+	channelRepo ChannelRepository,
+	policy NotifyPolicy,
+	recipients Recipients,
+	auditLog AuditLog,
+	logger zerolog.Logger,
+) *Notifier {
+	return &Notifier{
+		recipients:  recipients,
+		channelRepo: channelRepo,
+		dispatcher:  dispatcher,
+		policy:      policy,
+		auditLog:    auditLog,
+		logger:      logger,
+	}
+}
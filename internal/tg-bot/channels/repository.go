@@ -0,0 +1,143 @@
+package channels
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/repository"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// ChannelRepository описывает методы работы с каналами уведомлений пользователя
+type ChannelRepository interface {
+	// FindDestinationsByUserID возвращает каналы пользователя
+	FindDestinationsByUserID(ctx context.Context, userID int64) ([]Destination, error)
+	// FindDestinationsByTGUser возвращает каналы пользователя telegram
+	FindDestinationsByTGUser(ctx context.Context, tgUser string) ([]Destination, error)
+	// FindDestinationByTGUser возвращает канал пользователя по идентификатору
+	FindDestinationByTGUser(ctx context.Context, tgUser string, id int64) (Destination, error)
+	// SaveDestination сохраняет новый канал
+	SaveDestination(ctx context.Context, tgUser string, dst Destination) (Destination, error)
+	// SaveSeverities изменяет уровни важности канала
+	SaveSeverities(ctx context.Context, tgUser string, id int64, severities Severities) error
+	// SaveTelegramSeverities сохраняет уровни важности для чатов telegram
+	SaveTelegramSeverities(ctx context.Context, tgUser string, severities Severities) error
+	// DeleteDestination удаляет канал
+	DeleteDestination(ctx context.Context, tgUser string, id int64) error
+}
+
+type SQLChannelRepo struct {
+	db *sqlx.DB
+}
+
+const selectDestinations = `SELECT c.id, c.user_id, c.kind, c.address, c.secret, c.severities, c.created_at
+FROM notify_channels c
+         JOIN users u ON u.id = c.user_id`
+
+func (r SQLChannelRepo) FindDestinationsByUserID(ctx context.Context, userID int64) ([]Destination, error) {
+	const sqlQuery = selectDestinations + ` WHERE u.id = $1 ORDER BY c.id`
+
+	result := make([]Destination, 0)
+	err := r.db.SelectContext(ctx, &result, sqlQuery, userID)
+	return result, err
+}
+
+func (r SQLChannelRepo) FindDestinationsByTGUser(ctx context.Context, tgUser string) ([]Destination, error) {
+	const sqlQuery = selectDestinations + ` WHERE u.tg_user = $1 ORDER BY c.id`
+
+	result := make([]Destination, 0)
+	err := r.db.SelectContext(ctx, &result, sqlQuery, tgUser)
+	return result, err
+}
+
+func (r SQLChannelRepo) FindDestinationByTGUser(ctx context.Context, tgUser string, id int64) (Destination, error) {
+	const sqlQuery = selectDestinations + ` WHERE u.tg_user = $1 AND c.id = $2`
+
+	dst := Destination{}
+	err := r.db.GetContext(ctx, &dst, sqlQuery, tgUser, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Destination{}, repository.ErrNotFound
+		}
+		return Destination{}, err
+	}
+
+	return dst, nil
+}
+
+func (r SQLChannelRepo) SaveDestination(ctx context.Context, tgUser string, dst Destination) (Destination, error) {
+	const sqlQuery = `INSERT INTO notify_channels(user_id, kind, address, secret, severities)
+		SELECT id, $2, $3, $4, $5 FROM users WHERE tg_user = $1
+		RETURNING id, user_id, created_at`
+
+	row := r.db.QueryRowxContext(ctx, sqlQuery, tgUser, dst.Kind, dst.Address, dst.Secret, dst.Severities)
+	err := row.Scan(&dst.ID, &dst.UserID, &dst.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Destination{}, repository.ErrNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return Destination{}, repository.ErrAlreadyExists
+		}
+		return Destination{}, err
+	}
+
+	return dst, nil
+}
+
+func (r SQLChannelRepo) SaveSeverities(ctx context.Context, tgUser string, id int64, severities Severities) error {
+	const sqlQuery = `UPDATE notify_channels c SET severities = $3
+		FROM users u WHERE c.user_id = u.id AND u.tg_user = $1 AND c.id = $2`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, tgUser, id, severities)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r SQLChannelRepo) SaveTelegramSeverities(ctx context.Context, tgUser string, severities Severities) error {
+	const sqlQuery = `INSERT INTO notify_channels(user_id, kind, address, severities)
+		SELECT id, 'telegram', '', $2 FROM users WHERE tg_user = $1
+		ON CONFLICT (user_id, kind, address) DO UPDATE SET severities = EXCLUDED.severities`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, tgUser, severities)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r SQLChannelRepo) DeleteDestination(ctx context.Context, tgUser string, id int64) error {
+	const sqlQuery = `DELETE FROM notify_channels c USING users u WHERE c.user_id = u.id AND u.tg_user = $1 AND c.id = $2`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, tgUser, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func NewRepo(db *sqlx.DB) SQLChannelRepo {
+	return SQLChannelRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,128 @@
+package channels
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// ChannelService сервис управления каналами уведомлений пользователя
+type ChannelService interface {
+	// FindChannels возвращает каналы пользователя
+	FindChannels(ctx context.Context, tgName string) ([]Destination, error)
+	// AddChannel проверяет адрес и добавляет канал email или webhook, для webhook создается ключ подписи
+	AddChannel(ctx context.Context, tgName string, kind Kind, address string, severities Severities) (Destination, error)
+	// SetSeverities изменяет уровни важности канала
+	SetSeverities(ctx context.Context, tgName string, id int64, severities Severities) error
+	// SetTelegramSeverities изменяет уровни важности уведомлений в telegram
+	SetTelegramSeverities(ctx context.Context, tgName string, severities Severities) error
+	// DeleteChannel удаляет канал
+	DeleteChannel(ctx context.Context, tgName string, id int64) error
+	// TestChannel отправляет в канал тестовое уведомление с текстом text и ждет результата
+	TestChannel(ctx context.Context, tgName string, id int64, text string) error
+}
+
+type ChannelServiceImpl struct {
+	channelRepo ChannelRepository
+	dispatcher  *Dispatcher
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func validateAddress(kind Kind, address string) error {
+	switch kind {
+	case KindEmail:
+		addr, err := mail.ParseAddress(address)
+		if err != nil || addr.Address != address {
+			return fmt.Errorf("channels: invalid email <%s>", address)
+		}
+	case KindWebhook:
+		u, err := url.Parse(address)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return fmt.Errorf("channels: invalid webhook url <%s>", address)
+		}
+	default:
+		return fmt.Errorf("channels: channel <%s> can not be added", kind)
+	}
+	return nil
+}
+
+func (s ChannelServiceImpl) FindChannels(ctx context.Context, tgName string) ([]Destination, error) {
+	return s.channelRepo.FindDestinationsByTGUser(ctx, tgName)
+}
+
+func (s ChannelServiceImpl) AddChannel(ctx context.Context, tgName string, kind Kind, address string, severities Severities) (Destination, error) {
+	if err := validateAddress(kind, address); err != nil {
+		return Destination{}, err
+	}
+	if !s.dispatcher.Enabled(kind) {
+		return Destination{}, ErrChannelDisabled
+	}
+	if len(severities) == 0 {
+		severities = AllSeverities
+	}
+
+	dst := Destination{
+		Kind:       kind,
+		Address:    address,
+		Severities: severities,
+	}
+	if kind == KindWebhook {
+		secret, err := newSecret()
+		if err != nil {
+			return Destination{}, err
+		}
+		dst.Secret = secret
+	}
+
+	return s.channelRepo.SaveDestination(ctx, tgName, dst)
+}
+
+func (s ChannelServiceImpl) SetSeverities(ctx context.Context, tgName string, id int64, severities Severities) error {
+	return s.channelRepo.SaveSeverities(ctx, tgName, id, severities)
+}
+
+func (s ChannelServiceImpl) SetTelegramSeverities(ctx context.Context, tgName string, severities Severities) error {
+	return s.channelRepo.SaveTelegramSeverities(ctx, tgName, severities)
+}
+
+func (s ChannelServiceImpl) DeleteChannel(ctx context.Context, tgName string, id int64) error {
+	return s.channelRepo.DeleteDestination(ctx, tgName, id)
+}
+
+func (s ChannelServiceImpl) TestChannel(ctx context.Context, tgName string, id int64, text string) error {
+	dst, err := s.channelRepo.FindDestinationByTGUser(ctx, tgName, id)
+	if err != nil {
+		return err
+	}
+	if dst.Kind == KindTelegram {
+		return fmt.Errorf("channels: telegram channel can not be tested")
+	}
+	return s.dispatcher.Send(ctx, dst, Message{
+		HubName:  "test",
+		AlertID:  "test",
+		Text:     text,
+		Severity: pkgmodel.SeverityNormal,
+		At:       time.Now(),
+	})
+}
+
+// NewChannelService создает сервис каналов уведомлений
+func NewChannelService(channelRepo ChannelRepository, dispatcher *Dispatcher) ChannelServiceImpl {
+	return ChannelServiceImpl{
+		channelRepo: channelRepo,
+		dispatcher:  dispatcher,
+	}
+}
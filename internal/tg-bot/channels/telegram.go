@@ -0,0 +1,42 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
+)
+
+// TelegramChannel отправляет уведомления в очередь сообщений бота
+type TelegramChannel struct {
+	botNotify chan<- model.Notification
+}
+
+func (c TelegramChannel) Kind() Kind {
+	return KindTelegram
+}
+
+func (c TelegramChannel) Send(ctx context.Context, dst Destination, msg Message) error {
+	chatID, err := strconv.ParseInt(dst.Address, 10, 64)
+	if err != nil {
+		return fmt.Errorf("telegram channel: invalid chat id <%s>", dst.Address)
+	}
+	n := model.NewNotification(chatID, msg.Text)
+	n.AlertKey = msg.AlertKey
+	n.Silent = msg.Silent
+
+	select {
+	case c.botNotify <- n:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NewTelegramChannel создает канал доставки в telegram
+func NewTelegramChannel(botNotify chan<- model.Notification) TelegramChannel {
+	return TelegramChannel{
+		botNotify: botNotify,
+	}
+}
@@ -0,0 +1,149 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/configs"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// HeaderSignature подпись тела запроса: sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+	HeaderSignature = "X-RC-Signature"
+	// HeaderTimestamp время отправки, unix секунды, входит в подпись для защиты от повтора
+	HeaderTimestamp = "X-RC-Timestamp"
+	// HeaderDelivery идентификатор доставки, одинаковый для всех повторов одного сообщения
+	HeaderDelivery = "X-RC-Delivery"
+)
+
+// errPermanent ошибка, после которой повтор не имеет смысла
+var errPermanent = errors.New("webhook: permanent error")
+
+// WebhookPayload тело запроса webhook
+type WebhookPayload struct {
+	DeliveryID string    `json:"delivery_id"`
+	Hub        string    `json:"hub"`
+	AlertID    string    `json:"alert_id,omitempty"`
+	Text       string    `json:"text"`
+	Severity   string    `json:"severity"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// WebhookChannel отправляет уведомления POST запросом с подписью HMAC
+type WebhookChannel struct {
+	client *http.Client
+	config configs.WebhookCfg
+	logger zerolog.Logger
+}
+
+// Sign вычисляет подпись тела запроса
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (c WebhookChannel) Kind() Kind {
+	return KindWebhook
+}
+
+func (c WebhookChannel) backoff(attempts int) time.Duration {
+	d := c.config.BackoffMin
+	for i := 1; i < attempts && d < c.config.BackoffMax; i++ {
+		d *= 2
+	}
+	if d > c.config.BackoffMax {
+		d = c.config.BackoffMax
+	}
+	return d
+}
+
+// post выполняет одну попытку, для ответа 429 возвращает задержку из Retry-After
+func (c WebhookChannel) post(ctx context.Context, dst Destination, deliveryID string, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dst.Address, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("%w, %v", errPermanent, err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderSignature, Sign(dst.Secret, timestamp, body))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode < http.StatusMultipleChoices:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return time.Duration(seconds) * time.Second, fmt.Errorf("webhook: status %d", resp.StatusCode)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return 0, fmt.Errorf("webhook: status %d", resp.StatusCode)
+	}
+	return 0, fmt.Errorf("%w, status %d", errPermanent, resp.StatusCode)
+}
+
+func (c WebhookChannel) Send(ctx context.Context, dst Destination, msg Message) error {
+	payload := WebhookPayload{
+		DeliveryID: uuid.NewString(),
+		Hub:        msg.HubName,
+		AlertID:    msg.AlertID,
+		Text:       msg.Text,
+		Severity:   string(msg.Severity),
+		Timestamp:  msg.At,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		retryAfter, err := c.post(ctx, dst, payload.DeliveryID, body)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, errPermanent) || attempt >= c.config.MaxAttempts {
+			return fmt.Errorf("webhook: delivery failed after %d attempts, %w", attempt, err)
+		}
+
+		delay := c.backoff(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		c.logger.Warn().Err(err).Int64("channelID", dst.ID).Msgf("webhook: failed to deliver, retry in %s", delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// NewWebhookChannel создает канал доставки http webhook
+func NewWebhookChannel(config configs.WebhookCfg, logger zerolog.Logger) WebhookChannel {
+	return WebhookChannel{
+		client: &http.Client{Timeout: config.Timeout},
+		config: config,
+		logger: logger,
+	}
+}
@@ -1,6 +1,8 @@
 package configs
 
 import (
+	"time"
+
 	"github.com/c0dered273/automation-remote-controller/pkg/configs"
 	"github.com/c0dered273/automation-remote-controller/pkg/validators"
 	"github.com/rs/zerolog"
@@ -13,13 +15,17 @@ var (
 
 	// Переменные окружения
 	// PORT - порт на котором необходимо поднять gRPC сервер
+	// HTTP_PORT - порт http сервера входящих webhook
 	// BOT_TOKEN - токен бота telegram
 	// CA_CERT - путь к корневому сертификату
 	// SERVER_CERT - путь к сертификату сервера
 	// SERVER_PKey - путь к приватному ключу сервера
 	// DATABASE_URI- строка соединения с БД
+	// SMTP_PASSWORD - пароль SMTP сервера
+	// INTERNAL_TOKEN - общий секрет внутреннего API для user-account-api
 	envVars = []string{
 		"PORT",
+		"HTTP_PORT",
 		"BOT_TOKEN",
 		"CA_CERT",
 		"SERVER_CERT",
@@ -30,18 +36,137 @@ var (
 
 // TGBotCfg настройки бота
 type TGBotCfg struct {
-	Name           string `mapstructure:"name"`
-	Port           string `mapstructure:"port"`
-	BotToken       string `mapstructure:"bot_token" validate:"required"`
-	CACert         string `mapstructure:"ca_cert" validate:"required"`
-	ServerCert     string `mapstructure:"server_cert" validate:"required"`
-	ServerPkey     string `mapstructure:"server_pkey" validate:"required"`
-	DatabaseUri    string `mapstructure:"database_uri" validate:"required"`
+	Name           string       `mapstructure:"name"`
+	Port           string       `mapstructure:"port"`
+	HTTPPort       string       `mapstructure:"http_port"`
+	BotToken       string       `mapstructure:"bot_token" validate:"required"`
+	CACert         string       `mapstructure:"ca_cert" validate:"required"`
+	ServerCert     string       `mapstructure:"server_cert" validate:"required"`
+	ServerPkey     string       `mapstructure:"server_pkey" validate:"required"`
+	DatabaseUri    string       `mapstructure:"database_uri" validate:"required"`
+	Scheduler      SchedulerCfg `mapstructure:"scheduler"`
+	Sender         SenderCfg    `mapstructure:"sender"`
+	Audit          AuditCfg     `mapstructure:"audit"`
+	Rules          RulesCfg     `mapstructure:"rules"`
+	SMTP           SMTPCfg      `mapstructure:"smtp"`
+	Webhook        WebhookCfg   `mapstructure:"webhook"`
+	Internal       InternalCfg  `mapstructure:"internal"`
+	Live           LiveCfg      `mapstructure:"live"`
+	WebApp         WebAppCfg    `mapstructure:"webapp"`
 	configs.Logger `mapstructure:"logger"`
 }
 
+// SchedulerCfg настройки планировщика команд
+type SchedulerCfg struct {
+	// Interval период проверки наступивших расписаний
+	Interval time.Duration `mapstructure:"interval" validate:"required"`
+	// MisfireGrace запуск, опоздавший больше чем на это время, считается пропущенным
+	MisfireGrace time.Duration `mapstructure:"misfire_grace" validate:"required"`
+}
+
+// SenderCfg настройки очереди отправки сообщений в telegram
+type SenderCfg struct {
+	// GlobalRate общее ограничение, сообщений в секунду
+	GlobalRate float64 `mapstructure:"global_rate" validate:"gt=0"`
+	// ChatRate ограничение для одного чата, сообщений в секунду
+	ChatRate float64 `mapstructure:"chat_rate" validate:"gt=0"`
+	// MaxAttempts количество попыток отправки при временных ошибках
+	MaxAttempts int `mapstructure:"max_attempts" validate:"gt=0"`
+	// BackoffMin, BackoffMax границы экспоненциальной задержки между попытками
+	BackoffMin time.Duration `mapstructure:"backoff_min" validate:"required"`
+	BackoffMax time.Duration `mapstructure:"backoff_max" validate:"required"`
+}
+
+// AuditCfg настройки журнала команд и уведомлений
+type AuditCfg struct {
+	// Retention срок хранения записей журнала
+	Retention time.Duration `mapstructure:"retention" validate:"required"`
+	// CleanupInterval период удаления устаревших записей
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval" validate:"required"`
+}
+
+// RulesCfg настройки движка правил автоматизации
+type RulesCfg struct {
+	// QueueSize размер очереди событий хабов
+	QueueSize int `mapstructure:"queue_size" validate:"gt=0"`
+	// LoopWindow, LoopMaxFires правило, сработавшее больше LoopMaxFires раз за LoopWindow, отключается
+	LoopWindow   time.Duration `mapstructure:"loop_window" validate:"required"`
+	LoopMaxFires int           `mapstructure:"loop_max_fires" validate:"gt=0"`
+}
+
+// SMTPCfg настройки отправки уведомлений по email, если Addr не задан, канал email отключен
+type SMTPCfg struct {
+	// Addr адрес SMTP сервера host:port
+	Addr     string `mapstructure:"addr" validate:"omitempty,hostname_port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// From адрес отправителя
+	From    string        `mapstructure:"from" validate:"required_with=Addr,omitempty,email"`
+	Timeout time.Duration `mapstructure:"timeout" validate:"required"`
+}
+
+// WebhookCfg настройки отправки уведомлений на http webhook
+type WebhookCfg struct {
+	// Timeout ограничение времени одного запроса
+	Timeout time.Duration `mapstructure:"timeout" validate:"required"`
+	// MaxAttempts количество попыток при сетевых ошибках и ответах 5xx и 429
+	MaxAttempts int `mapstructure:"max_attempts" validate:"gt=0"`
+	// BackoffMin, BackoffMax границы экспоненциальной задержки между попытками
+	BackoffMin time.Duration `mapstructure:"backoff_min" validate:"required"`
+	BackoffMax time.Duration `mapstructure:"backoff_max" validate:"required"`
+}
+
+// InternalCfg настройки внутреннего API управления устройствами, если Token не задан, API отключено
+type InternalCfg struct {
+	// Token общий секрет, передается в заголовке X-Internal-Token
+	Token string `mapstructure:"token" validate:"omitempty,min=16"`
+	// AckTimeout время ожидания ответа хаба на команду или запрос состояния
+	AckTimeout time.Duration `mapstructure:"ack_timeout" validate:"required"`
+}
+
+// LiveCfg настройки потока событий для панелей мониторинга
+type LiveCfg struct {
+	// BufferSize количество последних событий, доступных для продолжения потока после переподключения
+	BufferSize int `mapstructure:"buffer_size" validate:"gt=0"`
+	// SubscriberBuffer очередь событий подписчика, при переполнении подписчик отключается
+	SubscriberBuffer int `mapstructure:"subscriber_buffer" validate:"gt=0"`
+	// Heartbeat период отправки события ping
+	Heartbeat time.Duration `mapstructure:"heartbeat" validate:"required"`
+}
+
+// WebAppCfg настройки Telegram Mini App, если URL не задан, приложение отключено
+type WebAppCfg struct {
+	// URL публичный https адрес страницы /webapp/ http сервера бота
+	URL string `mapstructure:"url" validate:"omitempty,url,startswith=https://"`
+	// InitDataTTL срок действия данных запуска приложения
+	InitDataTTL time.Duration `mapstructure:"init_data_ttl" validate:"required"`
+}
+
 func setDefaults() {
 	viper.SetDefault("port", "8080")
+	viper.SetDefault("http_port", "8082")
+	viper.SetDefault("scheduler.interval", 10*time.Second)
+	viper.SetDefault("scheduler.misfire_grace", time.Minute)
+	viper.SetDefault("sender.global_rate", 25)
+	viper.SetDefault("sender.chat_rate", 1)
+	viper.SetDefault("sender.max_attempts", 5)
+	viper.SetDefault("sender.backoff_min", time.Second)
+	viper.SetDefault("sender.backoff_max", time.Minute)
+	viper.SetDefault("audit.retention", 90*24*time.Hour)
+	viper.SetDefault("audit.cleanup_interval", time.Hour)
+	viper.SetDefault("rules.queue_size", 256)
+	viper.SetDefault("rules.loop_window", time.Minute)
+	viper.SetDefault("rules.loop_max_fires", 10)
+	viper.SetDefault("smtp.timeout", 10*time.Second)
+	viper.SetDefault("webhook.timeout", 10*time.Second)
+	viper.SetDefault("webhook.max_attempts", 5)
+	viper.SetDefault("webhook.backoff_min", time.Second)
+	viper.SetDefault("webhook.backoff_max", time.Minute)
+	viper.SetDefault("internal.ack_timeout", 5*time.Second)
+	viper.SetDefault("live.buffer_size", 1024)
+	viper.SetDefault("live.subscriber_buffer", 64)
+	viper.SetDefault("live.heartbeat", 15*time.Second)
+	viper.SetDefault("webapp.init_data_ttl", 24*time.Hour)
 	viper.SetDefault("logger.level", "info")
 	viper.SetDefault("logger.format", "pretty")
 }
@@ -84,7 +209,11 @@ func bindEnvVars() error {
 			return err
 		}
 	}
-	return nil
+	err := viper.BindEnv("smtp.password", "SMTP_PASSWORD")
+	if err != nil {
+		return err
+	}
+	return viper.BindEnv("internal.token", "INTERNAL_TOKEN")
 }
 
 func newConfig() (*TGBotCfg, error) {
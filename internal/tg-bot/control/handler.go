@@ -0,0 +1,112 @@
+package control
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/devices"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/repository"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+	"github.com/labstack/echo/v4"
+)
+
+// InternalAuth middleware проверяет общий секрет внутреннего API из заголовка X-Internal-Token
+func InternalAuth(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			got := c.Request().Header.Get(pkgmodel.InternalTokenHeader)
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid internal token")
+			}
+			return next(c)
+		}
+	}
+}
+
+// UserFunc возвращает пользователя telegram, от имени которого выполняется запрос
+type UserFunc func(c echo.Context) string
+
+// ParamUser пользователь из параметра пути :tgUser внутреннего API
+func ParamUser(c echo.Context) string {
+	return c.Param("tgUser")
+}
+
+// ToHTTPError сопоставляет ошибки управления устройствами с кодами ответа
+func ToHTTPError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, hubs.ErrForbidden), errors.Is(err, devices.ErrDeviceForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	case errors.Is(err, repository.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Hub not found")
+	case errors.Is(err, devices.ErrPINLocked):
+		return echo.NewHTTPError(http.StatusTooManyRequests, "PIN entry is locked")
+	case errors.Is(err, devices.ErrWrongPIN):
+		return echo.NewHTTPError(http.StatusForbidden, "Wrong PIN")
+	case errors.Is(err, devices.ErrConfirmRequired):
+		return echo.NewHTTPError(http.StatusPreconditionRequired, "Action must be confirmed")
+	case errors.Is(err, devices.ErrPINRequired):
+		return echo.NewHTTPError(http.StatusPreconditionRequired, "PIN is required")
+	case errors.Is(err, ErrUnknownAction):
+		return echo.NewHTTPError(http.StatusBadRequest, "Unknown action")
+	case errors.Is(err, devices.ErrValueRequired):
+		return echo.NewHTTPError(http.StatusBadRequest, "Value is required")
+	case errors.Is(err, devices.ErrHubOffline):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "Hub is offline")
+	case errors.Is(err, devices.ErrNoAck), errors.Is(err, ErrNoReply):
+		return echo.NewHTTPError(http.StatusGatewayTimeout, "Hub did not reply in time")
+	case errors.Is(err, devices.ErrActionFailed):
+		return echo.NewHTTPError(http.StatusBadGateway, err.Error())
+	}
+	c.Logger().Error(err)
+	return echo.ErrInternalServerError
+}
+
+// FindHubs GET /internal/users/:tgUser/hubs - хабы пользователя telegram
+func FindHubs(service ControlService, user UserFunc) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		result, err := service.FindHubs(c.Request().Context(), user(c))
+		if err != nil {
+			return ToHTTPError(c, err)
+		}
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// ReadState GET /internal/users/:tgUser/hubs/:hubName/devices - состояние устройств хаба
+// параметр device_id может повторяться, без него возвращаются все устройства
+func ReadState(service ControlService, user UserFunc) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		state, err := service.ReadState(
+			c.Request().Context(), user(c), c.Param("hubName"), c.QueryParams()["device_id"],
+		)
+		if err != nil {
+			return ToHTTPError(c, err)
+		}
+		return c.JSON(http.StatusOK, state)
+	}
+}
+
+// Execute POST /internal/users/:tgUser/hubs/:hubName/devices/:deviceID/actions - команда устройству
+// ответ отправляется после подтверждения выполнения хабом
+func Execute(service ControlService, user UserFunc, source devices.Source) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		req := pkgmodel.CommandRequest{}
+		if err := c.Bind(&req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		if err := c.Validate(req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		ack, err := service.Execute(c.Request().Context(), user(c), c.Param("hubName"), c.Param("deviceID"), source, req)
+		if err != nil {
+			return ToHTTPError(c, err)
+		}
+		return c.JSON(http.StatusOK, ack)
+	}
+}
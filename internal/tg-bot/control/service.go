@@ -0,0 +1,120 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/devices"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
+	"github.com/c0dered273/automation-remote-controller/pkg/collections"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+var (
+	ErrUnknownAction = errors.New("control: unknown action")
+	ErrNoReply       = errors.New("control: hub did not reply in time")
+)
+
+// ControlService управление устройствами хабов через внутреннее API
+type ControlService interface {
+	// FindHubs хабы пользователя с признаком подключения клиентского приложения
+	FindHubs(ctx context.Context, tgName string) ([]pkgmodel.HubStatus, error)
+	// ReadState запрашивает у хаба состояние устройств, пустой список означает все устройства хаба
+	ReadState(ctx context.Context, tgName string, hubName string, deviceIDs []string) (pkgmodel.StateEvent, error)
+	// Execute отправляет команду устройству с учетом его правил и ожидает подтверждение хаба
+	Execute(
+		ctx context.Context, tgName string, hubName string, deviceID string, source devices.Source, cmd pkgmodel.CommandRequest,
+	) (pkgmodel.AckEvent, error)
+}
+
+type ControlServiceImpl struct {
+	hubService    hubs.HubService
+	policyService devices.PolicyService
+	clients       *collections.ConcurrentMap[string, *model.ClientEvents]
+	ackTimeout    time.Duration
+}
+
+func (s ControlServiceImpl) FindHubs(ctx context.Context, tgName string) ([]pkgmodel.HubStatus, error) {
+	access, err := s.hubService.FindHubs(ctx, tgName, pkgmodel.ViewStatus)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]pkgmodel.HubStatus, 0, len(access))
+	for _, a := range access {
+		_, online := s.clients.Get(a.Name)
+		result = append(result, pkgmodel.HubStatus{
+			Name:   a.Name,
+			Role:   a.Role,
+			Online: online,
+		})
+	}
+	return result, nil
+}
+
+func (s ControlServiceImpl) ReadState(ctx context.Context, tgName string, hubName string, deviceIDs []string) (pkgmodel.StateEvent, error) {
+	hub, err := s.hubService.Authorize(ctx, tgName, hubName, pkgmodel.ViewStatus)
+	if err != nil {
+		return pkgmodel.StateEvent{}, err
+	}
+	client, ok := s.clients.Get(hub.Name)
+	if !ok {
+		return pkgmodel.StateEvent{}, devices.ErrHubOffline
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, s.ackTimeout)
+	defer cancel()
+	state, err := client.ReadState(waitCtx, pkgmodel.StateRequest{DeviceIDs: deviceIDs})
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return state, ErrNoReply
+	case errors.Is(err, model.ErrClientClosed):
+		return state, devices.ErrHubOffline
+	}
+	return state, err
+}
+
+func (s ControlServiceImpl) Execute(
+	ctx context.Context,
+	tgName string,
+	hubName string,
+	deviceID string,
+	source devices.Source,
+	cmd pkgmodel.CommandRequest,
+) (pkgmodel.AckEvent, error) {
+	action, err := pkgmodel.NewAction(cmd.Action)
+	if err != nil || action == pkgmodel.Empty {
+		return pkgmodel.AckEvent{}, ErrUnknownAction
+	}
+	hub, err := s.hubService.Authorize(ctx, tgName, hubName, pkgmodel.SendActions)
+	if err != nil {
+		return pkgmodel.AckEvent{}, err
+	}
+
+	return s.policyService.ExecuteWait(ctx, devices.ActionRequest{
+		TGUser:    tgName,
+		HubID:     hub.ID,
+		DeviceID:  deviceID,
+		Action:    action,
+		Source:    source,
+		Confirmed: cmd.Confirmed,
+		PIN:       cmd.PIN,
+		Value:     cmd.Value,
+	}, s.ackTimeout)
+}
+
+// NewControlService создает сервис управления устройствами через внутреннее API
+func NewControlService(
+	hubService hubs.HubService,
+	policyService devices.PolicyService,
+	clients *collections.ConcurrentMap[string, *model.ClientEvents],
+	ackTimeout time.Duration,
+) ControlServiceImpl {
+	return ControlServiceImpl{
+		hubService:    hubService,
+		policyService: policyService,
+		clients:       clients,
+		ackTimeout:    ackTimeout,
+	}
+}
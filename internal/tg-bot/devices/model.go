@@ -0,0 +1,97 @@
+package devices
+
+import (
+	"strconv"
+	"time"
+
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// Source источник команды устройству
+type Source string
+
+const (
+	// SourceBot команда пользователя из меню бота
+	SourceBot Source = "bot"
+	// SourceScene шаг сценария
+	SourceScene Source = "scene"
+	// SourceSchedule команда по расписанию
+	SourceSchedule Source = "schedule"
+	// SourceRule действие правила автоматизации
+	SourceRule Source = "rule"
+	// SourceAPI команда пользователя через HTTP API
+	SourceAPI Source = "api"
+	// SourceWebApp команда пользователя из Telegram Mini App
+	SourceWebApp Source = "webapp"
+)
+
+// Interactive проверяет, может ли источник передать подтверждение или PIN-код пользователя
+func (s Source) Interactive() bool {
+	return s == SourceBot || s == SourceAPI || s == SourceWebApp
+}
+
+// Policy правила управления устройством хаба
+type Policy struct {
+	HubID    int64  `db:"client_id"`
+	DeviceID string `db:"device_id"`
+	// MinRole минимальная роль участника хаба, которой разрешено управлять устройством
+	MinRole pkgmodel.Role `db:"min_role"`
+	// Confirm команда выполняется только после подтверждения пользователем
+	Confirm bool `db:"confirm"`
+	// HasPIN перед выполнением команды нужно ввести PIN-код
+	HasPIN bool `db:"has_pin"`
+}
+
+// Interactive проверяет, требует ли устройство участия пользователя при выполнении команды
+func (p Policy) Interactive() bool {
+	return p.Confirm || p.HasPIN
+}
+
+// DefaultPolicy правила устройства, для которого ограничения не заданы
+func DefaultPolicy(hubID int64, deviceID string) Policy {
+	return Policy{
+		HubID:    hubID,
+		DeviceID: deviceID,
+		MinRole:  pkgmodel.RoleOperator,
+	}
+}
+
+// ActionRequest запрос на выполнение команды устройством
+type ActionRequest struct {
+	TGUser string
+	// ChatID чат, из которого отправлена команда, для команд по расписанию и сценариев не заполняется
+	ChatID   int64
+	HubID    int64
+	DeviceID string
+	Action   pkgmodel.Action
+	Source   Source
+	// Confirmed пользователь подтвердил выполнение команды
+	Confirmed bool
+	// PIN введенный пользователем PIN-код устройства
+	PIN string
+	// Value значение для команды SetValue
+	Value *float64
+}
+
+// ActionText название команды вместе со значением, например SetValue=21.5
+func (r ActionRequest) ActionText() string {
+	if r.Value == nil {
+		return r.Action.String()
+	}
+	return r.Action.String() + "=" + pkgmodel.FormatValue(*r.Value)
+}
+
+// pending команда, ожидающая подтверждения или ввода PIN-кода
+type pending struct {
+	// id идентификатор команды, передается в кнопке подтверждения
+	id  string
+	req ActionRequest
+	// needPIN команда ожидает ввода PIN-кода, а не нажатия кнопки подтверждения
+	needPIN   bool
+	expiresAt time.Time
+}
+
+// pendingKey ожидающие команды хранятся отдельно для каждого чата пользователя
+func pendingKey(tgName string, chatID int64) string {
+	return tgName + ":" + strconv.FormatInt(chatID, 10)
+}
@@ -0,0 +1,101 @@
+package devices
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// pinMaxAttempts количество неверных PIN-кодов подряд, после которого ввод блокируется
+	pinMaxAttempts = 5
+	// pinLockout блокировка после pinMaxAttempts ошибок, каждая следующая ошибка удваивает блокировку до pinMaxLockout
+	pinLockout    = time.Minute
+	pinMaxLockout = time.Hour
+	// pinFailureTTL счетчик ошибок сбрасывается, если неверный PIN-код не вводился дольше этого времени
+	pinFailureTTL = 24 * time.Hour
+)
+
+// pinKey ошибки ввода PIN-кода считаются отдельно для каждого пользователя и устройства хаба
+type pinKey struct {
+	tgUser   string
+	hubID    int64
+	deviceID string
+}
+
+type pinFailures struct {
+	count       int
+	last        time.Time
+	lockedUntil time.Time
+}
+
+// pinGuard ограничивает подбор PIN-кода: после нескольких ошибок подряд ввод PIN-кода устройства
+// временно блокируется для пользователя, время блокировки растет с каждой следующей ошибкой
+type pinGuard struct {
+	mu       sync.Mutex
+	failures map[pinKey]*pinFailures
+}
+
+func newPINKey(req ActionRequest) pinKey {
+	return pinKey{
+		tgUser:   req.TGUser,
+		hubID:    req.HubID,
+		deviceID: strings.ToLower(req.DeviceID),
+	}
+}
+
+// locked возвращает время окончания блокировки ввода PIN-кода, если она действует
+func (g *pinGuard) locked(key pinKey, now time.Time) (time.Time, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	f, ok := g.failures[key]
+	if !ok || !now.Before(f.lockedUntil) {
+		return time.Time{}, false
+	}
+	return f.lockedUntil, true
+}
+
+// fail учитывает неверный PIN-код и возвращает время окончания блокировки, если ошибка привела к блокировке
+func (g *pinGuard) fail(key pinKey, now time.Time) (time.Time, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.prune(now)
+	f, ok := g.failures[key]
+	if !ok {
+		f = &pinFailures{}
+		g.failures[key] = f
+	}
+	f.count++
+	f.last = now
+	if f.count < pinMaxAttempts {
+		return time.Time{}, false
+	}
+	lockout := pinMaxLockout
+	if shift := f.count - pinMaxAttempts; shift < 16 && pinLockout<<shift < pinMaxLockout {
+		lockout = pinLockout << shift
+	}
+	f.lockedUntil = now.Add(lockout)
+	return f.lockedUntil, true
+}
+
+// reset сбрасывает счетчик ошибок после верного PIN-кода
+func (g *pinGuard) reset(key pinKey) {
+	g.mu.Lock()
+	delete(g.failures, key)
+	g.mu.Unlock()
+}
+
+// prune удаляет устаревшие счетчики, вызывается под блокировкой
+func (g *pinGuard) prune(now time.Time) {
+	for key, f := range g.failures {
+		if now.Sub(f.last) > pinFailureTTL && !now.Before(f.lockedUntil) {
+			delete(g.failures, key)
+		}
+	}
+}
+
+func newPINGuard() *pinGuard {
+	return &pinGuard{
+		failures: make(map[pinKey]*pinFailures),
+	}
+}
@@ -0,0 +1,80 @@
+package devices
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPINGuard(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	key := newPINKey(ActionRequest{TGUser: "user", HubID: 1, DeviceID: "Pump001"})
+
+	tests := []struct {
+		name        string
+		at          time.Duration
+		fail        bool
+		reset       bool
+		wantLocked  bool
+		wantLockout time.Duration
+	}{
+		{name: "first failure", at: 0, fail: true},
+		{name: "second failure", at: time.Second, fail: true},
+		{name: "third failure", at: 2 * time.Second, fail: true},
+		{name: "fourth failure", at: 3 * time.Second, fail: true},
+		{name: "fifth failure locks", at: 4 * time.Second, fail: true, wantLocked: true, wantLockout: pinLockout},
+		{name: "locked before lockout ends", at: 30 * time.Second, wantLocked: true},
+		{name: "unlocked after lockout", at: 4*time.Second + pinLockout},
+		{name: "next failure doubles lockout", at: 2 * time.Minute, fail: true, wantLocked: true, wantLockout: 2 * pinLockout},
+		{name: "success resets counter", at: 10 * time.Minute, reset: true},
+		{name: "failure after reset", at: 11 * time.Minute, fail: true},
+	}
+
+	g := newPINGuard()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := now.Add(tt.at)
+			switch {
+			case tt.reset:
+				g.reset(key)
+			case tt.fail:
+				until, locked := g.fail(key, at)
+				if locked != tt.wantLocked {
+					t.Fatalf("fail() locked = %v, want %v", locked, tt.wantLocked)
+				}
+				if locked && until.Sub(at) != tt.wantLockout {
+					t.Fatalf("fail() lockout = %v, want %v", until.Sub(at), tt.wantLockout)
+				}
+				return
+			}
+			if _, locked := g.locked(key, at); locked != tt.wantLocked {
+				t.Fatalf("locked() = %v, want %v", locked, tt.wantLocked)
+			}
+		})
+	}
+}
+
+func TestPINGuardKey(t *testing.T) {
+	g := newPINGuard()
+	now := time.Now()
+	for i := 0; i < pinMaxAttempts; i++ {
+		g.fail(newPINKey(ActionRequest{TGUser: "user", HubID: 1, DeviceID: "PUMP001"}), now)
+	}
+
+	tests := []struct {
+		name string
+		req  ActionRequest
+		want bool
+	}{
+		{name: "same device in other case", req: ActionRequest{TGUser: "user", HubID: 1, DeviceID: "pump001"}, want: true},
+		{name: "other device", req: ActionRequest{TGUser: "user", HubID: 1, DeviceID: "Pump002"}},
+		{name: "other hub", req: ActionRequest{TGUser: "user", HubID: 2, DeviceID: "Pump001"}},
+		{name: "other user", req: ActionRequest{TGUser: "other", HubID: 1, DeviceID: "Pump001"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, locked := g.locked(newPINKey(tt.req), now); locked != tt.want {
+				t.Errorf("locked() = %v, want %v", locked, tt.want)
+			}
+		})
+	}
+}
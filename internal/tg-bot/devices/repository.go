@@ -0,0 +1,63 @@
+package devices
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+// PolicyRepository описывает методы чтения правил управления устройствами
+// идентификаторы устройств сравниваются без учета регистра, как и в клиентском приложении
+type PolicyRepository interface {
+	// FindPolicy возвращает правила управления устройством хаба
+	FindPolicy(ctx context.Context, hubID int64, deviceID string) (Policy, error)
+	// CheckPIN сверяет PIN-код устройства с сохраненным хешем
+	CheckPIN(ctx context.Context, hubID int64, deviceID string, pin string) (bool, error)
+}
+
+type SQLPolicyRepo struct {
+	db *sqlx.DB
+}
+
+func (r SQLPolicyRepo) FindPolicy(ctx context.Context, hubID int64, deviceID string) (Policy, error) {
+	const sqlQuery = `SELECT client_id, device_id, min_role, confirm, pin IS NOT NULL AS has_pin
+		FROM device_policies
+		WHERE client_id = $1 AND device_id = lower($2)`
+
+	policy := Policy{}
+	err := r.db.GetContext(ctx, &policy, sqlQuery, hubID, deviceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Policy{}, repository.ErrNotFound
+		}
+		return Policy{}, err
+	}
+
+	return policy, nil
+}
+
+func (r SQLPolicyRepo) CheckPIN(ctx context.Context, hubID int64, deviceID string, pin string) (bool, error) {
+	const sqlQuery = `SELECT pin = crypt($3, pin)
+		FROM device_policies
+		WHERE client_id = $1 AND device_id = lower($2) AND pin IS NOT NULL`
+
+	var ok bool
+	err := r.db.GetContext(ctx, &ok, sqlQuery, hubID, deviceID, pin)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return ok, nil
+}
+
+func NewRepo(db *sqlx.DB) SQLPolicyRepo {
+	return SQLPolicyRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,301 @@
+package devices
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/audit"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/repository"
+	"github.com/c0dered273/automation-remote-controller/pkg/collections"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+	"github.com/rs/zerolog"
+)
+
+// ConfirmTTL время, в течение которого команда ожидает подтверждения или ввода PIN-кода
+const ConfirmTTL = 2 * time.Minute
+
+var (
+	ErrDeviceForbidden = errors.New("devices: role is not allowed to operate device")
+	ErrConfirmRequired = errors.New("devices: action must be confirmed")
+	ErrPINRequired     = errors.New("devices: PIN is required")
+	ErrWrongPIN        = errors.New("devices: wrong PIN")
+	ErrPINLocked       = errors.New("devices: too many wrong PIN attempts, PIN entry is locked")
+	ErrInteractiveOnly = errors.New("devices: device can only be operated from the bot menu")
+	ErrHubOffline      = errors.New("devices: hub is offline")
+	ErrNoAck           = errors.New("devices: hub did not acknowledge action in time")
+	ErrActionFailed    = errors.New("devices: hub failed to execute action")
+	ErrValueRequired   = errors.New("devices: action requires a value")
+)
+
+// PolicyService проверяет правила управления устройствами и отправляет команды на хаб
+type PolicyService interface {
+	// Execute проверяет правила устройства, отправляет команду на хаб и записывает результат в журнал
+	Execute(ctx context.Context, req ActionRequest) error
+	// ExecuteWait то же, что Execute, но дополнительно ожидает подтверждение выполнения команды хабом
+	// если подтверждение не получено за время timeout, возвращается ErrNoAck
+	ExecuteWait(ctx context.Context, req ActionRequest, timeout time.Duration) (pkgmodel.AckEvent, error)
+	// Check проверяет, что пользователю разрешено выполнить команду, и возвращает хаб устройства
+	// ErrConfirmRequired и ErrPINRequired означают, что команду нужно повторить после подтверждения пользователем
+	Check(ctx context.Context, req ActionRequest) (hubs.Hub, error)
+	// Hold сохраняет команду до подтверждения пользователем и возвращает ее идентификатор для кнопки подтверждения,
+	// в каждом чате у пользователя может быть только одна такая команда, needPIN - команда ожидает ввода PIN-кода
+	Hold(req ActionRequest, needPIN bool) (string, error)
+	// Take возвращает и удаляет ожидающую команду пользователя в чате, если ее идентификатор совпадает с id,
+	// пустой id выбирает команду, ожидающую ввода PIN-кода
+	Take(tgName string, chatID int64, id string) (ActionRequest, bool)
+}
+
+type PolicyServiceImpl struct {
+	policyRepo   PolicyRepository
+	hubService   hubs.HubService
+	auditService audit.AuditService
+	clients      *collections.ConcurrentMap[string, *model.ClientEvents]
+	pending      map[string]pending
+	pendingMu    *sync.Mutex
+	// pins ограничение подбора PIN-кодов устройств
+	pins *pinGuard
+	// events получатель результатов команд, отправленных на хаб
+	events model.EventPublisher
+	logger zerolog.Logger
+}
+
+func (s PolicyServiceImpl) Execute(ctx context.Context, req ActionRequest) error {
+	_, err := s.execute(ctx, req, 0)
+	return err
+}
+
+func (s PolicyServiceImpl) ExecuteWait(ctx context.Context, req ActionRequest, timeout time.Duration) (pkgmodel.AckEvent, error) {
+	return s.execute(ctx, req, timeout)
+}
+
+// execute выполняет команду, при нулевом timeout подтверждение хаба не ожидается
+func (s PolicyServiceImpl) execute(ctx context.Context, req ActionRequest, timeout time.Duration) (pkgmodel.AckEvent, error) {
+	var ack pkgmodel.AckEvent
+	hub, err := s.Check(ctx, req)
+	if errors.Is(err, ErrConfirmRequired) || errors.Is(err, ErrPINRequired) {
+		return ack, err
+	}
+
+	entry := audit.Entry{
+		Kind:     audit.KindAction,
+		TGUser:   req.TGUser,
+		ChatID:   req.ChatID,
+		HubID:    req.HubID,
+		DeviceID: req.DeviceID,
+		Action:   req.ActionText(),
+		Source:   string(req.Source),
+		Result:   audit.ResultOK,
+	}
+	if err != nil {
+		entry.Result = audit.ResultDenied
+		if errors.Is(err, ErrPINLocked) {
+			entry.Result = audit.ResultLocked
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			// Несуществующий хаб не может быть записан в журнал
+			entry.HubID = 0
+		}
+	} else {
+		start := time.Now()
+		ack, err = s.send(ctx, hub, req, timeout)
+		entry.LatencyMs = time.Since(start).Milliseconds()
+		if err != nil {
+			entry.Result = audit.ResultFailed
+		}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if entry.Result != audit.ResultDenied {
+		s.publish(hub, entry)
+	}
+	s.auditService.Record(ctx, entry)
+
+	return ack, err
+}
+
+// publish передает результат отправленной на хаб команды получателям событий
+func (s PolicyServiceImpl) publish(hub hubs.Hub, entry audit.Entry) {
+	s.events.Publish(model.HubEvent{
+		Type: pkgmodel.EventCommand,
+		Hub:  hub,
+		Command: model.CommandResult{
+			TGUser:    entry.TGUser,
+			DeviceID:  entry.DeviceID,
+			Action:    entry.Action,
+			Source:    entry.Source,
+			Success:   entry.Result == audit.ResultOK,
+			Error:     entry.Error,
+			LatencyMs: entry.LatencyMs,
+		},
+		At: time.Now(),
+	})
+}
+
+func (s PolicyServiceImpl) send(ctx context.Context, hub hubs.Hub, req ActionRequest, timeout time.Duration) (pkgmodel.AckEvent, error) {
+	client, ok := s.clients.Get(hub.Name)
+	if !ok {
+		return pkgmodel.AckEvent{}, ErrHubOffline
+	}
+	a := pkgmodel.ActionEvent{
+		DeviceID: req.DeviceID,
+		Action:   req.Action,
+		Value:    req.Value,
+	}
+	if timeout == 0 {
+		return pkgmodel.AckEvent{}, client.SendAction(a)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ack, err := client.Execute(waitCtx, a)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ack, ErrNoAck
+	case errors.Is(err, model.ErrClientClosed):
+		return ack, ErrHubOffline
+	case err != nil:
+		return ack, err
+	case !ack.Success:
+		return ack, fmt.Errorf("%w: %s", ErrActionFailed, ack.Error)
+	}
+	return ack, nil
+}
+
+func (s PolicyServiceImpl) Check(ctx context.Context, req ActionRequest) (hubs.Hub, error) {
+	hub, err := s.check(ctx, req)
+	if err != nil && !errors.Is(err, ErrConfirmRequired) && !errors.Is(err, ErrPINRequired) {
+		s.logger.Warn().
+			Err(err).
+			Str("tgUser", req.TGUser).
+			Int64("hubID", req.HubID).
+			Str("deviceID", req.DeviceID).
+			Str("action", req.ActionText()).
+			Str("source", string(req.Source)).
+			Msg("devices: action denied")
+	}
+	return hub, err
+}
+
+func (s PolicyServiceImpl) check(ctx context.Context, req ActionRequest) (hubs.Hub, error) {
+	if req.Action == pkgmodel.SetValue && req.Value == nil {
+		return hubs.Hub{}, ErrValueRequired
+	}
+	access, err := s.hubService.FindAccess(ctx, req.TGUser, req.HubID)
+	if err != nil {
+		return hubs.Hub{}, err
+	}
+	if !access.Role.Can(pkgmodel.SendActions) {
+		return hubs.Hub{}, hubs.ErrForbidden
+	}
+
+	policy, err := s.policyRepo.FindPolicy(ctx, req.HubID, req.DeviceID)
+	if errors.Is(err, repository.ErrNotFound) {
+		policy = DefaultPolicy(req.HubID, req.DeviceID)
+	} else if err != nil {
+		return hubs.Hub{}, fmt.Errorf("find policy: %w", err)
+	}
+
+	if !access.Role.AtLeast(policy.MinRole) {
+		return hubs.Hub{}, ErrDeviceForbidden
+	}
+	if !req.Source.Interactive() && policy.Interactive() {
+		return hubs.Hub{}, ErrInteractiveOnly
+	}
+	if policy.HasPIN {
+		if len(req.PIN) == 0 {
+			return hubs.Hub{}, ErrPINRequired
+		}
+		key := newPINKey(req)
+		if until, locked := s.pins.locked(key, time.Now()); locked {
+			return hubs.Hub{}, fmt.Errorf("%w until %s", ErrPINLocked, until.Format(time.RFC3339))
+		}
+		ok, err := s.policyRepo.CheckPIN(ctx, req.HubID, req.DeviceID, req.PIN)
+		if err != nil {
+			return hubs.Hub{}, fmt.Errorf("check pin: %w", err)
+		}
+		if !ok {
+			if until, locked := s.pins.fail(key, time.Now()); locked {
+				return hubs.Hub{}, fmt.Errorf("%w, %w until %s", ErrWrongPIN, ErrPINLocked, until.Format(time.RFC3339))
+			}
+			return hubs.Hub{}, ErrWrongPIN
+		}
+		s.pins.reset(key)
+		// Ввод PIN-кода одновременно подтверждает команду
+		return access.Hub, nil
+	}
+	if policy.Confirm && !req.Confirmed {
+		return hubs.Hub{}, ErrConfirmRequired
+	}
+
+	return access.Hub, nil
+}
+
+func (s PolicyServiceImpl) Hold(req ActionRequest, needPIN bool) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(b)
+
+	now := time.Now()
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	for key, p := range s.pending {
+		if now.After(p.expiresAt) {
+			delete(s.pending, key)
+		}
+	}
+	s.pending[pendingKey(req.TGUser, req.ChatID)] = pending{
+		id:        id,
+		req:       req,
+		needPIN:   needPIN,
+		expiresAt: now.Add(ConfirmTTL),
+	}
+	return id, nil
+}
+
+func (s PolicyServiceImpl) Take(tgName string, chatID int64, id string) (ActionRequest, bool) {
+	key := pendingKey(tgName, chatID)
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	p, ok := s.pending[key]
+	// Устаревшая кнопка подтверждения не выполняет и не отменяет более позднюю команду
+	if !ok || (len(id) == 0 && !p.needPIN) || (len(id) != 0 && id != p.id) {
+		return ActionRequest{}, false
+	}
+	delete(s.pending, key)
+	if time.Now().After(p.expiresAt) {
+		return ActionRequest{}, false
+	}
+	return p.req, true
+}
+
+// NewPolicyService создает сервис правил управления устройствами
+func NewPolicyService(
+	policyRepo PolicyRepository,
+	hubService hubs.HubService,
+	auditService audit.AuditService,
+	clients *collections.ConcurrentMap[string, *model.ClientEvents],
+	events model.EventPublisher,
+	logger zerolog.Logger,
+) PolicyServiceImpl {
+	return PolicyServiceImpl{
+		policyRepo:   policyRepo,
+		hubService:   hubService,
+		auditService: auditService,
+		clients:      clients,
+		pending:      make(map[string]pending),
+		pendingMu:    &sync.Mutex{},
+		pins:         newPINGuard(),
+		events:       events,
+		logger:       logger,
+	}
+}
@@ -0,0 +1,56 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestPolicyServiceHoldTake(t *testing.T) {
+	lamp := ActionRequest{TGUser: "user", ChatID: 1, HubID: 1, DeviceID: "Lamp001"}
+	gate := ActionRequest{TGUser: "user", ChatID: 1, HubID: 1, DeviceID: "Gate001"}
+	pump := ActionRequest{TGUser: "user", ChatID: 2, HubID: 1, DeviceID: "Pump001"}
+
+	tests := []struct {
+		name   string
+		hold   []ActionRequest
+		pin    []bool
+		chatID int64
+		// id индекс удерживаемой команды, идентификатор которой передается в Take, -1 - пустой идентификатор
+		id     int
+		want   string
+		wantOK bool
+	}{
+		{name: "confirm by id", hold: []ActionRequest{lamp}, pin: []bool{false}, chatID: 1, id: 0, want: "Lamp001", wantOK: true},
+		{name: "stale button", hold: []ActionRequest{lamp, gate}, pin: []bool{false, false}, chatID: 1, id: 0},
+		{name: "latest button", hold: []ActionRequest{lamp, gate}, pin: []bool{false, false}, chatID: 1, id: 1, want: "Gate001", wantOK: true},
+		{name: "other chat", hold: []ActionRequest{lamp}, pin: []bool{false}, chatID: 2, id: 0},
+		{name: "pin in same chat", hold: []ActionRequest{lamp}, pin: []bool{true}, chatID: 1, id: -1, want: "Lamp001", wantOK: true},
+		{name: "pin in other chat", hold: []ActionRequest{lamp, pump}, pin: []bool{true, true}, chatID: 2, id: -1, want: "Pump001", wantOK: true},
+		{name: "pin without pin request", hold: []ActionRequest{lamp}, pin: []bool{false}, chatID: 1, id: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewPolicyService(nil, nil, nil, nil, nil, zerolog.Nop())
+			ids := make([]string, len(tt.hold))
+			for i, req := range tt.hold {
+				id, err := s.Hold(req, tt.pin[i])
+				if err != nil {
+					t.Fatal(err)
+				}
+				ids[i] = id
+			}
+			id := ""
+			if tt.id >= 0 {
+				id = ids[tt.id]
+			}
+			req, ok := s.Take("user", tt.chatID, id)
+			if ok != tt.wantOK || req.DeviceID != tt.want {
+				t.Fatalf("Take() = %s, %v, want %s, %v", req.DeviceID, ok, tt.want, tt.wantOK)
+			}
+			if _, ok := s.Take("user", tt.chatID, id); ok {
+				t.Fatal("Take() returned the same request twice")
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/channels"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/i18n"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/repository"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/users"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+)
+
+// channelTestTimeout ограничение времени тестовой отправки, включая повторы
+const channelTestTimeout = time.Minute
+
+func channelErrorText(l i18n.Localizer, err error) string {
+	switch {
+	case errors.Is(err, channels.ErrChannelDisabled):
+		return l.T("channel.disabled")
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return l.T("channel.exists")
+	case errors.Is(err, repository.ErrNotFound):
+		return l.T("channel.not_found")
+	}
+	return argErrorText(l, err)
+}
+
+// ChannelsHandler /channels, :channels - каналы уведомлений пользователя и уровни важности для каждого
+func ChannelsHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, channelService channels.ChannelService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		var chatID int64
+		var username string
+		if update.Message == nil {
+			callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
+			if _, err := botApi.Request(callback); err != nil {
+				logger.Fatal().Err(err).Send()
+			}
+			chatID = update.CallbackQuery.Message.Chat.ID
+			username = update.CallbackQuery.From.UserName
+		} else {
+			chatID = update.Message.Chat.ID
+			username = update.Message.From.UserName
+		}
+
+		if prevMsg, ok := userService.GetUserLastMessage(username, chatID); ok {
+			delMsg := tgbotapi.NewDeleteMessage(chatID, prevMsg.MessageID)
+			_, _ = botApi.Send(delMsg)
+		}
+
+		l := userService.Localizer(ctx, updateFrom(update))
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		if userService.IsUserExists(ctx, username) {
+			list, err := channelService.FindChannels(ctx, username)
+			if err != nil {
+				logger.Error().Err(err).Send()
+			}
+
+			telegram := channels.AllSeverities
+			var sb strings.Builder
+			var rows [][]tgbotapi.InlineKeyboardButton
+			sb.WriteString(l.T("channels.title"))
+			sb.WriteString("\n")
+			for _, c := range list {
+				if c.Kind == channels.KindTelegram {
+					telegram = c.Severities
+					continue
+				}
+				sb.WriteString(l.T("channels.item", c.ID, c.Kind, c.Address, c.Severities))
+				sb.WriteString("\n")
+				rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(l.T("channels.test", c.ID), fmt.Sprintf("handler:channelTest?id=%d", c.ID)),
+					tgbotapi.NewInlineKeyboardButtonData(NegativeCross, fmt.Sprintf("handler:channelDelete?id=%d", c.ID)),
+				))
+			}
+			sb.WriteString(l.T("channels.telegram", telegram))
+			sb.WriteString("\n\n")
+			sb.WriteString(l.T("channels.usage"))
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(l.T("button.main_menu"), "/menu"),
+			))
+			msg.Text = sb.String()
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		} else {
+			msg.Text = l.T("error.unknown_user")
+		}
+
+		sent, err := botApi.Send(msg)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		userService.SetUserLastMessage(username, sent)
+	}
+}
+
+// NewChannelHandler /channel_add email|webhook <адрес> [уровни] - добавление канала уведомлений
+// доступно только в личном чате, так как в ответе передается ключ подписи webhook
+func NewChannelHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, channelService channels.ChannelService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		l := userService.Localizer(ctx, update.Message.From)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, l.T("error.unknown"))
+		args := strings.Fields(update.Message.CommandArguments())
+
+		switch {
+		case !update.Message.Chat.IsPrivate():
+			msg.Text = l.T("channel.private_only")
+		case len(args) < 2 || len(args) > 3:
+			msg.Text = l.T("channels.usage")
+		default:
+			dst, err := func() (channels.Destination, error) {
+				kind, err := channels.NewKind(args[0])
+				if err != nil {
+					return channels.Destination{}, err
+				}
+				var severities channels.Severities
+				if len(args) == 3 {
+					if severities, err = channels.ParseSeverities(args[2]); err != nil {
+						return channels.Destination{}, err
+					}
+				}
+				return channelService.AddChannel(ctx, update.Message.From.UserName, kind, args[1], severities)
+			}()
+			switch {
+			case err != nil:
+				logger.Error().Err(err).Send()
+				msg.Text = channelErrorText(l, err)
+			case dst.Kind == channels.KindWebhook:
+				msg.Text = l.T("channel.added_webhook", dst.ID, dst.Secret, channels.HeaderSignature, channels.HeaderTimestamp)
+			default:
+				msg.Text = l.T("channel.added", dst.ID)
+			}
+		}
+
+		if _, err := botApi.Send(msg); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+	}
+}
+
+// ChannelSeverityHandler /channel_severity telegram|<номер> <уровни> - выбор уровней важности канала
+func ChannelSeverityHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, channelService channels.ChannelService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		l := userService.Localizer(ctx, update.Message.From)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, l.T("error.unknown"))
+		args := strings.Fields(update.Message.CommandArguments())
+		username := update.Message.From.UserName
+
+		if len(args) != 2 {
+			msg.Text = l.T("channels.usage")
+		} else {
+			err := func() error {
+				severities, err := channels.ParseSeverities(args[1])
+				if err != nil {
+					return err
+				}
+				if strings.EqualFold(args[0], string(channels.KindTelegram)) {
+					return channelService.SetTelegramSeverities(ctx, username, severities)
+				}
+				id, err := strconv.ParseInt(args[0], 10, 64)
+				if err != nil {
+					return argError{arg: args[0]}
+				}
+				return channelService.SetSeverities(ctx, username, id, severities)
+			}()
+			if err != nil {
+				logger.Error().Err(err).Send()
+				msg.Text = channelErrorText(l, err)
+			} else {
+				msg.Text = l.T("channel.severities_set")
+			}
+		}
+
+		if _, err := botApi.Send(msg); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+	}
+}
+
+// DeleteChannelHandler :channelDelete - удаление канала уведомлений
+// параметр id - номер канала
+func DeleteChannelHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, channelService channels.ChannelService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	list := ChannelsHandler(ctx, logger, userService, channelService)
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		id, err := strconv.ParseInt(ParseReqParams(update.CallbackQuery.Data).Get("id"), 10, 64)
+		if err != nil {
+			logger.Error().Err(err).Msg("handler: invalid channel id")
+		} else if err := channelService.DeleteChannel(ctx, update.CallbackQuery.From.UserName, id); err != nil {
+			logger.Error().Err(err).Msg("handler: failed to delete channel")
+		}
+		list(update, botApi)
+	}
+}
+
+// TestChannelHandler :channelTest - тестовое уведомление в канал, результат отправляется отдельным сообщением
+// параметр id - номер канала
+func TestChannelHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, channelService channels.ChannelService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		l := userService.Localizer(ctx, update.CallbackQuery.From)
+		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, l.T("channel.sending"))
+		if _, err := botApi.Request(callback); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+
+		chatID := update.CallbackQuery.Message.Chat.ID
+		username := update.CallbackQuery.From.UserName
+		id, err := strconv.ParseInt(ParseReqParams(update.CallbackQuery.Data).Get("id"), 10, 64)
+		if err != nil {
+			logger.Error().Err(err).Msg("handler: invalid channel id")
+			return
+		}
+
+		// доставка с повторами может занять время, обработка обновлений не блокируется
+		go func() {
+			testCtx, cancel := context.WithTimeout(ctx, channelTestTimeout)
+			defer cancel()
+
+			msg := tgbotapi.NewMessage(chatID, l.T("channel.test_ok", id))
+			if err := channelService.TestChannel(testCtx, username, id, l.T("channel.test_message")); err != nil {
+				logger.Error().Err(err).Msg("handler: channel test failed")
+				msg.Text = l.T("channel.test_failed", id, channelErrorText(l, err))
+			}
+			if _, err := botApi.Send(msg); err != nil {
+				logger.Error().Err(err).Send()
+			}
+		}()
+	}
+}
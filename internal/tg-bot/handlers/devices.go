@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/control"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/devices"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/i18n"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/users"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+)
+
+// deviceErrorText текст ответа пользователю для ошибок проверки правил устройства
+func deviceErrorText(l i18n.Localizer, err error) string {
+	switch {
+	case errors.Is(err, devices.ErrDeviceForbidden):
+		return l.T("device.forbidden")
+	case errors.Is(err, devices.ErrPINLocked):
+		return l.T("device.pin_locked")
+	case errors.Is(err, devices.ErrWrongPIN):
+		return l.T("device.wrong_pin")
+	case errors.Is(err, devices.ErrInteractiveOnly):
+		return l.T("device.interactive_only")
+	case errors.Is(err, devices.ErrHubOffline):
+		return l.T("device.hub_offline")
+	case errors.Is(err, control.ErrNoReply):
+		return l.T("device.no_reply")
+	default:
+		return hubErrorText(l, err)
+	}
+}
+
+// deviceActionReply проверяет правила устройства, отправляет команду на хаб и возвращает ответ пользователю
+// команда, требующая подтверждения или PIN-кода, сохраняется до ответа пользователя
+func deviceActionReply(
+	ctx context.Context,
+	logger zerolog.Logger,
+	l i18n.Localizer,
+	policyService devices.PolicyService,
+	req devices.ActionRequest,
+) (string, tgbotapi.InlineKeyboardMarkup) {
+	icon := "\xF0\x9F\x92\xA1"
+	back := tgbotapi.NewInlineKeyboardButtonData(l.T("button.back"), fmt.Sprintf("handler:lampMenu?hub=%d&lampID=%s", req.HubID, req.DeviceID))
+	if req.Action == pkgmodel.SetValue {
+		icon = setpointIcon
+		back = tgbotapi.NewInlineKeyboardButtonData(l.T("button.back"), fmt.Sprintf("handler:setpointMenu?hub=%d&deviceID=%s", req.HubID, req.DeviceID))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(icon)
+	sb.WriteString(fmt.Sprintf("%s\n", req.DeviceID))
+
+	err := policyService.Execute(ctx, req)
+	switch {
+	case errors.Is(err, devices.ErrConfirmRequired):
+		id, err := policyService.Hold(req, false)
+		if err != nil {
+			logger.Error().Err(err).Msg("handler: failed to hold action")
+			sb.WriteString(l.T("error.unknown"))
+			break
+		}
+		sb.WriteString(l.T("device.confirm", req.ActionText()))
+		return sb.String(), tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(l.T("device.confirm_button"), "handler:lampConfirm?id="+id),
+				back,
+			),
+		)
+	case errors.Is(err, devices.ErrPINRequired):
+		if _, err := policyService.Hold(req, true); err != nil {
+			logger.Error().Err(err).Msg("handler: failed to hold action")
+			sb.WriteString(l.T("error.unknown"))
+			break
+		}
+		sb.WriteString(l.N("device.pin_required", int(devices.ConfirmTTL/time.Minute), req.ActionText()))
+	case err != nil:
+		logger.Error().Err(err).Msg("handler: failed to send action")
+		sb.WriteString(deviceErrorText(l, err))
+	default:
+		sb.WriteString(fmt.Sprintf("%s\n", req.ActionText()))
+	}
+
+	return sb.String(), tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(back))
+}
+
+// LampConfirmHandler :lampConfirm - подтверждение команды устройству, ожидающей подтверждения пользователя
+// параметр id - идентификатор ожидающей команды, кнопка устаревшей команды не выполняет более позднюю
+func LampConfirmHandler(
+	ctx context.Context,
+	logger zerolog.Logger,
+	userService users.UserService,
+	policyService devices.PolicyService,
+) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
+		if _, err := botApi.Request(callback); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+
+		username := update.CallbackQuery.From.UserName
+		chatID := update.CallbackQuery.Message.Chat.ID
+		l := userService.Localizer(ctx, update.CallbackQuery.From)
+		if prevMsg, ok := userService.GetUserLastMessage(username, chatID); ok {
+			delMsg := tgbotapi.NewDeleteMessage(chatID, prevMsg.MessageID)
+			_, _ = botApi.Send(delMsg)
+		}
+
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		if req, ok := policyService.Take(username, chatID, ParseReqParams(update.CallbackQuery.Data).Get("id")); ok {
+			req.Confirmed = true
+			msg.Text, msg.ReplyMarkup = deviceActionReply(ctx, logger, l, policyService, req)
+		} else {
+			msg.Text = l.T("device.confirm_expired")
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(l.T("menu.light"), "handler:lightControl"),
+			))
+		}
+
+		sent, err := botApi.Send(msg)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		userService.SetUserLastMessage(username, sent)
+	}
+}
+
+// PINHandler /pin - ввод PIN-кода для команды устройству, ожидающей подтверждения
+// сообщение с PIN-кодом удаляется из чата
+func PINHandler(
+	ctx context.Context,
+	logger zerolog.Logger,
+	userService users.UserService,
+	policyService devices.PolicyService,
+) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		username := update.Message.From.UserName
+		chatID := update.Message.Chat.ID
+		l := userService.Localizer(ctx, update.Message.From)
+		_, _ = botApi.Send(tgbotapi.NewDeleteMessage(chatID, update.Message.MessageID))
+
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		pin := strings.TrimSpace(update.Message.CommandArguments())
+		if len(pin) == 0 {
+			msg.Text = l.T("device.pin_usage")
+		} else if req, ok := policyService.Take(username, chatID, ""); ok {
+			req.PIN = pin
+			msg.Text, msg.ReplyMarkup = deviceActionReply(ctx, logger, l, policyService, req)
+		} else {
+			msg.Text = l.T("device.no_pending_pin")
+		}
+
+		sent, err := botApi.Send(msg)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		userService.SetUserLastMessage(username, sent)
+	}
+}
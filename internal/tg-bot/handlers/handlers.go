@@ -3,8 +3,11 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/devices"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/users"
 	"github.com/c0dered273/automation-remote-controller/pkg/collections"
@@ -32,22 +35,41 @@ func MenuHandler(ctx context.Context, logger zerolog.Logger, userService users.U
 			chatID = update.Message.Chat.ID
 			username = update.Message.From.UserName
 		}
+		l := userService.Localizer(ctx, updateFrom(update))
 
-		if prevMsg, ok := userService.GetUserLastMessage(username); ok {
+		if prevMsg, ok := userService.GetUserLastMessage(username, chatID); ok {
 			delMsg := tgbotapi.NewDeleteMessage(chatID, prevMsg.MessageID)
 			_, _ = botApi.Send(delMsg)
 		}
 
-		msg := tgbotapi.NewMessage(chatID, "Error: unknown")
-		if err := userService.SetUserChatID(ctx, username, chatID); err != nil {
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		var err error
+		if isPrivateChat(update) {
+			// Уведомления владельцу отправляются в личный чат, поэтому запоминаем только его
+			err = userService.SetUserChatID(ctx, username, chatID)
+		} else if !userService.IsUserExists(ctx, username) {
+			err = fmt.Errorf("handler: unknown user %s", username)
+		}
+		if err != nil {
 			logger.Error().Err(err).Send()
-			msg.Text = "Error: unknown user"
+			msg.Text = l.T("error.unknown_user")
 		} else {
-			msg.Text = "Главное меню"
+			msg.Text = l.T("menu.title")
 			inlineMainMenu := tgbotapi.NewInlineKeyboardMarkup(
 				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData("Состояние", "handler:status"),
-					tgbotapi.NewInlineKeyboardButtonData("Освещение", "handler:lightControl"),
+					tgbotapi.NewInlineKeyboardButtonData(l.T("menu.status"), "handler:status"),
+					tgbotapi.NewInlineKeyboardButtonData(l.T("menu.light"), "handler:lightControl"),
+				),
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(l.T("menu.setpoints"), "handler:setpoints"),
+				),
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(l.T("menu.scenes"), "handler:scenes"),
+					tgbotapi.NewInlineKeyboardButtonData(l.T("menu.schedules"), "handler:schedules"),
+				),
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(l.T("menu.hubs"), "handler:hubs"),
+					tgbotapi.NewInlineKeyboardButtonData(l.T("menu.language"), "handler:language"),
 				),
 			)
 			msg.ReplyMarkup = inlineMainMenu
@@ -61,23 +83,24 @@ func MenuHandler(ctx context.Context, logger zerolog.Logger, userService users.U
 	}
 }
 
-// StartNotificationsHandler /start - включить уведомления
-func StartNotificationsHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, clients *collections.ConcurrentMap[string, *model.ClientEvents]) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+// StartNotificationsHandler /start - включить уведомления в личный чат от всех хабов пользователя,
+// если задан адрес Mini App webAppURL, кнопка меню личного чата подписывается на языке пользователя
+func StartNotificationsHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, webAppURL string) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
 	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
 		username := update.Message.From.UserName
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Error: unknown")
+		l := userService.Localizer(ctx, update.Message.From)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, l.T("error.unknown"))
 		if err := userService.SetNotification(ctx, username, true); err != nil {
 			logger.Error().Err(err).Send()
-			msg.Text = "Error: unknown user"
+			msg.Text = l.T("error.unknown_user")
 		} else {
-			client, ok := clients.Get(username)
-			if !ok {
-				logger.Error().Msg("handler: failed to find client grpc stream")
-			}
-			client.IsNotify = true
-
-			msg.Text = "notifications enabled"
+			msg.Text = l.T("notify.enabled")
 			msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+			if len(webAppURL) != 0 && isPrivateChat(update) {
+				if err := SetMenuButton(botApi, update.Message.Chat.ID, l, webAppURL); err != nil {
+					logger.Error().Err(err).Msg("handler: failed to set menu button")
+				}
+			}
 		}
 
 		if _, err := botApi.Send(msg); err != nil {
@@ -86,22 +109,17 @@ func StartNotificationsHandler(ctx context.Context, logger zerolog.Logger, userS
 	}
 }
 
-// StopNotificationsHandler /stop - отключить уведомления
-func StopNotificationsHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, clients *collections.ConcurrentMap[string, *model.ClientEvents]) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+// StopNotificationsHandler /stop - отключить уведомления в личный чат
+func StopNotificationsHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
 	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
 		username := update.Message.From.UserName
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Error: unknown")
+		l := userService.Localizer(ctx, update.Message.From)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, l.T("error.unknown"))
 		if err := userService.SetNotification(ctx, username, false); err != nil {
 			logger.Error().Err(err).Send()
-			msg.Text = "Error: unknown user"
+			msg.Text = l.T("error.unknown_user")
 		} else {
-			client, ok := clients.Get(username)
-			if !ok {
-				logger.Error().Msg("handler: failed to find client grpc stream")
-			}
-			client.IsNotify = false
-
-			msg.Text = "notifications disabled"
+			msg.Text = l.T("notify.disabled")
 			msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
 		}
 
@@ -112,7 +130,13 @@ func StopNotificationsHandler(ctx context.Context, logger zerolog.Logger, userSe
 }
 
 // StatusHandler :status - состояние систем
-func StatusHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+func StatusHandler(
+	ctx context.Context,
+	logger zerolog.Logger,
+	userService users.UserService,
+	hubService hubs.HubService,
+	clients *collections.ConcurrentMap[string, *model.ClientEvents],
+) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
 	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
 		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
 		if _, err := botApi.Request(callback); err != nil {
@@ -120,16 +144,37 @@ func StatusHandler(ctx context.Context, logger zerolog.Logger, userService users
 		}
 
 		username := update.CallbackQuery.From.UserName
-		msg := tgbotapi.NewMessage(update.CallbackQuery.Message.Chat.ID, "Error: unknown")
+		l := userService.Localizer(ctx, update.CallbackQuery.From)
+		msg := tgbotapi.NewMessage(update.CallbackQuery.Message.Chat.ID, l.T("error.unknown"))
 		if userService.IsUserExists(ctx, username) {
-			var sb strings.Builder
-			sb.WriteString(fmt.Sprintf("%s Электричество\n", PositiveCheck))
-			sb.WriteString(fmt.Sprintf("%s Отопление\n", PositiveCheck))
-			sb.WriteString(fmt.Sprintf("%s Водоснабжение\n", NegativeCross))
-			sb.WriteString(fmt.Sprintf("%s Вентиляция\n", PositiveCheck))
-			msg.Text = sb.String()
+			available, err := hubService.FindHubs(ctx, username, pkgmodel.ViewStatus)
+			if err != nil {
+				logger.Error().Err(err).Send()
+			}
+			if len(available) == 0 {
+				msg.Text = l.T("error.access_denied")
+			} else {
+				var sb strings.Builder
+				for _, hub := range available {
+					mark := NegativeCross
+					if _, ok := clients.Get(hub.Name); ok {
+						mark = PositiveCheck
+					}
+					sb.WriteString(l.T("status.hub", mark, hub.Name, hub.Role))
+					sb.WriteString("\n")
+				}
+				sb.WriteString(l.T("status.power", PositiveCheck))
+				sb.WriteString("\n")
+				sb.WriteString(l.T("status.heating", PositiveCheck))
+				sb.WriteString("\n")
+				sb.WriteString(l.T("status.water", NegativeCross))
+				sb.WriteString("\n")
+				sb.WriteString(l.T("status.ventilation", PositiveCheck))
+				sb.WriteString("\n")
+				msg.Text = sb.String()
+			}
 		} else {
-			msg.Text = "Error: unknown user"
+			msg.Text = l.T("error.unknown_user")
 		}
 		sent, err := botApi.Send(msg)
 		if err != nil {
@@ -140,7 +185,8 @@ func StatusHandler(ctx context.Context, logger zerolog.Logger, userService users
 }
 
 // LightControlHandler :lightControl - меню управления освещением
-func LightControlHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+// параметр hub - идентификатор хаба, если у пользователя несколько хабов и параметр не указан, предлагается выбрать хаб
+func LightControlHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, hubService hubs.HubService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
 	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
 		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
 		if _, err := botApi.Request(callback); err != nil {
@@ -148,35 +194,62 @@ func LightControlHandler(ctx context.Context, logger zerolog.Logger, userService
 		}
 
 		username := update.CallbackQuery.From.UserName
-		if prevMsg, ok := userService.GetUserLastMessage(username); ok {
+		l := userService.Localizer(ctx, update.CallbackQuery.From)
+		if prevMsg, ok := userService.GetUserLastMessage(username, update.CallbackQuery.Message.Chat.ID); ok {
 			delMsg := tgbotapi.NewDeleteMessage(update.CallbackQuery.Message.Chat.ID, prevMsg.MessageID)
 			_, _ = botApi.Send(delMsg)
 		}
 
-		msg := tgbotapi.NewMessage(update.CallbackQuery.Message.Chat.ID, "Error: unknown")
+		msg := tgbotapi.NewMessage(update.CallbackQuery.Message.Chat.ID, l.T("error.unknown"))
 		if userService.IsUserExists(ctx, username) {
-			lamp01 := "Lamp001"
-			lamp02 := "Lamp002"
-			lamp03 := "Lamp003"
+			available, err := hubService.FindHubs(ctx, username, pkgmodel.SendActions)
+			if err != nil {
+				logger.Error().Err(err).Send()
+			}
+			hubID, _ := strconv.ParseInt(ParseReqParams(update.CallbackQuery.Data).Get("hub"), 10, 64)
+			if hubID == 0 && len(available) == 1 {
+				hubID = available[0].ID
+			}
 
-			inlineButtons := tgbotapi.NewInlineKeyboardMarkup(
-				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("\xF0\x9F\x92\xA1%s", lamp01), fmt.Sprintf("handler:lampMenu?lampID=%s", lamp01)),
-				),
-				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("\xF0\x9F\x92\xA1%s", lamp02), fmt.Sprintf("handler:lampMenu?lampID=%s", lamp02)),
-				),
-				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("\xF0\x9F\x92\xA1%s", lamp03), fmt.Sprintf("handler:lampMenu?lampID=%s", lamp03)),
-				),
-				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData("Главное меню", "/menu"),
-				),
-			)
-			msg.Text = "Освещение"
-			msg.ReplyMarkup = inlineButtons
+			switch {
+			case len(available) == 0:
+				msg.Text = l.T("error.access_denied")
+			case hubID == 0:
+				var rows [][]tgbotapi.InlineKeyboardButton
+				for _, hub := range available {
+					rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+						tgbotapi.NewInlineKeyboardButtonData(hub.Name, fmt.Sprintf("handler:lightControl?hub=%d", hub.ID)),
+					))
+				}
+				rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(l.T("button.main_menu"), "/menu"),
+				))
+				msg.Text = l.T("light.select_hub")
+				msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+			default:
+				lamp01 := "Lamp001"
+				lamp02 := "Lamp002"
+				lamp03 := "Lamp003"
+
+				inlineButtons := tgbotapi.NewInlineKeyboardMarkup(
+					tgbotapi.NewInlineKeyboardRow(
+						tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("\xF0\x9F\x92\xA1%s", lamp01), fmt.Sprintf("handler:lampMenu?hub=%d&lampID=%s", hubID, lamp01)),
+					),
+					tgbotapi.NewInlineKeyboardRow(
+						tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("\xF0\x9F\x92\xA1%s", lamp02), fmt.Sprintf("handler:lampMenu?hub=%d&lampID=%s", hubID, lamp02)),
+					),
+					tgbotapi.NewInlineKeyboardRow(
+						tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("\xF0\x9F\x92\xA1%s", lamp03), fmt.Sprintf("handler:lampMenu?hub=%d&lampID=%s", hubID, lamp03)),
+					),
+					tgbotapi.NewInlineKeyboardRow(
+						tgbotapi.NewInlineKeyboardButtonData(l.T("button.main_menu"), "/menu"),
+					),
+				)
+				msg.Text = l.T("light.title")
+				msg.ReplyMarkup = inlineButtons
+			}
 		} else {
-			msg.Text = "Error: unknown user"
+			msg.Text = l.T("error.unknown_user")
 		}
 
 		sent, err := botApi.Send(msg)
@@ -188,6 +261,7 @@ func LightControlHandler(ctx context.Context, logger zerolog.Logger, userService
 }
 
 // LampMenuHandler :lampMenu - меню управления лампой
+// параметр hub - идентификатор хаба
 // параметр lampID - идентификатор устройства, для которого нужно вывести меню
 func LampMenuHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
 	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
@@ -197,15 +271,17 @@ func LampMenuHandler(ctx context.Context, logger zerolog.Logger, userService use
 		}
 
 		username := update.CallbackQuery.From.UserName
-		if prevMsg, ok := userService.GetUserLastMessage(username); ok {
+		l := userService.Localizer(ctx, update.CallbackQuery.From)
+		if prevMsg, ok := userService.GetUserLastMessage(username, update.CallbackQuery.Message.Chat.ID); ok {
 			delMsg := tgbotapi.NewDeleteMessage(update.CallbackQuery.Message.Chat.ID, prevMsg.MessageID)
 			_, _ = botApi.Send(delMsg)
 		}
 
-		msg := tgbotapi.NewMessage(update.CallbackQuery.Message.Chat.ID, "Error: unknown")
+		msg := tgbotapi.NewMessage(update.CallbackQuery.Message.Chat.ID, l.T("error.unknown"))
 		if userService.IsUserExists(ctx, username) {
 			reqParams := ParseReqParams(update.CallbackQuery.Data)
-			lampID := reqParams["lampID"][0]
+			hubID := reqParams.Get("hub")
+			lampID := reqParams.Get("lampID")
 
 			var sb strings.Builder
 			sb.WriteString("\xF0\x9F\x92\xA1")
@@ -213,18 +289,21 @@ func LampMenuHandler(ctx context.Context, logger zerolog.Logger, userService use
 
 			inlineButtons := tgbotapi.NewInlineKeyboardMarkup(
 				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData("Включить", fmt.Sprintf("handler:lampSwitch?lampID=%s&action=switchON", lampID)),
-					tgbotapi.NewInlineKeyboardButtonData("Отключить", fmt.Sprintf("handler:lampSwitch?lampID=%s&action=switchOFF", lampID)),
+					tgbotapi.NewInlineKeyboardButtonData(l.T("lamp.on"), fmt.Sprintf("handler:lampSwitch?hub=%s&lampID=%s&action=switchON", hubID, lampID)),
+					tgbotapi.NewInlineKeyboardButtonData(l.T("lamp.off"), fmt.Sprintf("handler:lampSwitch?hub=%s&lampID=%s&action=switchOFF", hubID, lampID)),
+				),
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(l.T("lamp.toggle"), fmt.Sprintf("handler:lampSwitch?hub=%s&lampID=%s&action=toggle", hubID, lampID)),
 				),
 				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData("Назад", "handler:lightControl"),
+					tgbotapi.NewInlineKeyboardButtonData(l.T("button.back"), fmt.Sprintf("handler:lightControl?hub=%s", hubID)),
 				),
 			)
 
 			msg.Text = sb.String()
 			msg.ReplyMarkup = inlineButtons
 		} else {
-			msg.Text = "Error: unknown user"
+			msg.Text = l.T("error.unknown_user")
 		}
 		sent, err := botApi.Send(msg)
 		if err != nil {
@@ -235,9 +314,17 @@ func LampMenuHandler(ctx context.Context, logger zerolog.Logger, userService use
 }
 
 // LampSwitchHandler :lampSwitch - выполнение указанной команды для устройства
+// параметр hub - идентификатор хаба
 // параметр lampID - идентификатор устройства, для которого нужно выполнить команду
 // параметр action - команды
-func LampSwitchHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, clients *collections.ConcurrentMap[string, *model.ClientEvents]) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+// команда отправляется, только если роль пользователя в хабе и правила устройства разрешают управление,
+// для отдельных устройств перед отправкой запрашивается подтверждение или PIN-код
+func LampSwitchHandler(
+	ctx context.Context,
+	logger zerolog.Logger,
+	userService users.UserService,
+	policyService devices.PolicyService,
+) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
 	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
 		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
 		if _, err := botApi.Request(callback); err != nil {
@@ -245,48 +332,28 @@ func LampSwitchHandler(ctx context.Context, logger zerolog.Logger, userService u
 		}
 
 		username := update.CallbackQuery.From.UserName
-		if prevMsg, ok := userService.GetUserLastMessage(username); ok {
+		l := userService.Localizer(ctx, update.CallbackQuery.From)
+		if prevMsg, ok := userService.GetUserLastMessage(username, update.CallbackQuery.Message.Chat.ID); ok {
 			delMsg := tgbotapi.NewDeleteMessage(update.CallbackQuery.Message.Chat.ID, prevMsg.MessageID)
 			_, _ = botApi.Send(delMsg)
 		}
 
-		msg := tgbotapi.NewMessage(update.CallbackQuery.Message.Chat.ID, "Error: unknown")
+		msg := tgbotapi.NewMessage(update.CallbackQuery.Message.Chat.ID, l.T("error.unknown"))
 		if userService.IsUserExists(ctx, username) {
 			reqParams := ParseReqParams(update.CallbackQuery.Data)
-			lampID := reqParams["lampID"][0]
-			action := reqParams["action"][0]
-
-			eventAction, _ := pkgmodel.NewAction(action)
-			event := pkgmodel.ActionEvent{
-				DeviceID: lampID,
+			hubID, _ := strconv.ParseInt(reqParams.Get("hub"), 10, 64)
+			eventAction, _ := pkgmodel.NewAction(reqParams.Get("action"))
+
+			msg.Text, msg.ReplyMarkup = deviceActionReply(ctx, logger, l, policyService, devices.ActionRequest{
+				TGUser:   username,
+				ChatID:   update.CallbackQuery.Message.Chat.ID,
+				HubID:    hubID,
+				DeviceID: reqParams.Get("lampID"),
 				Action:   eventAction,
-			}
-
-			client, ok := clients.Get(update.CallbackQuery.From.UserName)
-			if !ok {
-				logger.Error().Msg("handler: failed to find client grpc stream")
-			}
-
-			err := client.SendAction(event)
-			if err != nil {
-				logger.Error().Err(err).Msg("handler: failed to send action")
-			}
-
-			var sb strings.Builder
-			sb.WriteString("\xF0\x9F\x92\xA1")
-			sb.WriteString(fmt.Sprintf("%s\n", lampID))
-			sb.WriteString(fmt.Sprintf("%s\n", action))
-
-			inlineButtons := tgbotapi.NewInlineKeyboardMarkup(
-				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData("Назад", fmt.Sprintf("handler:lampMenu?lampID=%s", lampID)),
-				),
-			)
-			msg.Text = sb.String()
-			msg.ReplyMarkup = inlineButtons
-
+				Source:   devices.SourceBot,
+			})
 		} else {
-			msg.Text = "Error: unknown user"
+			msg.Text = l.T("error.unknown_user")
 		}
 		sent, err := botApi.Send(msg)
 		if err != nil {
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/audit"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/i18n"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/users"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+)
+
+// parseHistoryArgs разбирает фильтры команды /history в формате ключ=значение
+func parseHistoryArgs(args string) (audit.Filter, error) {
+	f := audit.Filter{}
+	for _, arg := range strings.Fields(args) {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || len(value) == 0 {
+			return audit.Filter{}, argError{arg: arg}
+		}
+		switch key {
+		case "hub":
+			f.HubName = value
+		case "device":
+			f.DeviceID = value
+		case "kind":
+			kind := audit.Kind(value)
+			if kind != audit.KindAction && kind != audit.KindNotification {
+				return audit.Filter{}, argError{arg: arg}
+			}
+			f.Kind = kind
+		default:
+			return audit.Filter{}, argError{arg: arg}
+		}
+	}
+	return f, nil
+}
+
+// historyPageData параметры кнопки перехода на страницу журнала
+func historyPageData(f audit.Filter, offset int) string {
+	params := url.Values{}
+	params.Set("o", strconv.Itoa(offset))
+	if len(f.HubName) != 0 {
+		params.Set("h", f.HubName)
+	}
+	if len(f.DeviceID) != 0 {
+		params.Set("d", f.DeviceID)
+	}
+	if len(f.Kind) != 0 {
+		params.Set("k", string(f.Kind))
+	}
+	return "handler:history?" + params.Encode()
+}
+
+// historyLine строка журнала для сообщения пользователю
+func historyLine(l i18n.Localizer, e audit.Entry, loc *time.Location) string {
+	ts := e.CreatedAt.In(loc).Format("02.01 15:04:05")
+	if e.Kind == audit.KindNotification {
+		return fmt.Sprintf("%s %s \U0001F514 %s [%s]", ts, e.HubName, e.Text, e.Result)
+	}
+	line := fmt.Sprintf("%s %s %s %s @%s (%s) [%s]", ts, e.HubName, e.DeviceID, e.Action, e.TGUser, e.Source, e.Result)
+	if e.Result == audit.ResultOK {
+		line += " " + l.T("history.latency", e.LatencyMs)
+	}
+	return line
+}
+
+// HistoryHandler /history, :history - журнал команд устройствам и уведомлений по хабам пользователя
+// фильтры команды указываются в формате ключ=значение, см. history.usage в каталоге сообщений
+// параметр o - смещение страницы, h, d, k - фильтры по хабу, устройству и типу записи
+func HistoryHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, auditService audit.AuditService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		var chatID int64
+		var username string
+		var filter audit.Filter
+		var filterErr error
+		if update.Message == nil {
+			callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
+			if _, err := botApi.Request(callback); err != nil {
+				logger.Fatal().Err(err).Send()
+			}
+			chatID = update.CallbackQuery.Message.Chat.ID
+			username = update.CallbackQuery.From.UserName
+
+			reqParams := ParseReqParams(update.CallbackQuery.Data)
+			filter.Offset, _ = strconv.Atoi(reqParams.Get("o"))
+			filter.HubName = reqParams.Get("h")
+			filter.DeviceID = reqParams.Get("d")
+			filter.Kind = audit.Kind(reqParams.Get("k"))
+		} else {
+			chatID = update.Message.Chat.ID
+			username = update.Message.From.UserName
+			filter, filterErr = parseHistoryArgs(update.Message.CommandArguments())
+		}
+
+		if prevMsg, ok := userService.GetUserLastMessage(username, chatID); ok {
+			delMsg := tgbotapi.NewDeleteMessage(chatID, prevMsg.MessageID)
+			_, _ = botApi.Send(delMsg)
+		}
+
+		l := userService.Localizer(ctx, updateFrom(update))
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		user, err := userService.FindUserByTGName(ctx, username)
+		switch {
+		case err != nil:
+			msg.Text = l.T("error.unknown_user")
+		case filterErr != nil:
+			msg.Text = lines(argErrorText(l, filterErr), l.T("history.usage"))
+		default:
+			loc, err := time.LoadLocation(user.Timezone)
+			if err != nil {
+				loc = time.UTC
+			}
+			// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+			filter.Limit = audit.DefaultLimit + 1
+			entries, err := auditService.FindHistory(ctx, username, filter)
+			if err != nil {
+				logger.Error().Err(err).Msg("handler: failed to find history")
+				break
+			}
+			hasNext := len(entries) > audit.DefaultLimit
+			if hasNext {
+				entries = entries[:audit.DefaultLimit]
+			}
+
+			var sb strings.Builder
+			sb.WriteString(l.T("history.title"))
+			sb.WriteString("\n")
+			if len(entries) == 0 {
+				sb.WriteString(l.T("history.empty"))
+				sb.WriteString("\n")
+			}
+			for _, e := range entries {
+				sb.WriteString(historyLine(l, e, loc))
+				sb.WriteString("\n")
+			}
+			msg.Text = sb.String()
+
+			var nav []tgbotapi.InlineKeyboardButton
+			if filter.Offset > 0 {
+				prev := filter.Offset - audit.DefaultLimit
+				if prev < 0 {
+					prev = 0
+				}
+				nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(l.T("history.newer"), historyPageData(filter, prev)))
+			}
+			if hasNext {
+				nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(l.T("history.older"), historyPageData(filter, filter.Offset+audit.DefaultLimit)))
+			}
+			rows := [][]tgbotapi.InlineKeyboardButton{}
+			if len(nav) != 0 {
+				rows = append(rows, nav)
+			}
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(l.T("button.main_menu"), "/menu"),
+			))
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		}
+
+		sent, err := botApi.Send(msg)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		userService.SetUserLastMessage(username, sent)
+	}
+}
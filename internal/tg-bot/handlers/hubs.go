@@ -0,0 +1,460 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/i18n"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/repository"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/users"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+)
+
+// roles роли, которые можно назначить участнику хаба
+var roles = []pkgmodel.Role{pkgmodel.RoleOwner, pkgmodel.RoleOperator, pkgmodel.RoleViewer}
+
+// chatTitle название чата для списка подписок
+func chatTitle(chat *tgbotapi.Chat) string {
+	if len(chat.Title) != 0 {
+		return chat.Title
+	}
+	return "@" + chat.UserName
+}
+
+// hubErrorText текст ответа пользователю для ошибок сервиса хабов
+func hubErrorText(l i18n.Localizer, err error) string {
+	switch {
+	case errors.Is(err, hubs.ErrForbidden):
+		return l.T("error.access_denied")
+	case errors.Is(err, hubs.ErrLastOwner):
+		return l.T("hub.last_owner")
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return l.T("hub.already_member")
+	case errors.Is(err, repository.ErrNotFound):
+		return l.T("hub.not_found")
+	default:
+		return l.T("error.unknown")
+	}
+}
+
+// BindChatHandler /bind - подписка текущего чата (группы) на уведомления хаба
+// подписку может добавить только пользователь, имеющий право управлять хабом
+func BindChatHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, hubService hubs.HubService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		username := update.Message.From.UserName
+		chat := update.Message.Chat
+		l := userService.Localizer(ctx, update.Message.From)
+		msg := tgbotapi.NewMessage(chat.ID, l.T("error.unknown"))
+
+		hubName := strings.TrimSpace(update.Message.CommandArguments())
+		switch {
+		case !userService.IsUserExists(ctx, username):
+			msg.Text = l.T("error.unknown_user")
+		case len(hubName) == 0:
+			msg.Text = l.T("hub.bind_usage")
+		default:
+			if err := hubService.Subscribe(ctx, username, hubName, chat.ID, chatTitle(chat)); err != nil {
+				logger.Error().Err(err).Msg("handler: failed to bind chat")
+				msg.Text = hubErrorText(l, err)
+			} else {
+				msg.Text = l.T("hub.bound", hubName)
+			}
+		}
+
+		if _, err := botApi.Send(msg); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+	}
+}
+
+// UnbindChatHandler /unbind - отмена подписки текущего чата на уведомления хаба
+func UnbindChatHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, hubService hubs.HubService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		username := update.Message.From.UserName
+		chatID := update.Message.Chat.ID
+		l := userService.Localizer(ctx, update.Message.From)
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+
+		hubName := strings.TrimSpace(update.Message.CommandArguments())
+		switch {
+		case !userService.IsUserExists(ctx, username):
+			msg.Text = l.T("error.unknown_user")
+		case len(hubName) == 0:
+			msg.Text = l.T("hub.bind_usage")
+		default:
+			if err := hubService.Unsubscribe(ctx, username, hubName, chatID); err != nil {
+				logger.Error().Err(err).Msg("handler: failed to unbind chat")
+				msg.Text = hubErrorText(l, err)
+			} else {
+				msg.Text = l.T("hub.unbound", hubName)
+			}
+		}
+
+		if _, err := botApi.Send(msg); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+	}
+}
+
+// SubscriptionsHandler /subscriptions, :subscriptions - список чатов, подписанных на уведомления хабов пользователя
+func SubscriptionsHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, hubService hubs.HubService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		var chatID int64
+		var username string
+		if update.Message == nil {
+			callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
+			if _, err := botApi.Request(callback); err != nil {
+				logger.Fatal().Err(err).Send()
+			}
+			chatID = update.CallbackQuery.Message.Chat.ID
+			username = update.CallbackQuery.From.UserName
+		} else {
+			chatID = update.Message.Chat.ID
+			username = update.Message.From.UserName
+		}
+
+		if prevMsg, ok := userService.GetUserLastMessage(username, chatID); ok {
+			delMsg := tgbotapi.NewDeleteMessage(chatID, prevMsg.MessageID)
+			_, _ = botApi.Send(delMsg)
+		}
+
+		l := userService.Localizer(ctx, updateFrom(update))
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		if userService.IsUserExists(ctx, username) {
+			list, err := hubService.FindSubscriptions(ctx, username)
+			if err != nil {
+				logger.Error().Err(err).Send()
+			}
+
+			var rows [][]tgbotapi.InlineKeyboardButton
+			for _, s := range list {
+				rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s: %s", s.HubName, s.Title), "handler:subscriptions"),
+					tgbotapi.NewInlineKeyboardButtonData(NegativeCross, fmt.Sprintf("handler:unsubscribe?hub=%s&chat=%d", s.HubName, s.ChatID)),
+				))
+			}
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(l.T("button.main_menu"), "/menu"),
+			))
+			msg.Text = l.T("subscriptions.title")
+			if len(list) == 0 {
+				msg.Text = lines(l.T("subscriptions.title"), l.T("subscriptions.empty"))
+			}
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		} else {
+			msg.Text = l.T("error.unknown_user")
+		}
+
+		sent, err := botApi.Send(msg)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		userService.SetUserLastMessage(username, sent)
+	}
+}
+
+// UnsubscribeHandler :unsubscribe - удаление подписки чата
+// параметр hub - имя хаба
+// параметр chat - идентификатор чата
+func UnsubscribeHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, hubService hubs.HubService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	list := SubscriptionsHandler(ctx, logger, userService, hubService)
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		reqParams := ParseReqParams(update.CallbackQuery.Data)
+		chatID, err := strconv.ParseInt(reqParams.Get("chat"), 10, 64)
+		if err != nil {
+			logger.Error().Err(err).Msg("handler: invalid chat id")
+		} else if err := hubService.Unsubscribe(ctx, update.CallbackQuery.From.UserName, reqParams.Get("hub"), chatID); err != nil {
+			logger.Error().Err(err).Msg("handler: failed to delete subscription")
+		}
+		list(update, botApi)
+	}
+}
+
+// BotMembershipHandler обрабатывает изменение статуса бота в чате
+// при добавлении в группу отправляет инструкцию по подписке, при удалении из группы удаляет подписки чата
+func BotMembershipHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, hubService hubs.HubService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		member := update.MyChatMember
+		if member.Chat.IsPrivate() {
+			return
+		}
+
+		if member.NewChatMember.HasLeft() || member.NewChatMember.WasKicked() {
+			if err := hubService.DeleteChat(ctx, member.Chat.ID); err != nil {
+				logger.Error().Err(err).Msg("handler: failed to delete chat subscriptions")
+			}
+			return
+		}
+
+		if member.OldChatMember.HasLeft() || member.OldChatMember.WasKicked() {
+			l := userService.Localizer(ctx, &member.From)
+			msg := tgbotapi.NewMessage(member.Chat.ID, l.T("hub.bind_usage"))
+			if _, err := botApi.Send(msg); err != nil {
+				logger.Error().Err(err).Send()
+			}
+		}
+	}
+}
+
+// HubsHandler /hubs, :hubs - список хабов, доступных пользователю, и его роль в каждом из них
+func HubsHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, hubService hubs.HubService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		var chatID int64
+		var username string
+		if update.Message == nil {
+			callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
+			if _, err := botApi.Request(callback); err != nil {
+				logger.Fatal().Err(err).Send()
+			}
+			chatID = update.CallbackQuery.Message.Chat.ID
+			username = update.CallbackQuery.From.UserName
+		} else {
+			chatID = update.Message.Chat.ID
+			username = update.Message.From.UserName
+		}
+
+		if prevMsg, ok := userService.GetUserLastMessage(username, chatID); ok {
+			delMsg := tgbotapi.NewDeleteMessage(chatID, prevMsg.MessageID)
+			_, _ = botApi.Send(delMsg)
+		}
+
+		l := userService.Localizer(ctx, updateFrom(update))
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		user, err := userService.FindUserByTGName(ctx, username)
+		if err == nil {
+			list, err := hubService.FindHubs(ctx, username, pkgmodel.ViewStatus)
+			if err != nil {
+				logger.Error().Err(err).Send()
+			}
+
+			var rows [][]tgbotapi.InlineKeyboardButton
+			for _, hub := range list {
+				label := fmt.Sprintf("%s (%s)", hub.Name, hub.Role)
+				if hub.Role.Can(pkgmodel.ManageHub) {
+					rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+						tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("handler:members?hub=%d", hub.ID)),
+					))
+				} else {
+					rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+						tgbotapi.NewInlineKeyboardButtonData(label, "handler:hubs"),
+						tgbotapi.NewInlineKeyboardButtonData(l.T("hubs.leave"), fmt.Sprintf("handler:memberRemove?hub=%d&user=%d", hub.ID, user.ID)),
+					))
+				}
+			}
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(l.T("button.main_menu"), "/menu"),
+			))
+			msg.Text = l.T("hubs.title")
+			if len(list) == 0 {
+				msg.Text = lines(l.T("hubs.title"), l.T("hubs.empty"))
+			}
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		} else {
+			msg.Text = l.T("error.unknown_user")
+		}
+
+		sent, err := botApi.Send(msg)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		userService.SetUserLastMessage(username, sent)
+	}
+}
+
+// InviteHandler /invite - создание приглашения в хаб
+func InviteHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, hubService hubs.HubService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		username := update.Message.From.UserName
+		l := userService.Localizer(ctx, update.Message.From)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, l.T("error.unknown"))
+
+		args := strings.Fields(update.Message.CommandArguments())
+		switch {
+		case !userService.IsUserExists(ctx, username):
+			msg.Text = l.T("error.unknown_user")
+		case len(args) != 2:
+			msg.Text = l.T("hub.invite_usage")
+		default:
+			role, err := pkgmodel.NewRole(args[1])
+			if err != nil {
+				msg.Text = lines(l.T("error.invalid_argument", args[1]), l.T("hub.invite_usage"))
+				break
+			}
+			inv, err := hubService.Invite(ctx, username, args[0], role)
+			if err != nil {
+				logger.Error().Err(err).Msg("handler: failed to create invitation")
+				msg.Text = hubErrorText(l, err)
+			} else {
+				msg.Text = l.T("hub.invitation", inv.HubName, inv.Role, inv.ExpiresAt.Format(time.RFC822), inv.Code)
+			}
+		}
+
+		if _, err := botApi.Send(msg); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+	}
+}
+
+// JoinHandler /join - вступление в хаб по коду приглашения
+func JoinHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, hubService hubs.HubService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		username := update.Message.From.UserName
+		l := userService.Localizer(ctx, update.Message.From)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, l.T("error.unknown"))
+
+		code := strings.TrimSpace(update.Message.CommandArguments())
+		switch {
+		case !userService.IsUserExists(ctx, username):
+			msg.Text = l.T("error.unknown_user")
+		case len(code) == 0:
+			msg.Text = l.T("hub.join_usage")
+		default:
+			inv, err := hubService.Join(ctx, username, code)
+			switch {
+			case errors.Is(err, repository.ErrNotFound):
+				msg.Text = l.T("hub.invitation_invalid")
+			case err != nil:
+				logger.Error().Err(err).Msg("handler: failed to accept invitation")
+				msg.Text = hubErrorText(l, err)
+			default:
+				msg.Text = l.T("hub.joined", inv.HubName, inv.Role)
+			}
+		}
+
+		if _, err := botApi.Send(msg); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+	}
+}
+
+// MembersHandler /members, :members - список участников хаба с кнопками изменения роли и удаления
+// параметр hub - идентификатор хаба, для команды /members указывается имя хаба
+func MembersHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, hubService hubs.HubService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		var chatID int64
+		var username string
+		var hubID int64
+		var err error
+		if update.Message == nil {
+			callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
+			if _, err := botApi.Request(callback); err != nil {
+				logger.Fatal().Err(err).Send()
+			}
+			chatID = update.CallbackQuery.Message.Chat.ID
+			username = update.CallbackQuery.From.UserName
+			hubID, err = strconv.ParseInt(ParseReqParams(update.CallbackQuery.Data).Get("hub"), 10, 64)
+		} else {
+			chatID = update.Message.Chat.ID
+			username = update.Message.From.UserName
+			var hub hubs.Hub
+			hub, err = hubService.Authorize(ctx, username, strings.TrimSpace(update.Message.CommandArguments()), pkgmodel.ManageHub)
+			hubID = hub.ID
+		}
+
+		if prevMsg, ok := userService.GetUserLastMessage(username, chatID); ok {
+			delMsg := tgbotapi.NewDeleteMessage(chatID, prevMsg.MessageID)
+			_, _ = botApi.Send(delMsg)
+		}
+
+		l := userService.Localizer(ctx, updateFrom(update))
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		var hub hubs.Hub
+		var members []hubs.Member
+		if err == nil {
+			hub, members, err = hubService.FindMembers(ctx, username, hubID)
+		}
+		if err != nil {
+			logger.Error().Err(err).Msg("handler: failed to find hub members")
+			msg.Text = hubErrorText(l, err)
+		} else {
+			var rows [][]tgbotapi.InlineKeyboardButton
+			for _, m := range members {
+				row := tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("@%s (%s)", m.TGUser, m.Role), fmt.Sprintf("handler:members?hub=%d", hub.ID)),
+				)
+				for _, r := range roles {
+					if r == m.Role {
+						continue
+					}
+					row = append(row, tgbotapi.NewInlineKeyboardButtonData(string(r), fmt.Sprintf("handler:memberRole?hub=%d&user=%d&role=%s", hub.ID, m.UserID, r)))
+				}
+				row = append(row, tgbotapi.NewInlineKeyboardButtonData(NegativeCross, fmt.Sprintf("handler:memberRemove?hub=%d&user=%d", hub.ID, m.UserID)))
+				rows = append(rows, row)
+			}
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(l.T("button.back"), "handler:hubs"),
+			))
+			msg.Text = l.T("members.title", hub.Name)
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		}
+
+		sent, err := botApi.Send(msg)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		userService.SetUserLastMessage(username, sent)
+	}
+}
+
+// MemberRoleHandler :memberRole - изменение роли участника хаба
+// параметр hub - идентификатор хаба
+// параметр user - идентификатор участника
+// параметр role - новая роль
+func MemberRoleHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, hubService hubs.HubService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	list := MembersHandler(ctx, logger, userService, hubService)
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		reqParams := ParseReqParams(update.CallbackQuery.Data)
+		hubID, _ := strconv.ParseInt(reqParams.Get("hub"), 10, 64)
+		userID, _ := strconv.ParseInt(reqParams.Get("user"), 10, 64)
+		role, err := pkgmodel.NewRole(reqParams.Get("role"))
+		if err == nil {
+			err = hubService.SetRole(ctx, update.CallbackQuery.From.UserName, hubID, userID, role)
+		}
+		if err != nil {
+			logger.Error().Err(err).Msg("handler: failed to change member role")
+			l := userService.Localizer(ctx, update.CallbackQuery.From)
+			callback := tgbotapi.NewCallback(update.CallbackQuery.ID, hubErrorText(l, err))
+			if _, err := botApi.Request(callback); err != nil {
+				logger.Fatal().Err(err).Send()
+			}
+			return
+		}
+		list(update, botApi)
+	}
+}
+
+// MemberRemoveHandler :memberRemove - удаление участника из хаба или выход из хаба
+// параметр hub - идентификатор хаба
+// параметр user - идентификатор участника
+func MemberRemoveHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, hubService hubs.HubService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	members := MembersHandler(ctx, logger, userService, hubService)
+	hubsList := HubsHandler(ctx, logger, userService, hubService)
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		username := update.CallbackQuery.From.UserName
+		reqParams := ParseReqParams(update.CallbackQuery.Data)
+		hubID, _ := strconv.ParseInt(reqParams.Get("hub"), 10, 64)
+		userID, _ := strconv.ParseInt(reqParams.Get("user"), 10, 64)
+		if err := hubService.RemoveMember(ctx, username, hubID, userID); err != nil {
+			logger.Error().Err(err).Msg("handler: failed to remove member")
+			l := userService.Localizer(ctx, update.CallbackQuery.From)
+			callback := tgbotapi.NewCallback(update.CallbackQuery.ID, hubErrorText(l, err))
+			if _, err := botApi.Request(callback); err != nil {
+				logger.Fatal().Err(err).Send()
+			}
+			return
+		}
+		if _, err := hubService.AuthorizeByID(ctx, username, hubID, pkgmodel.ManageHub); err != nil {
+			// Пользователь покинул хаб сам
+			hubsList(update, botApi)
+			return
+		}
+		members(update, botApi)
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/i18n"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/users"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+)
+
+// languageKeyboard кнопки выбора языка, название каждого языка выводится на нем самом
+func languageKeyboard(l i18n.Localizer) tgbotapi.InlineKeyboardMarkup {
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(i18n.Langs))
+	for _, lang := range i18n.Langs {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.New(lang).T("language.name"), "handler:language?lang="+string(lang)))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(l.T("button.main_menu"), "/menu"),
+	))
+}
+
+// LanguageHandler /language, :language - выбор языка сообщений бота
+// /language en - установить язык, без аргументов предлагается выбрать язык кнопками
+// параметр lang - код выбранного языка
+// если задан адрес Mini App webAppURL, надпись кнопки меню личного чата меняется на выбранный язык
+func LanguageHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, webAppURL string) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		var chatID int64
+		var username string
+		var code string
+		if update.Message == nil {
+			callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
+			if _, err := botApi.Request(callback); err != nil {
+				logger.Fatal().Err(err).Send()
+			}
+			chatID = update.CallbackQuery.Message.Chat.ID
+			username = update.CallbackQuery.From.UserName
+			code = ParseReqParams(update.CallbackQuery.Data).Get("lang")
+		} else {
+			chatID = update.Message.Chat.ID
+			username = update.Message.From.UserName
+			code = strings.TrimSpace(update.Message.CommandArguments())
+		}
+
+		if prevMsg, ok := userService.GetUserLastMessage(username, chatID); ok {
+			delMsg := tgbotapi.NewDeleteMessage(chatID, prevMsg.MessageID)
+			_, _ = botApi.Send(delMsg)
+		}
+
+		l := userService.Localizer(ctx, updateFrom(update))
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		switch {
+		case !userService.IsUserExists(ctx, username):
+			msg.Text = l.T("error.unknown_user")
+		case len(code) == 0:
+			msg.Text = l.T("language.current", l.T("language.name"))
+			msg.ReplyMarkup = languageKeyboard(l)
+		default:
+			lang, ok := i18n.Parse(code)
+			if !ok {
+				msg.Text = l.T("language.unsupported", code)
+				msg.ReplyMarkup = languageKeyboard(l)
+				break
+			}
+			if err := userService.SetLanguage(ctx, username, lang); err != nil {
+				logger.Error().Err(err).Msg("handler: failed to set language")
+				msg.Text = l.T("error.unknown_user")
+				break
+			}
+			l = i18n.New(lang)
+			if len(webAppURL) != 0 && isPrivateChat(update) {
+				if err := SetMenuButton(botApi, chatID, l, webAppURL); err != nil {
+					logger.Error().Err(err).Msg("handler: failed to set menu button")
+				}
+			}
+			msg.Text = l.T("language.set", l.T("language.name"))
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(l.T("button.main_menu"), "/menu"),
+			))
+		}
+
+		sent, err := botApi.Send(msg)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		userService.SetUserLastMessage(username, sent)
+	}
+}
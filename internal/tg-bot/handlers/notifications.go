@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/i18n"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/notifications"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/users"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+)
+
+// muteHours варианты времени отключения уведомления, предлагаемые кнопками под сообщением
+var muteHours = []int{1, 8, 24}
+
+// MuteKeyboard кнопки отключения уведомления на несколько часов
+func MuteKeyboard(l i18n.Localizer, alertKey string) tgbotapi.InlineKeyboardMarkup {
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(muteHours))
+	for _, h := range muteHours {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			l.N("mute.button", h),
+			fmt.Sprintf("handler:mute?key=%s&h=%d", alertKey, h),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row)
+}
+
+// MuteHandler :mute - отключение уведомления на N часов
+// параметр key - ключ источника уведомления
+// параметр h - количество часов
+func MuteHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, notifyService notifications.NotifyService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		reqParams := ParseReqParams(update.CallbackQuery.Data)
+		key := reqParams.Get("key")
+		hours, err := strconv.Atoi(reqParams.Get("h"))
+
+		l := userService.Localizer(ctx, update.CallbackQuery.From)
+		answer := l.T("error.unknown")
+		if err != nil || hours <= 0 || len(key) == 0 {
+			logger.Error().Str("data", update.CallbackQuery.Data).Msg("handler: invalid mute params")
+		} else {
+			username := update.CallbackQuery.From.UserName
+			err = notifyService.Mute(ctx, username, key, update.CallbackQuery.Message.Text, time.Duration(hours)*time.Hour)
+			if err != nil {
+				logger.Error().Err(err).Msg("handler: failed to mute alert")
+				answer = l.T("error.unknown_user")
+			} else {
+				answer = l.N("mute.done", hours)
+			}
+		}
+
+		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, answer)
+		if _, err := botApi.Request(callback); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+	}
+}
+
+// MutesHandler /mutes - список отключенных уведомлений
+func MutesHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, notifyService notifications.NotifyService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		var chatID int64
+		var username string
+		if update.Message == nil {
+			chatID = update.CallbackQuery.Message.Chat.ID
+			username = update.CallbackQuery.From.UserName
+		} else {
+			chatID = update.Message.Chat.ID
+			username = update.Message.From.UserName
+		}
+
+		if prevMsg, ok := userService.GetUserLastMessage(username, chatID); ok {
+			delMsg := tgbotapi.NewDeleteMessage(chatID, prevMsg.MessageID)
+			_, _ = botApi.Send(delMsg)
+		}
+
+		l := userService.Localizer(ctx, updateFrom(update))
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		prefs, err := notifyService.GetPrefs(ctx, username)
+		if err != nil {
+			logger.Error().Err(err).Send()
+			msg.Text = l.T("error.unknown_user")
+		} else {
+			mutes, err := notifyService.FindMutes(ctx, username)
+			if err != nil {
+				logger.Error().Err(err).Send()
+			}
+			loc, err := time.LoadLocation(prefs.Timezone)
+			if err != nil {
+				loc = time.UTC
+			}
+
+			var sb strings.Builder
+			sb.WriteString(l.T("mutes.title"))
+			sb.WriteString("\n")
+			if len(mutes) == 0 {
+				sb.WriteString(l.T("mutes.empty"))
+				sb.WriteString("\n")
+			}
+			var rows [][]tgbotapi.InlineKeyboardButton
+			for i, m := range mutes {
+				sb.WriteString(l.T("mutes.item", i+1, m.AlertText, m.MutedUntil.In(loc).Format("2006-01-02 15:04")))
+				sb.WriteString("\n")
+				rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("\U0001F514 %d", i+1), fmt.Sprintf("handler:unmute?key=%s", m.AlertKey)),
+				))
+			}
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(l.T("button.main_menu"), "/menu"),
+			))
+			msg.Text = sb.String()
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		}
+
+		sent, err := botApi.Send(msg)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		userService.SetUserLastMessage(username, sent)
+	}
+}
+
+// UnmuteHandler :unmute - включение ранее отключенного уведомления
+// параметр key - ключ источника уведомления
+func UnmuteHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, notifyService notifications.NotifyService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	list := MutesHandler(ctx, logger, userService, notifyService)
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
+		if _, err := botApi.Request(callback); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+
+		key := ParseReqParams(update.CallbackQuery.Data).Get("key")
+		if err := notifyService.Unmute(ctx, update.CallbackQuery.From.UserName, key); err != nil {
+			logger.Error().Err(err).Msg("handler: failed to unmute alert")
+		}
+		list(update, botApi)
+	}
+}
+
+// QuietHoursHandler /quiet - просмотр и установка часов тишины
+// /quiet 23:00 07:00 - установить, /quiet off - отключить
+func QuietHoursHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, notifyService notifications.NotifyService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		username := update.Message.From.UserName
+		l := userService.Localizer(ctx, update.Message.From)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, l.T("error.unknown"))
+
+		args := strings.Fields(update.Message.CommandArguments())
+		var err error
+		switch {
+		case len(args) == 1 && strings.EqualFold(args[0], "off"):
+			err = notifyService.DisableQuietHours(ctx, username)
+		case len(args) == 2:
+			err = notifyService.SetQuietHours(ctx, username, args[0], args[1])
+		case len(args) != 0:
+			msg.Text = l.T("quiet.usage")
+			if _, err := botApi.Send(msg); err != nil {
+				logger.Fatal().Err(err).Send()
+			}
+			return
+		}
+
+		if err != nil {
+			logger.Error().Err(err).Send()
+			msg.Text = l.T("error.generic", err)
+		} else if prefs, err := notifyService.GetPrefs(ctx, username); err != nil {
+			logger.Error().Err(err).Send()
+			msg.Text = l.T("error.unknown_user")
+		} else if prefs.QuietStart.Valid && prefs.QuietEnd.Valid {
+			msg.Text = l.T("quiet.enabled", prefs.QuietStart.String, prefs.QuietEnd.String, prefs.Timezone)
+		} else {
+			msg.Text = l.T("quiet.disabled")
+		}
+
+		if _, err := botApi.Send(msg); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+	}
+}
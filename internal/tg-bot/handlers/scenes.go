@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/i18n"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/scenes"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/users"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+)
+
+// ScenesHandler /scenes, :scenes - список сценариев пользователя
+func ScenesHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, sceneService scenes.SceneService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		var chatID int64
+		var username string
+		if update.Message == nil {
+			callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
+			if _, err := botApi.Request(callback); err != nil {
+				logger.Fatal().Err(err).Send()
+			}
+			chatID = update.CallbackQuery.Message.Chat.ID
+			username = update.CallbackQuery.From.UserName
+		} else {
+			chatID = update.Message.Chat.ID
+			username = update.Message.From.UserName
+		}
+
+		if prevMsg, ok := userService.GetUserLastMessage(username, chatID); ok {
+			delMsg := tgbotapi.NewDeleteMessage(chatID, prevMsg.MessageID)
+			_, _ = botApi.Send(delMsg)
+		}
+
+		l := userService.Localizer(ctx, updateFrom(update))
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		if userService.IsUserExists(ctx, username) {
+			list, err := sceneService.FindScenesByTGName(ctx, username)
+			if err != nil {
+				logger.Error().Err(err).Send()
+			}
+
+			var rows [][]tgbotapi.InlineKeyboardButton
+			for _, s := range list {
+				rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("▶ %s (%s)", s.Name, s.ClientName), fmt.Sprintf("handler:sceneRun?id=%d", s.ID)),
+					tgbotapi.NewInlineKeyboardButtonData(NegativeCross, fmt.Sprintf("handler:sceneDelete?id=%d", s.ID)),
+				))
+			}
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(l.T("button.main_menu"), "/menu"),
+			))
+			msg.Text = l.T("scenes.title")
+			if len(list) == 0 {
+				msg.Text = lines(l.T("scenes.title"), l.T("scenes.empty"))
+			}
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		} else {
+			msg.Text = l.T("error.unknown_user")
+		}
+
+		sent, err := botApi.Send(msg)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		userService.SetUserLastMessage(username, sent)
+	}
+}
+
+// NewSceneHandler /scene_add - создание сценария
+func NewSceneHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, sceneService scenes.SceneService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		username := update.Message.From.UserName
+		l := userService.Localizer(ctx, update.Message.From)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, l.T("error.unknown"))
+
+		if userService.IsUserExists(ctx, username) {
+			req, err := parseSceneArgs(username, update.Message.CommandArguments())
+			switch {
+			case errors.Is(err, errNotEnoughArgs):
+				msg.Text = l.T("scene.usage")
+			case err != nil:
+				msg.Text = lines(argErrorText(l, err), l.T("scene.usage"))
+			default:
+				scene, err := sceneService.NewScene(ctx, req)
+				if err != nil {
+					logger.Error().Err(err).Msg("handler: failed to create scene")
+					msg.Text = l.T("error.generic", err)
+				} else {
+					msg.Text = l.N("scene.created", len(scene.Steps), scene.Name)
+				}
+			}
+		} else {
+			msg.Text = l.T("error.unknown_user")
+		}
+
+		if _, err := botApi.Send(msg); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+	}
+}
+
+// RunSceneHandler :sceneRun - запуск сценария
+// параметр id - идентификатор сценария
+// сценарий выполняется в отдельной горутине, по завершении пользователю отправляется отчет по шагам
+func RunSceneHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, sceneService scenes.SceneService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		l := userService.Localizer(ctx, update.CallbackQuery.From)
+		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, l.T("scene.started"))
+		if _, err := botApi.Request(callback); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+
+		username := update.CallbackQuery.From.UserName
+		chatID := update.CallbackQuery.Message.Chat.ID
+		id, err := strconv.ParseInt(ParseReqParams(update.CallbackQuery.Data).Get("id"), 10, 64)
+		if err != nil {
+			logger.Error().Err(err).Msg("handler: invalid scene id")
+			return
+		}
+
+		go func() {
+			msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+			scene, results, err := sceneService.RunScene(ctx, username, id)
+			if err != nil {
+				logger.Error().Err(err).Msg("handler: failed to run scene")
+				msg.Text = l.T("error.generic", err)
+			} else {
+				msg.Text = sceneReport(l, scene, results)
+			}
+			if _, err := botApi.Send(msg); err != nil {
+				logger.Error().Err(err).Send()
+			}
+		}()
+	}
+}
+
+// DeleteSceneHandler :sceneDelete - удаление сценария
+// параметр id - идентификатор сценария
+func DeleteSceneHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, sceneService scenes.SceneService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	list := ScenesHandler(ctx, logger, userService, sceneService)
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		id, err := strconv.ParseInt(ParseReqParams(update.CallbackQuery.Data).Get("id"), 10, 64)
+		if err != nil {
+			logger.Error().Err(err).Msg("handler: invalid scene id")
+		} else if err := sceneService.DeleteScene(ctx, update.CallbackQuery.From.UserName, id); err != nil {
+			logger.Error().Err(err).Msg("handler: failed to delete scene")
+		}
+		list(update, botApi)
+	}
+}
+
+// sceneReport формирует отчет о выполнении шагов сценария
+func sceneReport(l i18n.Localizer, scene scenes.Scene, results []scenes.StepResult) string {
+	var sb strings.Builder
+	sb.WriteString(l.T("scene.report", scene.Name))
+	sb.WriteString("\n")
+	for i, r := range results {
+		switch {
+		case r.Skipped:
+			sb.WriteString(l.T("scene.step_skipped", i+1, r.Step.DeviceID, r.Step.Action))
+			sb.WriteString("\n")
+		case r.Err != nil:
+			sb.WriteString(fmt.Sprintf("%s %d. %s %s: %v\n", NegativeCross, i+1, r.Step.DeviceID, r.Step.Action, r.Err))
+		default:
+			sb.WriteString(fmt.Sprintf("%s %d. %s %s\n", PositiveCheck, i+1, r.Step.DeviceID, r.Step.Action))
+		}
+	}
+	return sb.String()
+}
+
+// parseSceneArgs разбирает аргументы команды /scene_add
+func parseSceneArgs(username string, args string) (scenes.NewSceneRequest, error) {
+	fields := strings.Fields(args)
+	if len(fields) < 3 {
+		return scenes.NewSceneRequest{}, errNotEnoughArgs
+	}
+
+	req := scenes.NewSceneRequest{
+		TGUser:     username,
+		ClientName: fields[0],
+		Name:       fields[1],
+	}
+	for _, f := range fields[2:] {
+		if strings.EqualFold(f, "stop") {
+			req.StopOnFailure = true
+			continue
+		}
+		parts := strings.Split(f, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return scenes.NewSceneRequest{}, argError{arg: f}
+		}
+		step := scenes.Step{
+			DeviceID: parts[0],
+			Action:   parts[1],
+		}
+		if len(parts) == 3 {
+			delay, err := time.ParseDuration(parts[2])
+			if err != nil || delay < 0 {
+				return scenes.NewSceneRequest{}, argError{arg: f}
+			}
+			step.DelayMs = delay.Milliseconds()
+		}
+		req.Steps = append(req.Steps, step)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/schedules"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/users"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+)
+
+// SchedulesHandler /schedules, :schedules - список расписаний пользователя
+func SchedulesHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, scheduleService schedules.ScheduleService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		var chatID int64
+		var username string
+		if update.Message == nil {
+			callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
+			if _, err := botApi.Request(callback); err != nil {
+				logger.Fatal().Err(err).Send()
+			}
+			chatID = update.CallbackQuery.Message.Chat.ID
+			username = update.CallbackQuery.From.UserName
+		} else {
+			chatID = update.Message.Chat.ID
+			username = update.Message.From.UserName
+		}
+
+		if prevMsg, ok := userService.GetUserLastMessage(username, chatID); ok {
+			delMsg := tgbotapi.NewDeleteMessage(chatID, prevMsg.MessageID)
+			_, _ = botApi.Send(delMsg)
+		}
+
+		l := userService.Localizer(ctx, updateFrom(update))
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		user, err := userService.FindUserByTGName(ctx, username)
+		if err != nil {
+			logger.Error().Err(err).Send()
+			msg.Text = l.T("error.unknown_user")
+		} else {
+			list, err := scheduleService.FindSchedulesByTGName(ctx, username)
+			if err != nil {
+				logger.Error().Err(err).Send()
+			}
+
+			var sb strings.Builder
+			sb.WriteString(l.T("schedules.title", user.Timezone))
+			sb.WriteString("\n")
+			if len(list) == 0 {
+				sb.WriteString(l.T("schedules.empty"))
+				sb.WriteString("\n")
+			}
+			var rows [][]tgbotapi.InlineKeyboardButton
+			for _, s := range list {
+				if s.FailedAt.Valid {
+					sb.WriteString(l.T("schedules.item_failed",
+						s.ID, s.ClientName, s.DeviceID, s.Action, s.Spec, s.FailedAt.Time.In(s.Location()).Format(schedules.OnceLayout)))
+				} else if s.LastError.Valid {
+					sb.WriteString(l.T("schedules.item_error",
+						s.ID, s.ClientName, s.DeviceID, s.Action, s.Spec, s.NextRunAt.In(s.Location()).Format(schedules.OnceLayout), s.LastError.String))
+				} else {
+					sb.WriteString(l.T("schedules.item",
+						s.ID, s.ClientName, s.DeviceID, s.Action, s.Spec, s.NextRunAt.In(s.Location()).Format(schedules.OnceLayout)))
+				}
+				sb.WriteString("\n")
+				rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s #%d", NegativeCross, s.ID), fmt.Sprintf("handler:scheduleDelete?id=%d", s.ID)),
+				))
+			}
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(l.T("button.main_menu"), "/menu"),
+			))
+			msg.Text = sb.String()
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		}
+
+		sent, err := botApi.Send(msg)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		userService.SetUserLastMessage(username, sent)
+	}
+}
+
+// NewScheduleHandler /schedule_add - создание расписания
+func NewScheduleHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, scheduleService schedules.ScheduleService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		username := update.Message.From.UserName
+		l := userService.Localizer(ctx, update.Message.From)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, l.T("error.unknown"))
+
+		user, err := userService.FindUserByTGName(ctx, username)
+		if err != nil {
+			logger.Error().Err(err).Send()
+			msg.Text = l.T("error.unknown_user")
+		} else {
+			req, err := parseScheduleArgs(username, update.Message.CommandArguments())
+			if err != nil {
+				msg.Text = l.T("schedule.usage")
+			} else {
+				schedule, err := scheduleService.NewSchedule(ctx, req, user.Timezone)
+				if err != nil {
+					logger.Error().Err(err).Msg("handler: failed to create schedule")
+					msg.Text = l.T("error.generic", err)
+				} else {
+					msg.Text = l.T("schedule.created",
+						schedule.ID, schedule.NextRunAt.In(schedule.Location()).Format(schedules.OnceLayout))
+				}
+			}
+		}
+
+		if _, err := botApi.Send(msg); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+	}
+}
+
+// DeleteScheduleHandler :scheduleDelete - удаление расписания
+// параметр id - идентификатор расписания
+func DeleteScheduleHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService, scheduleService schedules.ScheduleService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	list := SchedulesHandler(ctx, logger, userService, scheduleService)
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		reqParams := ParseReqParams(update.CallbackQuery.Data)
+		id, err := strconv.ParseInt(reqParams.Get("id"), 10, 64)
+		if err != nil {
+			logger.Error().Err(err).Msg("handler: invalid schedule id")
+		} else if err := scheduleService.DeleteSchedule(ctx, update.CallbackQuery.From.UserName, id); err != nil {
+			logger.Error().Err(err).Msg("handler: failed to delete schedule")
+		}
+		list(update, botApi)
+	}
+}
+
+// TimezoneHandler /timezone - просмотр и установка часового пояса пользователя
+func TimezoneHandler(ctx context.Context, logger zerolog.Logger, userService users.UserService) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		username := update.Message.From.UserName
+		l := userService.Localizer(ctx, update.Message.From)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, l.T("error.unknown"))
+
+		tz := strings.TrimSpace(update.Message.CommandArguments())
+		if len(tz) == 0 {
+			user, err := userService.FindUserByTGName(ctx, username)
+			if err != nil {
+				logger.Error().Err(err).Send()
+				msg.Text = l.T("error.unknown_user")
+			} else {
+				msg.Text = lines(l.T("timezone.current", user.Timezone), l.T("timezone.usage"))
+			}
+		} else if err := userService.SetTimezone(ctx, username, tz); err != nil {
+			logger.Error().Err(err).Send()
+			msg.Text = l.T("error.generic", err)
+		} else {
+			msg.Text = l.T("timezone.current", tz)
+		}
+
+		if _, err := botApi.Send(msg); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+	}
+}
+
+// parseScheduleArgs разбирает аргументы команды /schedule_add
+func parseScheduleArgs(username string, args string) (schedules.NewScheduleRequest, error) {
+	fields := strings.Fields(args)
+	if len(fields) < 4 {
+		return schedules.NewScheduleRequest{}, errNotEnoughArgs
+	}
+
+	req := schedules.NewScheduleRequest{
+		TGUser:     username,
+		ClientName: fields[0],
+		DeviceID:   fields[1],
+		Action:     fields[2],
+	}
+	spec := fields[3:]
+	if misfire, err := schedules.NewMisfirePolicy(spec[len(spec)-1]); err == nil {
+		req.Misfire = misfire
+		spec = spec[:len(spec)-1]
+	}
+	req.Spec = strings.Join(spec, " ")
+
+	return req, nil
+}
@@ -0,0 +1,413 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/control"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/devices"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/i18n"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/users"
+	"github.com/c0dered273/automation-remote-controller/pkg/collections"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// setpointIcon значок устройств с уставкой
+	setpointIcon = "\xF0\x9F\x8C\xA1"
+	// setpointChoices количество готовых значений в меню уставки
+	setpointChoices = 5
+	// setpointInputTTL время, в течение которого команда /set относится к устройству из последнего открытого меню уставки
+	setpointInputTTL = 5 * time.Minute
+)
+
+// setpointInput устройство, для которого пользователь вводит значение командой /set
+type setpointInput struct {
+	hubID     int64
+	deviceID  string
+	setpoint  pkgmodel.Setpoint
+	expiresAt time.Time
+}
+
+// SetpointInputs устройства, ожидающие ввода значения уставки, по пользователям telegram
+type SetpointInputs = collections.ConcurrentMap[string, setpointInput]
+
+// NewSetpointInputs создает хранилище устройств, ожидающих ввода значения уставки
+func NewSetpointInputs() *SetpointInputs {
+	return collections.NewConcurrentMap[string, setpointInput]()
+}
+
+// setpointText диапазон значений уставки для сообщения пользователю
+func setpointText(l i18n.Localizer, sp pkgmodel.Setpoint) string {
+	text := l.T("setpoint.range", pkgmodel.FormatValue(sp.Min), pkgmodel.FormatValue(sp.Max), sp.Unit)
+	if sp.Step > 0 {
+		text += ", " + l.T("setpoint.step", pkgmodel.FormatValue(sp.Step))
+	}
+	return text
+}
+
+// setpointValues готовые значения уставки, равномерно распределенные по диапазону с учетом шага
+func setpointValues(sp pkgmodel.Setpoint) []float64 {
+	n := setpointChoices
+	if sp.Step > 0 {
+		if count := int(math.Round((sp.Max-sp.Min)/sp.Step)) + 1; count < n {
+			n = count
+		}
+	}
+	if n < 2 {
+		return []float64{sp.Min}
+	}
+	result := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		v := sp.Snap(sp.Min + (sp.Max-sp.Min)*float64(i)/float64(n-1))
+		if len(result) == 0 || result[len(result)-1] != v {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// setpointError текст ответа пользователю для значения, не подходящего уставке
+func setpointError(l i18n.Localizer, sp pkgmodel.Setpoint, err error) string {
+	if errors.Is(err, pkgmodel.ErrSetpointStep) {
+		return l.T("setpoint.wrong_step", pkgmodel.FormatValue(sp.Step))
+	}
+	return l.T("setpoint.out_of_range", pkgmodel.FormatValue(sp.Min), pkgmodel.FormatValue(sp.Max), sp.Unit)
+}
+
+// readSetpoints запрашивает у хаба состояние устройств и возвращает устройства с уставкой
+func readSetpoints(
+	ctx context.Context, controlService control.ControlService, username string, hubName string, deviceIDs []string,
+) ([]pkgmodel.DeviceState, error) {
+	state, err := controlService.ReadState(ctx, username, hubName, deviceIDs)
+	if err != nil {
+		return nil, err
+	}
+	var result []pkgmodel.DeviceState
+	for _, d := range state.Devices {
+		if d.Setpoint != nil {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+// SetpointsHandler :setpoints - устройства хаба с уставкой и их текущие значения
+// параметр hub - идентификатор хаба, если у пользователя несколько хабов и параметр не указан, предлагается выбрать хаб
+func SetpointsHandler(
+	ctx context.Context,
+	logger zerolog.Logger,
+	userService users.UserService,
+	hubService hubs.HubService,
+	controlService control.ControlService,
+) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
+		if _, err := botApi.Request(callback); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+
+		username := update.CallbackQuery.From.UserName
+		chatID := update.CallbackQuery.Message.Chat.ID
+		l := userService.Localizer(ctx, update.CallbackQuery.From)
+		if prevMsg, ok := userService.GetUserLastMessage(username, chatID); ok {
+			delMsg := tgbotapi.NewDeleteMessage(chatID, prevMsg.MessageID)
+			_, _ = botApi.Send(delMsg)
+		}
+
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		if userService.IsUserExists(ctx, username) {
+			available, err := hubService.FindHubs(ctx, username, pkgmodel.SendActions)
+			if err != nil {
+				logger.Error().Err(err).Send()
+			}
+			hubID, _ := strconv.ParseInt(ParseReqParams(update.CallbackQuery.Data).Get("hub"), 10, 64)
+			if hubID == 0 && len(available) == 1 {
+				hubID = available[0].ID
+			}
+			var hubName string
+			for _, hub := range available {
+				if hub.ID == hubID {
+					hubName = hub.Name
+				}
+			}
+
+			var rows [][]tgbotapi.InlineKeyboardButton
+			switch {
+			case len(available) == 0:
+				msg.Text = l.T("error.access_denied")
+			case len(hubName) == 0:
+				for _, hub := range available {
+					rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+						tgbotapi.NewInlineKeyboardButtonData(hub.Name, fmt.Sprintf("handler:setpoints?hub=%d", hub.ID)),
+					))
+				}
+				msg.Text = l.T("setpoint.select_hub")
+			default:
+				states, err := readSetpoints(ctx, controlService, username, hubName, nil)
+				if err != nil {
+					logger.Error().Err(err).Msg("handler: failed to read setpoints")
+					msg.Text = deviceErrorText(l, err)
+					break
+				}
+				if len(states) == 0 {
+					msg.Text = l.T("setpoint.empty")
+					break
+				}
+				for _, d := range states {
+					rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+						tgbotapi.NewInlineKeyboardButtonData(
+							strings.TrimSpace(fmt.Sprintf("%s%s: %s %s", setpointIcon, d.DeviceID, d.Value, d.Setpoint.Unit)),
+							fmt.Sprintf("handler:setpointMenu?hub=%d&deviceID=%s", hubID, d.DeviceID),
+						),
+					))
+				}
+				msg.Text = l.T("setpoint.title")
+			}
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(l.T("button.main_menu"), "/menu"),
+			))
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		} else {
+			msg.Text = l.T("error.unknown_user")
+		}
+
+		sent, err := botApi.Send(msg)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		userService.SetUserLastMessage(username, sent)
+	}
+}
+
+// SetpointMenuHandler :setpointMenu - меню уставки устройства: текущее значение, готовые значения и шаг +/-
+// параметр hub - идентификатор хаба
+// параметр deviceID - идентификатор устройства
+// после открытия меню произвольное значение можно отправить командой /set
+func SetpointMenuHandler(
+	ctx context.Context,
+	logger zerolog.Logger,
+	userService users.UserService,
+	hubService hubs.HubService,
+	controlService control.ControlService,
+	inputs *SetpointInputs,
+) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
+		if _, err := botApi.Request(callback); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+
+		username := update.CallbackQuery.From.UserName
+		chatID := update.CallbackQuery.Message.Chat.ID
+		l := userService.Localizer(ctx, update.CallbackQuery.From)
+		if prevMsg, ok := userService.GetUserLastMessage(username, chatID); ok {
+			delMsg := tgbotapi.NewDeleteMessage(chatID, prevMsg.MessageID)
+			_, _ = botApi.Send(delMsg)
+		}
+
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		if userService.IsUserExists(ctx, username) {
+			reqParams := ParseReqParams(update.CallbackQuery.Data)
+			hubID, _ := strconv.ParseInt(reqParams.Get("hub"), 10, 64)
+			deviceID := reqParams.Get("deviceID")
+			back := tgbotapi.NewInlineKeyboardButtonData(l.T("button.back"), fmt.Sprintf("handler:setpoints?hub=%d", hubID))
+
+			var rows [][]tgbotapi.InlineKeyboardButton
+			hub, err := hubService.AuthorizeByID(ctx, username, hubID, pkgmodel.SendActions)
+			var states []pkgmodel.DeviceState
+			if err == nil {
+				states, err = readSetpoints(ctx, controlService, username, hub.Name, []string{deviceID})
+			}
+			switch {
+			case err != nil:
+				logger.Error().Err(err).Msg("handler: failed to read setpoint")
+				msg.Text = deviceErrorText(l, err)
+			case len(states) == 0:
+				msg.Text = l.T("setpoint.not_supported", deviceID)
+			default:
+				d := states[0]
+				sp := *d.Setpoint
+				inputs.Put(username, setpointInput{
+					hubID:     hubID,
+					deviceID:  d.DeviceID,
+					setpoint:  sp,
+					expiresAt: time.Now().Add(setpointInputTTL),
+				})
+
+				buttonData := func(v float64) string {
+					return fmt.Sprintf("handler:setValue?hub=%d&deviceID=%s&value=%s", hubID, d.DeviceID, pkgmodel.FormatValue(v))
+				}
+				if current, err := strconv.ParseFloat(d.Value, 64); err == nil && sp.Step > 0 {
+					var row []tgbotapi.InlineKeyboardButton
+					if down := sp.Snap(current - sp.Step); down < current {
+						row = append(row, tgbotapi.NewInlineKeyboardButtonData("-"+pkgmodel.FormatValue(sp.Step), buttonData(down)))
+					}
+					if up := sp.Snap(current + sp.Step); up > current {
+						row = append(row, tgbotapi.NewInlineKeyboardButtonData("+"+pkgmodel.FormatValue(sp.Step), buttonData(up)))
+					}
+					if len(row) != 0 {
+						rows = append(rows, row)
+					}
+				}
+				var presets []tgbotapi.InlineKeyboardButton
+				for _, v := range setpointValues(sp) {
+					presets = append(presets, tgbotapi.NewInlineKeyboardButtonData(pkgmodel.FormatValue(v), buttonData(v)))
+				}
+				rows = append(rows, presets)
+
+				var sb strings.Builder
+				sb.WriteString(setpointIcon)
+				sb.WriteString(fmt.Sprintf("%s\n", d.DeviceID))
+				sb.WriteString(l.T("setpoint.current", d.Value, sp.Unit))
+				sb.WriteString("\n")
+				sb.WriteString(setpointText(l, sp))
+				sb.WriteString("\n")
+				sb.WriteString(l.N("setpoint.input", int(setpointInputTTL/time.Minute)))
+				msg.Text = sb.String()
+			}
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(back))
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		} else {
+			msg.Text = l.T("error.unknown_user")
+		}
+
+		sent, err := botApi.Send(msg)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		userService.SetUserLastMessage(username, sent)
+	}
+}
+
+// SetValueHandler :setValue - отправка выбранного значения уставки устройству
+// параметр hub - идентификатор хаба
+// параметр deviceID - идентификатор устройства
+// параметр value - значение в единицах устройства, диапазон и шаг проверяются клиентским приложением хаба
+func SetValueHandler(
+	ctx context.Context,
+	logger zerolog.Logger,
+	userService users.UserService,
+	policyService devices.PolicyService,
+) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		callback := tgbotapi.NewCallback(update.CallbackQuery.ID, update.CallbackQuery.Data)
+		if _, err := botApi.Request(callback); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+
+		username := update.CallbackQuery.From.UserName
+		chatID := update.CallbackQuery.Message.Chat.ID
+		l := userService.Localizer(ctx, update.CallbackQuery.From)
+		if prevMsg, ok := userService.GetUserLastMessage(username, chatID); ok {
+			delMsg := tgbotapi.NewDeleteMessage(chatID, prevMsg.MessageID)
+			_, _ = botApi.Send(delMsg)
+		}
+
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		if userService.IsUserExists(ctx, username) {
+			reqParams := ParseReqParams(update.CallbackQuery.Data)
+			hubID, _ := strconv.ParseInt(reqParams.Get("hub"), 10, 64)
+			value, err := strconv.ParseFloat(reqParams.Get("value"), 64)
+			if err != nil {
+				msg.Text = l.T("error.invalid_argument", reqParams.Get("value"))
+			} else {
+				msg.Text, msg.ReplyMarkup = deviceActionReply(ctx, logger, l, policyService, devices.ActionRequest{
+					TGUser:   username,
+					ChatID:   chatID,
+					HubID:    hubID,
+					DeviceID: reqParams.Get("deviceID"),
+					Action:   pkgmodel.SetValue,
+					Value:    &value,
+					Source:   devices.SourceBot,
+				})
+			}
+		} else {
+			msg.Text = l.T("error.unknown_user")
+		}
+
+		sent, err := botApi.Send(msg)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		userService.SetUserLastMessage(username, sent)
+	}
+}
+
+// SetHandler /set - ввод значения уставки для устройства из последнего открытого меню уставки
+// /set 21.5 - значение проверяется по диапазону и шагу уставки до отправки на хаб
+func SetHandler(
+	ctx context.Context,
+	logger zerolog.Logger,
+	userService users.UserService,
+	policyService devices.PolicyService,
+	inputs *SetpointInputs,
+) func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+	return func(update tgbotapi.Update, botApi *tgbotapi.BotAPI) {
+		username := update.Message.From.UserName
+		chatID := update.Message.Chat.ID
+		l := userService.Localizer(ctx, update.Message.From)
+		if prevMsg, ok := userService.GetUserLastMessage(username, chatID); ok {
+			delMsg := tgbotapi.NewDeleteMessage(chatID, prevMsg.MessageID)
+			_, _ = botApi.Send(delMsg)
+		}
+
+		msg := tgbotapi.NewMessage(chatID, l.T("error.unknown"))
+		arg := strings.TrimSpace(update.Message.CommandArguments())
+		input, ok := inputs.Get(username)
+		if ok && time.Now().After(input.expiresAt) {
+			inputs.Take(username)
+			ok = false
+		}
+		switch {
+		case !userService.IsUserExists(ctx, username):
+			msg.Text = l.T("error.unknown_user")
+		case len(arg) == 0:
+			msg.Text = l.T("setpoint.usage")
+		case !ok:
+			msg.Text = l.T("setpoint.no_input")
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(l.T("menu.setpoints"), "handler:setpoints"),
+			))
+		default:
+			back := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(l.T("button.back"), fmt.Sprintf("handler:setpointMenu?hub=%d&deviceID=%s", input.hubID, input.deviceID)),
+			))
+			// десятичный разделитель может быть введен запятой
+			value, err := strconv.ParseFloat(strings.ReplaceAll(arg, ",", "."), 64)
+			if err != nil {
+				msg.Text = l.T("error.invalid_argument", arg)
+				msg.ReplyMarkup = back
+				break
+			}
+			if err := input.setpoint.Check(value); err != nil {
+				msg.Text = setpointError(l, input.setpoint, err)
+				msg.ReplyMarkup = back
+				break
+			}
+			msg.Text, msg.ReplyMarkup = deviceActionReply(ctx, logger, l, policyService, devices.ActionRequest{
+				TGUser:   username,
+				ChatID:   chatID,
+				HubID:    input.hubID,
+				DeviceID: input.deviceID,
+				Action:   pkgmodel.SetValue,
+				Value:    &value,
+				Source:   devices.SourceBot,
+			})
+		}
+
+		sent, err := botApi.Send(msg)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		userService.SetUserLastMessage(username, sent)
+	}
+}
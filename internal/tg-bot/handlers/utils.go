@@ -1,6 +1,14 @@
 package handlers
 
-import "net/url"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
 
 // ParseReqHandler достает из строки имя обработчика команды telegram
 func ParseReqHandler(reqURL string) string {
@@ -12,8 +20,8 @@ func ParseReqHandler(reqURL string) string {
 }
 
 // ParseReqParams достает из строки параметры запроса
-func ParseReqParams(reqURL string) map[string][]string {
-	empty := make(map[string][]string)
+func ParseReqParams(reqURL string) url.Values {
+	empty := make(url.Values)
 	reqUrl, err := url.Parse(reqURL)
 	if err != nil {
 		return empty
@@ -24,3 +32,72 @@ func ParseReqParams(reqURL string) map[string][]string {
 	}
 	return params
 }
+
+// isPrivateChat проверяет, что команда пришла из личного чата с ботом
+func isPrivateChat(update tgbotapi.Update) bool {
+	if update.Message != nil {
+		return update.Message.Chat.IsPrivate()
+	}
+	return update.CallbackQuery.Message.Chat.IsPrivate()
+}
+
+// updateFrom возвращает пользователя, отправившего команду
+func updateFrom(update tgbotapi.Update) *tgbotapi.User {
+	switch {
+	case update.Message != nil:
+		return update.Message.From
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From
+	case update.MyChatMember != nil:
+		return &update.MyChatMember.From
+	}
+	return nil
+}
+
+// SetMenuButton устанавливает кнопку меню, открывающую Mini App по адресу url, с надписью на языке l:
+// для чата chatID или кнопку по умолчанию, если chatID равен 0.
+// tgbotapi не поддерживает Mini App, поэтому запрос формируется вручную
+func SetMenuButton(botApi *tgbotapi.BotAPI, chatID int64, l i18n.Localizer, url string) error {
+	params := tgbotapi.Params{}
+	if chatID != 0 {
+		params.AddNonZero64("chat_id", chatID)
+	}
+	err := params.AddInterface("menu_button", map[string]any{
+		"type": "web_app",
+		"text": l.T("menu.webapp"),
+		"web_app": map[string]string{
+			"url": url,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = botApi.MakeRequest("setChatMenuButton", params)
+	return err
+}
+
+// lines объединяет тексты сообщений каталога построчно
+func lines(texts ...string) string {
+	return strings.Join(texts, "\n")
+}
+
+// errNotEnoughArgs команде передано недостаточно аргументов, пользователю показывается подсказка по использованию
+var errNotEnoughArgs = errors.New("handler: not enough arguments")
+
+// argError ошибка разбора аргумента команды, пользователю показывается только сам аргумент
+type argError struct {
+	arg string
+}
+
+func (e argError) Error() string {
+	return fmt.Sprintf("handler: invalid argument <%s>", e.arg)
+}
+
+// argErrorText текст ответа пользователю для ошибки разбора аргументов команды
+func argErrorText(l i18n.Localizer, err error) string {
+	var argErr argError
+	if errors.As(err, &argErr) {
+		return l.T("error.invalid_argument", argErr.arg)
+	}
+	return l.T("error.generic", err)
+}
@@ -0,0 +1,55 @@
+package hubs
+
+import (
+	"time"
+
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// Hub клиентское приложение (хаб)
+type Hub struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+// Access хаб, доступный пользователю, и роль пользователя в нем
+type Access struct {
+	Hub
+	Role pkgmodel.Role `db:"role"`
+}
+
+// Member участник хаба
+type Member struct {
+	UserID int64         `db:"user_id"`
+	TGUser string        `db:"tg_user"`
+	Role   pkgmodel.Role `db:"role"`
+}
+
+// Invitation приглашение в хаб, действует до ExpiresAt и может быть использовано один раз
+type Invitation struct {
+	Code      string        `db:"code"`
+	HubID     int64         `db:"client_id"`
+	HubName   string        `db:"client_name"`
+	Role      pkgmodel.Role `db:"role"`
+	CreatedBy int64         `db:"created_by"`
+	ExpiresAt time.Time     `db:"expires_at"`
+}
+
+// Subscription подписка чата telegram на уведомления хаба
+type Subscription struct {
+	HubID   int64  `db:"client_id"`
+	HubName string `db:"client_name"`
+	ChatID  int64  `db:"chat_id"`
+	// Title название группы или имя пользователя для личного чата
+	Title     string    `db:"title"`
+	AddedBy   int64     `db:"added_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Recipient получатель уведомлений хаба
+// для подписанных групп UserID - пользователь, добавивший подписку, его настройки уведомлений применяются к группе
+type Recipient struct {
+	UserID int64         `db:"user_id"`
+	ChatID int64         `db:"chat_id"`
+	Role   pkgmodel.Role `db:"role"`
+}
@@ -0,0 +1,278 @@
+package hubs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/repository"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// HubRepository описывает методы работы с хабами, их участниками и подписками чатов на уведомления
+type HubRepository interface {
+	// FindHubByName поиск хаба по имени
+	FindHubByName(ctx context.Context, name string) (Hub, error)
+	// FindHubByID поиск хаба по идентификатору
+	FindHubByID(ctx context.Context, id int64) (Hub, error)
+	// FindHubByClientID поиск хаба по идентификатору клиента
+	FindHubByClientID(ctx context.Context, clientID string) (Hub, error)
+	// FindRole возвращает роль пользователя в хабе
+	FindRole(ctx context.Context, tgUser string, hubID int64) (pkgmodel.Role, error)
+	// FindHubsByTGUser возвращает хабы, участником которых является пользователь
+	FindHubsByTGUser(ctx context.Context, tgUser string) ([]Access, error)
+	// FindMembers возвращает участников хаба
+	FindMembers(ctx context.Context, hubID int64) ([]Member, error)
+	// UpdateMemberRole изменяет роль участника хаба
+	UpdateMemberRole(ctx context.Context, hubID int64, userID int64, role pkgmodel.Role) error
+	// DeleteMember удаляет участника хаба
+	DeleteMember(ctx context.Context, hubID int64, userID int64) error
+	// CountOwners возвращает количество владельцев хаба
+	CountOwners(ctx context.Context, hubID int64) (int, error)
+	// SaveInvitation сохраняет приглашение в хаб
+	SaveInvitation(ctx context.Context, inv Invitation) error
+	// AcceptInvitation добавляет пользователя в хаб по действующему приглашению и удаляет приглашение
+	AcceptInvitation(ctx context.Context, code string, userID int64, now time.Time) (Invitation, error)
+	// SaveSubscription сохраняет подписку чата, повторная подписка обновляет название чата
+	SaveSubscription(ctx context.Context, s Subscription) error
+	// DeleteSubscription удаляет подписку чата на хаб
+	DeleteSubscription(ctx context.Context, hubID int64, chatID int64) error
+	// DeleteSubscriptionsByChatID удаляет все подписки чата
+	DeleteSubscriptionsByChatID(ctx context.Context, chatID int64) error
+	// FindSubscriptionsByHubID возвращает подписки чатов на хаб
+	FindSubscriptionsByHubID(ctx context.Context, hubID int64) ([]Subscription, error)
+	// FindRecipientsByHubID возвращает участников хаба с включенными уведомлениями и подписанные чаты
+	FindRecipientsByHubID(ctx context.Context, hubID int64) ([]Recipient, error)
+}
+
+type SQLHubRepo struct {
+	db *sqlx.DB
+}
+
+func (r SQLHubRepo) findHub(ctx context.Context, sqlQuery string, arg any) (Hub, error) {
+	hub := Hub{}
+	err := r.db.GetContext(ctx, &hub, sqlQuery, arg)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Hub{}, repository.ErrNotFound
+		}
+		return Hub{}, err
+	}
+
+	return hub, nil
+}
+
+func (r SQLHubRepo) FindHubByName(ctx context.Context, name string) (Hub, error) {
+	return r.findHub(ctx, `SELECT id, name FROM clients WHERE name = $1`, name)
+}
+
+func (r SQLHubRepo) FindHubByID(ctx context.Context, id int64) (Hub, error) {
+	return r.findHub(ctx, `SELECT id, name FROM clients WHERE id = $1`, id)
+}
+
+func (r SQLHubRepo) FindHubByClientID(ctx context.Context, clientID string) (Hub, error) {
+	return r.findHub(ctx, `SELECT id, name FROM clients WHERE uuid = $1`, clientID)
+}
+
+func (r SQLHubRepo) FindRole(ctx context.Context, tgUser string, hubID int64) (pkgmodel.Role, error) {
+	const sqlQuery = `SELECT m.role FROM hub_members m JOIN users u ON u.id = m.user_id
+		WHERE u.tg_user = $1 AND m.client_id = $2`
+
+	var role pkgmodel.Role
+	err := r.db.GetContext(ctx, &role, sqlQuery, tgUser, hubID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", repository.ErrNotFound
+		}
+		return "", err
+	}
+
+	return role, nil
+}
+
+func (r SQLHubRepo) FindHubsByTGUser(ctx context.Context, tgUser string) ([]Access, error) {
+	const sqlQuery = `SELECT c.id, c.name, m.role
+		FROM hub_members m
+		         JOIN users u ON u.id = m.user_id
+		         JOIN clients c ON c.id = m.client_id
+		WHERE u.tg_user = $1
+		ORDER BY c.name`
+
+	result := make([]Access, 0)
+	err := r.db.SelectContext(ctx, &result, sqlQuery, tgUser)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r SQLHubRepo) FindMembers(ctx context.Context, hubID int64) ([]Member, error) {
+	const sqlQuery = `SELECT m.user_id, u.tg_user, m.role
+		FROM hub_members m JOIN users u ON u.id = m.user_id
+		WHERE m.client_id = $1
+		ORDER BY m.created_at`
+
+	result := make([]Member, 0)
+	err := r.db.SelectContext(ctx, &result, sqlQuery, hubID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r SQLHubRepo) UpdateMemberRole(ctx context.Context, hubID int64, userID int64, role pkgmodel.Role) error {
+	const sqlQuery = `UPDATE hub_members SET role = $3 WHERE client_id = $1 AND user_id = $2`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, hubID, userID, role)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r SQLHubRepo) DeleteMember(ctx context.Context, hubID int64, userID int64) error {
+	const sqlQuery = `DELETE FROM hub_members WHERE client_id = $1 AND user_id = $2`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, hubID, userID)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r SQLHubRepo) CountOwners(ctx context.Context, hubID int64) (int, error) {
+	const sqlQuery = `SELECT count(*) FROM hub_members WHERE client_id = $1 AND role = $2`
+
+	var count int
+	err := r.db.GetContext(ctx, &count, sqlQuery, hubID, pkgmodel.RoleOwner)
+	return count, err
+}
+
+func (r SQLHubRepo) SaveInvitation(ctx context.Context, inv Invitation) error {
+	const sqlQuery = `INSERT INTO hub_invitations(code, client_id, role, created_by, expires_at)
+		VALUES(:code, :client_id, :role, :created_by, :expires_at)`
+
+	_, err := r.db.NamedExecContext(ctx, sqlQuery, inv)
+	return err
+}
+
+func (r SQLHubRepo) AcceptInvitation(ctx context.Context, code string, userID int64, now time.Time) (Invitation, error) {
+	const takeQuery = `DELETE FROM hub_invitations i USING clients c
+		WHERE c.id = i.client_id AND i.code = $1 AND i.expires_at > $2
+		RETURNING i.code, i.client_id, c.name AS client_name, i.role, i.created_by, i.expires_at`
+	const memberQuery = `INSERT INTO hub_members(client_id, user_id, role) VALUES($1, $2, $3)`
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return Invitation{}, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	inv := Invitation{}
+	if err := tx.GetContext(ctx, &inv, takeQuery, code, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Invitation{}, repository.ErrNotFound
+		}
+		return Invitation{}, err
+	}
+	if _, err := tx.ExecContext(ctx, memberQuery, inv.HubID, userID, inv.Role); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return Invitation{}, repository.ErrAlreadyExists
+		}
+		return Invitation{}, err
+	}
+
+	return inv, tx.Commit()
+}
+
+func (r SQLHubRepo) SaveSubscription(ctx context.Context, s Subscription) error {
+	const sqlQuery = `INSERT INTO hub_subscriptions(client_id, chat_id, title, added_by)
+		VALUES($1, $2, $3, $4)
+		ON CONFLICT (client_id, chat_id) DO UPDATE SET title = excluded.title, added_by = excluded.added_by`
+
+	_, err := r.db.ExecContext(ctx, sqlQuery, s.HubID, s.ChatID, s.Title, s.AddedBy)
+	return err
+}
+
+func (r SQLHubRepo) DeleteSubscription(ctx context.Context, hubID int64, chatID int64) error {
+	const sqlQuery = `DELETE FROM hub_subscriptions WHERE client_id = $1 AND chat_id = $2`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, hubID, chatID)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r SQLHubRepo) DeleteSubscriptionsByChatID(ctx context.Context, chatID int64) error {
+	const sqlQuery = `DELETE FROM hub_subscriptions WHERE chat_id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlQuery, chatID)
+	return err
+}
+
+func (r SQLHubRepo) FindSubscriptionsByHubID(ctx context.Context, hubID int64) ([]Subscription, error) {
+	const sqlQuery = `SELECT s.client_id, c.name AS client_name, s.chat_id, s.title, s.added_by, s.created_at
+		FROM hub_subscriptions s JOIN clients c ON c.id = s.client_id
+		WHERE s.client_id = $1
+		ORDER BY s.created_at`
+
+	result := make([]Subscription, 0)
+	err := r.db.SelectContext(ctx, &result, sqlQuery, hubID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r SQLHubRepo) FindRecipientsByHubID(ctx context.Context, hubID int64) ([]Recipient, error) {
+	// Сначала личные чаты участников, затем подписанные группы.
+	// Подписка группы действует, пока добавивший ее пользователь остается участником хаба
+	const sqlQuery = `SELECT user_id, chat_id, role FROM (
+			SELECT 0 AS kind, m.user_id, u.chat_id, m.role, m.created_at
+			FROM hub_members m JOIN users u ON u.id = m.user_id
+			WHERE m.client_id = $1 AND u.notify_enabled AND u.chat_id IS NOT NULL
+			UNION ALL
+			SELECT 1 AS kind, s.added_by, s.chat_id, m.role, s.created_at
+			FROM hub_subscriptions s JOIN hub_members m ON m.client_id = s.client_id AND m.user_id = s.added_by
+			WHERE s.client_id = $1
+		) r ORDER BY kind, created_at`
+
+	result := make([]Recipient, 0)
+	err := r.db.SelectContext(ctx, &result, sqlQuery, hubID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func NewRepo(db *sqlx.DB) SQLHubRepo {
+	return SQLHubRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,317 @@
+package hubs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/repository"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/users"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// InvitationTTL время действия приглашения в хаб
+const InvitationTTL = 24 * time.Hour
+
+var (
+	ErrForbidden = errors.New("hubs: access denied")
+	ErrLastOwner = errors.New("hubs: hub must have at least one owner")
+)
+
+// HubService сервис доступа к хабам: роли участников, приглашения и подписки чатов на уведомления
+type HubService interface {
+	// Authorize проверяет, что пользователю разрешено действие с хабом
+	Authorize(ctx context.Context, tgName string, hubName string, perm pkgmodel.Permission) (Hub, error)
+	// AuthorizeByID проверяет, что пользователю разрешено действие с хабом
+	AuthorizeByID(ctx context.Context, tgName string, hubID int64, perm pkgmodel.Permission) (Hub, error)
+	// FindAccess возвращает хаб и роль пользователя в нем
+	FindAccess(ctx context.Context, tgName string, hubID int64) (Access, error)
+	// FindHubs возвращает хабы, для которых пользователю разрешено действие
+	FindHubs(ctx context.Context, tgName string, perm pkgmodel.Permission) ([]Access, error)
+	// FindHubByClientID поиск хаба по идентификатору клиента
+	FindHubByClientID(ctx context.Context, clientID string) (Hub, error)
+	// Recipients возвращает получателей уведомлений хаба без повторов чатов
+	Recipients(ctx context.Context, hubID int64) ([]Recipient, error)
+	// Invite создает приглашение в хаб с указанной ролью
+	Invite(ctx context.Context, tgName string, hubName string, role pkgmodel.Role) (Invitation, error)
+	// Join добавляет пользователя в хаб по коду приглашения
+	Join(ctx context.Context, tgName string, code string) (Invitation, error)
+	// FindMembers возвращает участников хаба
+	FindMembers(ctx context.Context, tgName string, hubID int64) (Hub, []Member, error)
+	// SetRole изменяет роль участника хаба
+	SetRole(ctx context.Context, tgName string, hubID int64, userID int64, role pkgmodel.Role) error
+	// RemoveMember удаляет участника хаба, участник может покинуть хаб сам
+	RemoveMember(ctx context.Context, tgName string, hubID int64, userID int64) error
+	// Subscribe подписывает чат на уведомления хаба
+	Subscribe(ctx context.Context, tgName string, hubName string, chatID int64, title string) error
+	// Unsubscribe отменяет подписку чата на уведомления хаба
+	Unsubscribe(ctx context.Context, tgName string, hubName string, chatID int64) error
+	// FindSubscriptions возвращает подписки на хабы, которыми управляет пользователь
+	FindSubscriptions(ctx context.Context, tgName string) ([]Subscription, error)
+	// DeleteChat удаляет все подписки чата, например, когда бота удалили из группы
+	DeleteChat(ctx context.Context, chatID int64) error
+}
+
+type HubServiceImpl struct {
+	hubRepo     HubRepository
+	userService users.UserService
+}
+
+func (s HubServiceImpl) Authorize(ctx context.Context, tgName string, hubName string, perm pkgmodel.Permission) (Hub, error) {
+	hub, err := s.hubRepo.FindHubByName(ctx, hubName)
+	if err != nil {
+		return Hub{}, fmt.Errorf("find hub: %w", err)
+	}
+	return s.authorize(ctx, tgName, hub, perm)
+}
+
+func (s HubServiceImpl) AuthorizeByID(ctx context.Context, tgName string, hubID int64, perm pkgmodel.Permission) (Hub, error) {
+	hub, err := s.hubRepo.FindHubByID(ctx, hubID)
+	if err != nil {
+		return Hub{}, fmt.Errorf("find hub: %w", err)
+	}
+	return s.authorize(ctx, tgName, hub, perm)
+}
+
+func (s HubServiceImpl) authorize(ctx context.Context, tgName string, hub Hub, perm pkgmodel.Permission) (Hub, error) {
+	role, err := s.hubRepo.FindRole(ctx, tgName, hub.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return Hub{}, ErrForbidden
+		}
+		return Hub{}, fmt.Errorf("find role: %w", err)
+	}
+	if !role.Can(perm) {
+		return Hub{}, ErrForbidden
+	}
+	return hub, nil
+}
+
+func (s HubServiceImpl) FindAccess(ctx context.Context, tgName string, hubID int64) (Access, error) {
+	hub, err := s.hubRepo.FindHubByID(ctx, hubID)
+	if err != nil {
+		return Access{}, fmt.Errorf("find hub: %w", err)
+	}
+	role, err := s.hubRepo.FindRole(ctx, tgName, hub.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return Access{}, ErrForbidden
+		}
+		return Access{}, fmt.Errorf("find role: %w", err)
+	}
+	return Access{Hub: hub, Role: role}, nil
+}
+
+func (s HubServiceImpl) FindHubs(ctx context.Context, tgName string, perm pkgmodel.Permission) ([]Access, error) {
+	all, err := s.hubRepo.FindHubsByTGUser(ctx, tgName)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Access, 0, len(all))
+	for _, a := range all {
+		if a.Role.Can(perm) {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (s HubServiceImpl) FindHubByClientID(ctx context.Context, clientID string) (Hub, error) {
+	return s.hubRepo.FindHubByClientID(ctx, clientID)
+}
+
+func (s HubServiceImpl) Recipients(ctx context.Context, hubID int64) ([]Recipient, error) {
+	all, err := s.hubRepo.FindRecipientsByHubID(ctx, hubID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int64]bool, len(all))
+	result := make([]Recipient, 0, len(all))
+	for _, r := range all {
+		if seen[r.ChatID] || !r.Role.Can(pkgmodel.ReceiveNotifications) {
+			continue
+		}
+		seen[r.ChatID] = true
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// newInvitationCode генерирует случайный код приглашения
+func newInvitationCode() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s HubServiceImpl) Invite(ctx context.Context, tgName string, hubName string, role pkgmodel.Role) (Invitation, error) {
+	hub, err := s.Authorize(ctx, tgName, hubName, pkgmodel.ManageHub)
+	if err != nil {
+		return Invitation{}, err
+	}
+	user, err := s.userService.FindUserByTGName(ctx, tgName)
+	if err != nil {
+		return Invitation{}, fmt.Errorf("find user: %w", err)
+	}
+	code, err := newInvitationCode()
+	if err != nil {
+		return Invitation{}, fmt.Errorf("generate invitation code: %w", err)
+	}
+
+	inv := Invitation{
+		Code:      code,
+		HubID:     hub.ID,
+		HubName:   hub.Name,
+		Role:      role,
+		CreatedBy: user.ID,
+		ExpiresAt: time.Now().Add(InvitationTTL),
+	}
+	if err := s.hubRepo.SaveInvitation(ctx, inv); err != nil {
+		return Invitation{}, fmt.Errorf("save invitation: %w", err)
+	}
+	return inv, nil
+}
+
+func (s HubServiceImpl) Join(ctx context.Context, tgName string, code string) (Invitation, error) {
+	user, err := s.userService.FindUserByTGName(ctx, tgName)
+	if err != nil {
+		return Invitation{}, fmt.Errorf("find user: %w", err)
+	}
+	inv, err := s.hubRepo.AcceptInvitation(ctx, code, user.ID, time.Now())
+	if err != nil {
+		return Invitation{}, fmt.Errorf("accept invitation: %w", err)
+	}
+	return inv, nil
+}
+
+func (s HubServiceImpl) FindMembers(ctx context.Context, tgName string, hubID int64) (Hub, []Member, error) {
+	hub, err := s.AuthorizeByID(ctx, tgName, hubID, pkgmodel.ManageHub)
+	if err != nil {
+		return Hub{}, nil, err
+	}
+	members, err := s.hubRepo.FindMembers(ctx, hub.ID)
+	if err != nil {
+		return Hub{}, nil, err
+	}
+	return hub, members, nil
+}
+
+// checkOwners не дает удалить или понизить последнего владельца хаба
+func (s HubServiceImpl) checkOwners(ctx context.Context, hubID int64, userID int64) error {
+	role, err := s.memberRole(ctx, hubID, userID)
+	if err != nil {
+		return err
+	}
+	if role != pkgmodel.RoleOwner {
+		return nil
+	}
+	owners, err := s.hubRepo.CountOwners(ctx, hubID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// memberRole возвращает текущую роль участника хаба
+func (s HubServiceImpl) memberRole(ctx context.Context, hubID int64, userID int64) (pkgmodel.Role, error) {
+	members, err := s.hubRepo.FindMembers(ctx, hubID)
+	if err != nil {
+		return "", err
+	}
+	for _, m := range members {
+		if m.UserID == userID {
+			return m.Role, nil
+		}
+	}
+	return "", repository.ErrNotFound
+}
+
+func (s HubServiceImpl) SetRole(ctx context.Context, tgName string, hubID int64, userID int64, role pkgmodel.Role) error {
+	hub, err := s.AuthorizeByID(ctx, tgName, hubID, pkgmodel.ManageHub)
+	if err != nil {
+		return err
+	}
+	if role != pkgmodel.RoleOwner {
+		if err := s.checkOwners(ctx, hub.ID, userID); err != nil {
+			return err
+		}
+	}
+	return s.hubRepo.UpdateMemberRole(ctx, hub.ID, userID, role)
+}
+
+func (s HubServiceImpl) RemoveMember(ctx context.Context, tgName string, hubID int64, userID int64) error {
+	user, err := s.userService.FindUserByTGName(ctx, tgName)
+	if err != nil {
+		return fmt.Errorf("find user: %w", err)
+	}
+	if user.ID != userID {
+		if _, err := s.AuthorizeByID(ctx, tgName, hubID, pkgmodel.ManageHub); err != nil {
+			return err
+		}
+	}
+	if err := s.checkOwners(ctx, hubID, userID); err != nil {
+		return err
+	}
+	return s.hubRepo.DeleteMember(ctx, hubID, userID)
+}
+
+func (s HubServiceImpl) Subscribe(ctx context.Context, tgName string, hubName string, chatID int64, title string) error {
+	hub, err := s.Authorize(ctx, tgName, hubName, pkgmodel.ManageHub)
+	if err != nil {
+		return err
+	}
+	user, err := s.userService.FindUserByTGName(ctx, tgName)
+	if err != nil {
+		return fmt.Errorf("find user: %w", err)
+	}
+	return s.hubRepo.SaveSubscription(ctx, Subscription{
+		HubID:   hub.ID,
+		ChatID:  chatID,
+		Title:   title,
+		AddedBy: user.ID,
+	})
+}
+
+func (s HubServiceImpl) Unsubscribe(ctx context.Context, tgName string, hubName string, chatID int64) error {
+	hub, err := s.Authorize(ctx, tgName, hubName, pkgmodel.ManageHub)
+	if err != nil {
+		return err
+	}
+	return s.hubRepo.DeleteSubscription(ctx, hub.ID, chatID)
+}
+
+func (s HubServiceImpl) FindSubscriptions(ctx context.Context, tgName string) ([]Subscription, error) {
+	managed, err := s.FindHubs(ctx, tgName, pkgmodel.ManageHub)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Subscription, 0)
+	for _, hub := range managed {
+		list, err := s.hubRepo.FindSubscriptionsByHubID(ctx, hub.ID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, list...)
+	}
+	return result, nil
+}
+
+func (s HubServiceImpl) DeleteChat(ctx context.Context, chatID int64) error {
+	return s.hubRepo.DeleteSubscriptionsByChatID(ctx, chatID)
+}
+
+// NewHubService создает сервис хабов
+func NewHubService(hubRepo HubRepository, userService users.UserService) HubServiceImpl {
+	return HubServiceImpl{
+		hubRepo:     hubRepo,
+		userService: userService,
+	}
+}
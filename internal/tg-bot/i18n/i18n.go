@@ -0,0 +1,126 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Lang язык сообщений бота
+type Lang string
+
+const (
+	RU Lang = "ru"
+	EN Lang = "en"
+	// Default язык, используемый, если язык пользователя не поддерживается
+	Default = RU
+)
+
+// Langs поддерживаемые языки в порядке вывода пользователю
+var Langs = []Lang{RU, EN}
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// catalogs каталоги сообщений по языкам, загружаются из locales при старте
+var catalogs = mustLoad()
+
+// Message сообщение каталога, для сообщений с числом хранит формы множественного числа
+// в файле каталога задается строкой или объектом {"one": ..., "few": ..., "many": ..., "other": ...}
+type Message map[Form]string
+
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*m = Message{Other: text}
+		return nil
+	}
+	forms := map[Form]string{}
+	if err := json.Unmarshal(data, &forms); err != nil {
+		return err
+	}
+	if len(forms) == 0 {
+		return fmt.Errorf("i18n: plural message has no forms")
+	}
+	*m = forms
+	return nil
+}
+
+// Parse возвращает поддерживаемый язык по коду, коды вида en-US сводятся к en
+func Parse(code string) (Lang, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if base, _, ok := strings.Cut(code, "-"); ok {
+		code = base
+	}
+	if _, ok := catalogs[Lang(code)]; !ok {
+		return "", false
+	}
+	return Lang(code), true
+}
+
+// Localizer формирует тексты сообщений на языке пользователя
+type Localizer struct {
+	lang Lang
+}
+
+// Lang язык локализатора
+func (l Localizer) Lang() Lang {
+	return l.lang
+}
+
+// T возвращает сообщение по ключу, args подставляются в текст как в fmt.Sprintf
+func (l Localizer) T(key string, args ...any) string {
+	return l.format(key, Other, args)
+}
+
+// N возвращает сообщение по ключу в форме множественного числа для n
+// n передается в текст первым аргументом, за ним следуют args
+func (l Localizer) N(key string, n int, args ...any) string {
+	return l.format(key, plural(l.lang, n), append([]any{n}, args...))
+}
+
+func (l Localizer) format(key string, form Form, args []any) string {
+	msg, ok := catalogs[l.lang][key]
+	if !ok {
+		msg, ok = catalogs[Default][key]
+	}
+	if !ok {
+		return key
+	}
+	text, ok := msg[form]
+	if !ok {
+		text, ok = msg[Other]
+	}
+	if !ok {
+		text = msg[Many]
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// New создает локализатор для языка, неподдерживаемый язык заменяется языком по умолчанию
+func New(lang Lang) Localizer {
+	if _, ok := catalogs[lang]; !ok {
+		lang = Default
+	}
+	return Localizer{lang: lang}
+}
+
+func mustLoad() map[Lang]map[string]Message {
+	result := make(map[Lang]map[string]Message)
+	for _, lang := range Langs {
+		data, err := locales.ReadFile("locales/" + string(lang) + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: catalog <%s> not found: %v", lang, err))
+		}
+		catalog := make(map[string]Message)
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog <%s>: %v", lang, err))
+		}
+		result[lang] = catalog
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package i18n
+
+// Form форма множественного числа по правилам CLDR
+type Form string
+
+const (
+	One   Form = "one"
+	Few   Form = "few"
+	Many  Form = "many"
+	Other Form = "other"
+)
+
+// plural возвращает форму множественного числа для n на указанном языке
+func plural(lang Lang, n int) Form {
+	if n < 0 {
+		n = -n
+	}
+	switch lang {
+	case RU:
+		mod10, mod100 := n%10, n%100
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return One
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return Few
+		default:
+			return Many
+		}
+	default:
+		if n == 1 {
+			return One
+		}
+		return Other
+	}
+}
@@ -0,0 +1,101 @@
+package inbound
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	"github.com/labstack/echo/v4"
+)
+
+const tokenContextKey = "inboundToken"
+
+// TokenAuth middleware проверяет токен из заголовка Authorization: Bearer <token>
+func TokenAuth(service InboundService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			raw, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Missing token")
+			}
+
+			token, err := service.Authenticate(c.Request().Context(), strings.TrimSpace(raw))
+			if err != nil {
+				switch {
+				case errors.Is(err, ErrInvalidToken):
+					return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token")
+				case errors.Is(err, hubs.ErrForbidden):
+					return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+				}
+				c.Logger().Error(err)
+				return echo.ErrInternalServerError
+			}
+
+			c.Set(tokenContextKey, token)
+			return next(c)
+		}
+	}
+}
+
+// ReceiveResponse результат приема оповещений
+type ReceiveResponse struct {
+	Received  int `json:"received"`
+	Delivered int `json:"delivered"`
+}
+
+func receive(c echo.Context, service InboundService, alerts []Alert) error {
+	token := c.Get(tokenContextKey).(Token)
+	delivered, err := service.Receive(c.Request().Context(), token, alerts)
+	if err != nil {
+		c.Logger().Error(err)
+		return echo.ErrInternalServerError
+	}
+
+	return c.JSON(http.StatusAccepted, ReceiveResponse{
+		Received:  len(alerts),
+		Delivered: delivered,
+	})
+}
+
+// ReceiveGeneric POST /webhooks/alerts - оповещение в общем формате GenericAlert
+// повторный firing и resolved без firing с тем же alert_id отбрасываются
+func ReceiveGeneric(service InboundService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		req := GenericAlert{}
+		if err := c.Bind(&req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		if err := c.Validate(req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		return receive(c, service, []Alert{req.toAlert()})
+	}
+}
+
+// ReceiveAlertmanager POST /webhooks/alertmanager - webhook Alertmanager версии 4
+// оповещения объединяются по fingerprint
+func ReceiveAlertmanager(service InboundService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		req := AlertmanagerPayload{}
+		if err := c.Bind(&req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		if err := c.Validate(req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		alerts := make([]Alert, 0, len(req.Alerts))
+		for _, a := range req.Alerts {
+			alerts = append(alerts, a.toAlert())
+		}
+		return receive(c, service, alerts)
+	}
+}
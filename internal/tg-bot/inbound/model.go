@@ -0,0 +1,124 @@
+package inbound
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// Status состояние внешнего оповещения
+type Status string
+
+const (
+	StatusFiring   Status = "firing"
+	StatusResolved Status = "resolved"
+)
+
+// Token токен входящего webhook, уведомления доставляются получателям хаба токена
+type Token struct {
+	ID      int64  `db:"id"`
+	UserID  int64  `db:"user_id"`
+	TGUser  string `db:"tg_user"`
+	HubID   int64  `db:"client_id"`
+	HubName string `db:"client_name"`
+	Name    string `db:"name"`
+}
+
+// HashToken возвращает хэш токена, в БД хранится только он
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Alert внешнее оповещение, приведенное к общему виду
+type Alert struct {
+	// ID идентификатор оповещения во внешней системе, по нему объединяются firing и resolved
+	ID       string
+	Status   Status
+	Text     string
+	Severity pkgmodel.Severity
+}
+
+// GenericAlert тело запроса в общем формате
+// если status не указан, оповещение доставляется всегда, без учета состояния
+type GenericAlert struct {
+	AlertID  string `json:"alert_id" validate:"required_with=Status,max=128"`
+	Text     string `json:"text" validate:"required,max=4000"`
+	Severity string `json:"severity" validate:"omitempty,oneof=low normal critical"`
+	Status   string `json:"status" validate:"omitempty,oneof=firing resolved"`
+}
+
+func (a GenericAlert) toAlert() Alert {
+	severity, _ := pkgmodel.NewSeverity(a.Severity)
+	return Alert{
+		ID:       a.AlertID,
+		Status:   Status(a.Status),
+		Text:     a.Text,
+		Severity: severity,
+	}
+}
+
+// AlertmanagerPayload тело запроса webhook Alertmanager, версия 4
+type AlertmanagerPayload struct {
+	Version  string              `json:"version"`
+	GroupKey string              `json:"groupKey"`
+	Status   string              `json:"status"`
+	Receiver string              `json:"receiver"`
+	Alerts   []AlertmanagerAlert `json:"alerts" validate:"required,min=1,dive"`
+}
+
+// AlertmanagerAlert оповещение Alertmanager
+type AlertmanagerAlert struct {
+	Status       string            `json:"status" validate:"required,oneof=firing resolved"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// alertmanagerSeverity приводит метку severity Alertmanager к уровню важности уведомления
+func alertmanagerSeverity(label string) pkgmodel.Severity {
+	switch strings.ToLower(label) {
+	case "critical", "page", "error":
+		return pkgmodel.SeverityCritical
+	case "info", "none", "low":
+		return pkgmodel.SeverityLow
+	}
+	return pkgmodel.SeverityNormal
+}
+
+func (a AlertmanagerAlert) toAlert() Alert {
+	id := a.Fingerprint
+	if len(id) == 0 {
+		id = a.Labels["alertname"]
+	}
+
+	summary := a.Annotations["summary"]
+	if len(summary) == 0 {
+		summary = a.Annotations["description"]
+	}
+	mark := "\U0001F525 FIRING"
+	if Status(a.Status) == StatusResolved {
+		mark = "✅ RESOLVED"
+	}
+	text := fmt.Sprintf("%s: %s", mark, a.Labels["alertname"])
+	if instance := a.Labels["instance"]; len(instance) != 0 {
+		text += " (" + instance + ")"
+	}
+	if len(summary) != 0 {
+		text += "\n" + summary
+	}
+
+	return Alert{
+		ID:       "am:" + id,
+		Status:   Status(a.Status),
+		Text:     text,
+		Severity: alertmanagerSeverity(a.Labels["severity"]),
+	}
+}
@@ -0,0 +1,71 @@
+package inbound
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+// InboundRepository описывает методы работы с токенами и состоянием входящих оповещений
+type InboundRepository interface {
+	// FindTokenByHash возвращает токен вместе с владельцем и хабом
+	FindTokenByHash(ctx context.Context, hash string) (Token, error)
+	// Fire отмечает оповещение активным, возвращает false, если оно уже было активно
+	Fire(ctx context.Context, tokenID int64, alertID string) (bool, error)
+	// Resolve снимает активность оповещения, возвращает false, если оно не было активно
+	Resolve(ctx context.Context, tokenID int64, alertID string) (bool, error)
+}
+
+type SQLInboundRepo struct {
+	db *sqlx.DB
+}
+
+func (r SQLInboundRepo) FindTokenByHash(ctx context.Context, hash string) (Token, error) {
+	const sqlQuery = `SELECT t.id, t.user_id, u.tg_user, t.client_id, c.name AS client_name, t.name
+		FROM inbound_tokens t
+		         JOIN users u ON u.id = t.user_id
+		         JOIN clients c ON c.id = t.client_id
+		WHERE t.token_hash = $1`
+
+	token := Token{}
+	err := r.db.GetContext(ctx, &token, sqlQuery, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Token{}, repository.ErrNotFound
+		}
+		return Token{}, err
+	}
+
+	return token, nil
+}
+
+func (r SQLInboundRepo) Fire(ctx context.Context, tokenID int64, alertID string) (bool, error) {
+	const sqlQuery = `INSERT INTO inbound_alerts(token_id, alert_id) VALUES($1, $2) ON CONFLICT DO NOTHING`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, tokenID, alertID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n != 0, nil
+}
+
+func (r SQLInboundRepo) Resolve(ctx context.Context, tokenID int64, alertID string) (bool, error) {
+	const sqlQuery = `DELETE FROM inbound_alerts WHERE token_id = $1 AND alert_id = $2`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, tokenID, alertID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n != 0, nil
+}
+
+func NewRepo(db *sqlx.DB) SQLInboundRepo {
+	return SQLInboundRepo{
+		db: db,
+	}
+}
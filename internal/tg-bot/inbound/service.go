@@ -0,0 +1,94 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/repository"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrInvalidToken = errors.New("inbound: invalid token")
+)
+
+// InboundService сервис принимает внешние оповещения и передает их в рассылку уведомлений хаба
+type InboundService interface {
+	// Authenticate проверяет токен и право владельца отправлять уведомления в хаб
+	Authenticate(ctx context.Context, token string) (Token, error)
+	// Receive доставляет оповещения, повторные firing и resolved без firing отбрасываются
+	// возвращает количество доставленных оповещений
+	Receive(ctx context.Context, token Token, alerts []Alert) (int, error)
+}
+
+type InboundServiceImpl struct {
+	inboundRepo InboundRepository
+	hubService  hubs.HubService
+	notifier    model.HubNotifier
+	logger      zerolog.Logger
+}
+
+func (s InboundServiceImpl) Authenticate(ctx context.Context, token string) (Token, error) {
+	if len(token) == 0 {
+		return Token{}, ErrInvalidToken
+	}
+	t, err := s.inboundRepo.FindTokenByHash(ctx, HashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return Token{}, ErrInvalidToken
+		}
+		return Token{}, err
+	}
+	// доступ к хабу мог быть отозван после создания токена
+	if _, err := s.hubService.AuthorizeByID(ctx, t.TGUser, t.HubID, pkgmodel.SendActions); err != nil {
+		return Token{}, err
+	}
+	return t, nil
+}
+
+// changed проверяет, изменилось ли состояние оповещения
+func (s InboundServiceImpl) changed(ctx context.Context, token Token, alert Alert) (bool, error) {
+	switch alert.Status {
+	case StatusFiring:
+		return s.inboundRepo.Fire(ctx, token.ID, alert.ID)
+	case StatusResolved:
+		return s.inboundRepo.Resolve(ctx, token.ID, alert.ID)
+	}
+	return true, nil
+}
+
+func (s InboundServiceImpl) Receive(ctx context.Context, token Token, alerts []Alert) (int, error) {
+	hub := hubs.Hub{ID: token.HubID, Name: token.HubName}
+	delivered := 0
+	for _, alert := range alerts {
+		ok, err := s.changed(ctx, token, alert)
+		if err != nil {
+			return delivered, fmt.Errorf("inbound: alert state: %w", err)
+		}
+		if !ok {
+			s.logger.Debug().Str("alertID", alert.ID).Str("status", string(alert.Status)).Msg("inbound: duplicate alert skipped")
+			continue
+		}
+		s.notifier.Notify(ctx, hub, pkgmodel.NotifyEvent{
+			AlertID:  alert.ID,
+			Text:     alert.Text,
+			Severity: alert.Severity,
+		})
+		delivered++
+	}
+	return delivered, nil
+}
+
+// NewInboundService создает сервис входящих оповещений
+func NewInboundService(inboundRepo InboundRepository, hubService hubs.HubService, notifier model.HubNotifier, logger zerolog.Logger) InboundServiceImpl {
+	return InboundServiceImpl{
+		inboundRepo: inboundRepo,
+		hubService:  hubService,
+		notifier:    notifier,
+		logger:      logger,
+	}
+}
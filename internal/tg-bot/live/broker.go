@@ -0,0 +1,113 @@
+package live
+
+import (
+	"sync"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
+	"github.com/rs/zerolog"
+)
+
+// Subscription подписка на события хабов, канал C закрывается при отписке
+// или если подписчик не успевает читать события
+type Subscription struct {
+	C      chan Event
+	filter Filter
+}
+
+// Broker хранит последние события хабов и рассылает их подписчикам
+type Broker struct {
+	mu sync.Mutex
+	// events кольцевой буфер последних событий
+	events []Event
+	start  int
+	count  int
+	// nextID номер следующего события, начинается с текущего времени,
+	// чтобы номера не повторялись после перезапуска бота
+	nextID    uint64
+	subs      map[*Subscription]struct{}
+	subBuffer int
+	logger    zerolog.Logger
+}
+
+// Publish сохраняет событие в буфере и передает подписчикам без блокировки отправителя
+func (b *Broker) Publish(e model.HubEvent) {
+	event := newEvent(e)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	event.ID = b.nextID
+	b.nextID++
+	if b.count < len(b.events) {
+		b.events[(b.start+b.count)%len(b.events)] = event
+		b.count++
+	} else {
+		b.events[b.start] = event
+		b.start = (b.start + 1) % len(b.events)
+	}
+
+	for sub := range b.subs {
+		if !sub.filter.Match(event) {
+			continue
+		}
+		select {
+		case sub.C <- sub.filter.Apply(event):
+		default:
+			// Медленный подписчик отключается и продолжит поток с последнего полученного события
+			b.logger.Warn().Msg("live: subscriber is too slow, disconnected")
+			b.unsubscribe(sub)
+		}
+	}
+}
+
+// Subscribe создает подписку и возвращает сохраненные события после lastID
+// complete равен false, если часть событий после lastID уже вытеснена из буфера
+// при нулевом lastID сохраненные события не возвращаются
+func (b *Broker) Subscribe(filter Filter, lastID uint64) (replay []Event, sub *Subscription, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub = &Subscription{
+		C:      make(chan Event, b.subBuffer),
+		filter: filter,
+	}
+	b.subs[sub] = struct{}{}
+
+	if lastID == 0 {
+		return nil, sub, true
+	}
+	complete = lastID+1 >= b.nextID-uint64(b.count) && lastID < b.nextID
+	for i := 0; i < b.count; i++ {
+		event := b.events[(b.start+i)%len(b.events)]
+		if event.ID > lastID && filter.Match(event) {
+			replay = append(replay, filter.Apply(event))
+		}
+	}
+	return replay, sub, complete
+}
+
+// Unsubscribe удаляет подписку и закрывает ее канал
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.unsubscribe(sub)
+}
+
+func (b *Broker) unsubscribe(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.C)
+	}
+}
+
+// NewBroker создает брокер событий, size - количество событий, доступных для продолжения потока
+func NewBroker(size int, subBuffer int, logger zerolog.Logger) *Broker {
+	return &Broker{
+		events:    make([]Event, size),
+		nextID:    uint64(time.Now().UnixMicro()),
+		subs:      make(map[*Subscription]struct{}),
+		subBuffer: subBuffer,
+		logger:    logger,
+	}
+}
@@ -0,0 +1,156 @@
+package live
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// EventPing событие проверки соединения
+	EventPing = "ping"
+	// EventReset часть событий после Last-Event-ID потеряна, состояние нужно перечитать
+	EventReset = "reset"
+	// retryMs рекомендуемая задержка переподключения клиента
+	retryMs = 3000
+)
+
+// parseFilter разбирает параметры hub, type, severity и device, hub ограничивает доступные пользователю хабы
+func parseFilter(c echo.Context, access []hubs.Access) (Filter, error) {
+	query := c.QueryParams()
+	filter := Filter{
+		HubIDs:  make(map[int64]struct{}),
+		Devices: query["device"],
+	}
+	names := query["hub"]
+	for _, a := range access {
+		if len(names) != 0 && !containsFold(names, a.Name) {
+			continue
+		}
+		filter.HubIDs[a.ID] = struct{}{}
+	}
+	for _, t := range query["type"] {
+		eventType := pkgmodel.RuleEvent(strings.ToLower(t))
+		switch eventType {
+		case pkgmodel.EventNotification, pkgmodel.EventHubOnline, pkgmodel.EventHubOffline,
+			pkgmodel.EventState, pkgmodel.EventCommand:
+		default:
+			return Filter{}, fmt.Errorf("live: unknown event type <%s>", t)
+		}
+		filter.Types = append(filter.Types, eventType)
+	}
+	for _, s := range query["severity"] {
+		severity, err := pkgmodel.NewSeverity(s)
+		if err != nil {
+			return Filter{}, err
+		}
+		filter.Severities = append(filter.Severities, severity)
+	}
+	return filter, nil
+}
+
+// lastEventID номер последнего полученного события из заголовка Last-Event-ID или параметра last_event_id
+func lastEventID(c echo.Context) (uint64, error) {
+	raw := c.Request().Header.Get("Last-Event-ID")
+	if len(raw) == 0 {
+		raw = c.QueryParam("last_event_id")
+	}
+	if len(raw) == 0 {
+		return 0, nil
+	}
+	return strconv.ParseUint(raw, 10, 64)
+}
+
+func writeEvent(w *echo.Response, id string, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if len(id) != 0 {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	w.Flush()
+	return nil
+}
+
+// Stream GET /internal/users/:tgUser/events, GET /webapp/api/events - поток событий хабов пользователя в формате Server-Sent Events
+// события: notification, hub_online, hub_offline, state, command, а также ping и reset
+// user возвращает пользователя telegram, события хабов которого передаются
+func Stream(broker *Broker, hubService hubs.HubService, heartbeat time.Duration, user func(c echo.Context) string) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		access, err := hubService.FindHubs(ctx, user(c), pkgmodel.ViewStatus)
+		if err != nil {
+			c.Logger().Error(err)
+			return echo.ErrInternalServerError
+		}
+		filter, err := parseFilter(c, access)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		lastID, err := lastEventID(c)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid Last-Event-ID")
+		}
+
+		replay, sub, complete := broker.Subscribe(filter, lastID)
+		defer broker.Unsubscribe(sub)
+
+		w := c.Response()
+		w.Header().Set(echo.HeaderContentType, "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set(echo.HeaderConnection, "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", retryMs); err != nil {
+			return nil
+		}
+
+		if !complete {
+			if err := writeEvent(w, "", EventReset, struct{}{}); err != nil {
+				return nil
+			}
+		}
+		for _, e := range replay {
+			if err := writeEvent(w, strconv.FormatUint(e.ID, 10), string(e.Type), e); err != nil {
+				return nil
+			}
+		}
+		w.Flush()
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case at := <-ticker.C:
+				if err := writeEvent(w, "", EventPing, struct {
+					At time.Time `json:"at"`
+				}{At: at}); err != nil {
+					return nil
+				}
+			case e, ok := <-sub.C:
+				if !ok {
+					// Подписка закрыта брокером, клиент переподключится с Last-Event-ID
+					return nil
+				}
+				if err := writeEvent(w, strconv.FormatUint(e.ID, 10), string(e.Type), e); err != nil {
+					return nil
+				}
+			}
+		}
+	}
+}
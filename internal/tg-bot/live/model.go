@@ -0,0 +1,125 @@
+package live
+
+import (
+	"strings"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// Event событие хаба для потоковой передачи на панели мониторинга
+type Event struct {
+	// ID возрастающий номер события, используется для продолжения потока после переподключения
+	ID    uint64             `json:"id"`
+	Type  pkgmodel.RuleEvent `json:"type"`
+	Hub   string             `json:"hub"`
+	HubID int64              `json:"-"`
+	At    time.Time          `json:"at"`
+	// Notify уведомление для событий типа notification
+	Notify *pkgmodel.NotifyEvent `json:"notify,omitempty"`
+	// Devices состояние устройств для событий типа state
+	Devices []pkgmodel.DeviceState `json:"devices,omitempty"`
+	// Command результат команды для событий типа command
+	Command *model.CommandResult `json:"command,omitempty"`
+}
+
+// newEvent преобразует событие хаба, номер назначается при публикации
+func newEvent(e model.HubEvent) Event {
+	event := Event{
+		Type:  e.Type,
+		Hub:   e.Hub.Name,
+		HubID: e.Hub.ID,
+		At:    e.At,
+	}
+	switch e.Type {
+	case pkgmodel.EventNotification:
+		notify := e.Notify
+		event.Notify = &notify
+	case pkgmodel.EventState:
+		event.Devices = e.State
+	case pkgmodel.EventCommand:
+		command := e.Command
+		event.Command = &command
+	}
+	return event
+}
+
+// Filter условия отбора событий для подписчика, пустые поля не проверяются
+type Filter struct {
+	// HubIDs хабы, доступные подписчику, проверяется всегда
+	HubIDs map[int64]struct{}
+	Types  []pkgmodel.RuleEvent
+	// Severities уровни важности уведомлений, на остальные события не влияют
+	Severities []pkgmodel.Severity
+	// Devices устройства для событий state и command, на остальные события не влияют
+	Devices []string
+}
+
+// Match проверяет, подходит ли событие подписчику
+func (f Filter) Match(e Event) bool {
+	if _, ok := f.HubIDs[e.HubID]; !ok {
+		return false
+	}
+	if len(f.Types) != 0 && !contains(f.Types, e.Type) {
+		return false
+	}
+	if e.Notify != nil && len(f.Severities) != 0 {
+		severity := e.Notify.Severity
+		if len(severity) == 0 {
+			severity = pkgmodel.SeverityNormal
+		}
+		if !contains(f.Severities, severity) {
+			return false
+		}
+	}
+	if len(f.Devices) == 0 {
+		return true
+	}
+	if e.Command != nil {
+		return containsFold(f.Devices, e.Command.DeviceID)
+	}
+	if e.Type == pkgmodel.EventState {
+		// Событие состояния подходит, если в нем есть хотя бы одно выбранное устройство
+		for _, d := range e.Devices {
+			if containsFold(f.Devices, d.DeviceID) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Apply оставляет в событии состояния только выбранные устройства
+func (f Filter) Apply(e Event) Event {
+	if e.Type != pkgmodel.EventState || len(f.Devices) == 0 {
+		return e
+	}
+	devices := make([]pkgmodel.DeviceState, 0, len(e.Devices))
+	for _, d := range e.Devices {
+		if containsFold(f.Devices, d.DeviceID) {
+			devices = append(devices, d)
+		}
+	}
+	e.Devices = devices
+	return e
+}
+
+func contains[T comparable](values []T, v T) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, v string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,8 +3,13 @@ package model
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	"github.com/c0dered273/automation-remote-controller/pkg/collections"
 	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
 	"github.com/c0dered273/automation-remote-controller/pkg/proto"
 	"github.com/google/uuid"
@@ -21,34 +26,126 @@ type ClientEvents struct {
 	Send chan *Event
 	// Err обработка ошибок, при появлении в канале объекта, клиентский стрим закрывается
 	Err chan error
-	// chatID идентификатор чата telegram, в который будут отправлены уведомления
-	chatID int64
-	// botNotify канал отправки сообщений непосредственно в чат пользователю
-	botNotify chan<- Notification
-	// IsNotify флаг показывает отправлять ли пользователю сообщения
-	IsNotify bool
-	logger   zerolog.Logger
+	// hub клиентское приложение (хаб)
+	hub hubs.Hub
+	// notifier рассылка уведомлений получателям хаба
+	notifier HubNotifier
+	// events получатель событий хаба, например движок правил автоматизации
+	events EventPublisher
+	// replies ожидающие ответа запросы по идентификатору события
+	replies   *collections.ConcurrentMap[string, chan *Event]
+	done      chan struct{}
+	closeOnce sync.Once
+	logger    zerolog.Logger
 }
 
+// ErrClientClosed клиентское приложение отключилось до получения ответа
+var ErrClientClosed = errors.New("client events: client disconnected")
+
 // SendAction отправить событие клиентскому приложению
 func (e *ClientEvents) SendAction(a pkgmodel.ActionEvent) error {
-	payload, err := json.Marshal(a)
+	return e.send(context.Background(), proto.Action_SWITCH, uuid.NewString(), a)
+}
+
+// Execute отправляет команду клиентскому приложению и ожидает подтверждение выполнения
+// время ожидания ограничивается контекстом
+func (e *ClientEvents) Execute(ctx context.Context, a pkgmodel.ActionEvent) (pkgmodel.AckEvent, error) {
+	var ack pkgmodel.AckEvent
+	recv, err := e.request(ctx, proto.Action_SWITCH, a)
+	if err != nil {
+		return ack, err
+	}
+	err = json.Unmarshal(recv.E.Payload, &ack)
+	if err != nil {
+		return ack, fmt.Errorf("client events: failed to unmarshal ack, %w", err)
+	}
+	ack.ID = recv.E.Id
+	return ack, nil
+}
+
+// ReadState запрашивает у клиентского приложения текущее состояние устройств
+func (e *ClientEvents) ReadState(ctx context.Context, req pkgmodel.StateRequest) (pkgmodel.StateEvent, error) {
+	var state pkgmodel.StateEvent
+	recv, err := e.request(ctx, proto.Action_STATE, req)
+	if err != nil {
+		return state, err
+	}
+	err = json.Unmarshal(recv.E.Payload, &state)
+	if err != nil {
+		return state, fmt.Errorf("client events: failed to unmarshal state, %w", err)
+	}
+	state.ID = recv.E.Id
+	e.events.Publish(HubEvent{
+		Type:  pkgmodel.EventState,
+		Hub:   e.hub,
+		State: state.Devices,
+		At:    time.Now(),
+	})
+	return state, nil
+}
+
+// Close отмечает стрим клиентского приложения закрытым, ожидающие ответа запросы завершаются с ошибкой
+func (e *ClientEvents) Close() {
+	e.closeOnce.Do(func() {
+		close(e.done)
+	})
+}
+
+// request отправляет событие и ожидает ответ клиентского приложения с тем же идентификатором
+func (e *ClientEvents) request(ctx context.Context, action proto.Action, payload any) (*Event, error) {
+	id := uuid.NewString()
+	reply := make(chan *Event, 1)
+	e.replies.Put(id, reply)
+	defer e.replies.Take(id)
+
+	err := e.send(ctx, action, id, payload)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-e.done:
+		return nil, ErrClientClosed
+	case recv := <-reply:
+		return recv, nil
+	}
+}
+
+func (e *ClientEvents) send(ctx context.Context, action proto.Action, id string, payload any) error {
+	data, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("client events: failed to marshal action, %w", err)
+		return fmt.Errorf("client events: failed to marshal payload, %w", err)
 	}
 	event := Event{
 		E: &proto.Event{
-			Id:      uuid.NewString(),
-			Action:  proto.Action_SWITCH,
-			Payload: payload,
+			Id:      id,
+			Action:  action,
+			Payload: data,
 		},
 		Err: nil,
 	}
-	e.Send <- &event
-	return nil
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-e.done:
+		return ErrClientClosed
+	case e.Send <- &event:
+		return nil
+	}
 }
 
-// ContinuousReadAndNotify ожидает событие от клиентского приложения и передает его непосредственно в чат пользователю
+// resolve передает ответ клиентского приложения ожидающему запросу
+func (e *ClientEvents) resolve(recv *Event) {
+	reply, ok := e.replies.Take(recv.E.Id)
+	if !ok {
+		e.logger.Debug().Str("id", recv.E.Id).Msg("client events: reply without pending request")
+		return
+	}
+	reply <- recv
+}
+
+// ContinuousReadAndNotify ожидает событие от клиентского приложения и передает его получателям уведомлений хаба
 func (e *ClientEvents) ContinuousReadAndNotify() {
 	go func() {
 		for {
@@ -57,9 +154,6 @@ func (e *ClientEvents) ContinuousReadAndNotify() {
 				e.Err <- e.ctx.Err()
 				return
 			case recv := <-e.Recv:
-				if !e.IsNotify {
-					continue
-				}
 				switch recv.E.Action {
 				case proto.Action_NOTIFICATION:
 					var notifyEvent pkgmodel.NotifyEvent
@@ -68,7 +162,15 @@ func (e *ClientEvents) ContinuousReadAndNotify() {
 						e.Err <- fmt.Errorf("client events: failed unmarshal event, %w", err)
 						return
 					}
-					e.botNotify <- NewNotification(e.chatID, notifyEvent.Text)
+					e.notifier.Notify(e.ctx, e.hub, notifyEvent)
+					e.events.Publish(HubEvent{
+						Type:   pkgmodel.EventNotification,
+						Hub:    e.hub,
+						Notify: notifyEvent,
+						At:     time.Now(),
+					})
+				case proto.Action_ACK, proto.Action_STATE:
+					e.resolve(recv)
 				}
 			}
 		}
@@ -76,15 +178,23 @@ func (e *ClientEvents) ContinuousReadAndNotify() {
 }
 
 // NewClientEvents создает настроенную структуру ClientEvents
-func NewClientEvents(ctx context.Context, chatID int64, botNotify chan<- Notification, isNotify bool, logger zerolog.Logger) *ClientEvents {
+func NewClientEvents(
+	ctx context.Context,
+	hub hubs.Hub,
+	notifier HubNotifier,
+	events EventPublisher,
+	logger zerolog.Logger,
+) *ClientEvents {
 	return &ClientEvents{
-		ctx:       ctx,
-		Recv:      make(chan *Event),
-		Send:      make(chan *Event),
-		Err:       make(chan error),
-		chatID:    chatID,
-		botNotify: botNotify,
-		IsNotify:  isNotify,
-		logger:    logger,
+		ctx:      ctx,
+		Recv:     make(chan *Event),
+		Send:     make(chan *Event),
+		Err:      make(chan error),
+		hub:      hub,
+		notifier: notifier,
+		events:   events,
+		replies:  collections.NewConcurrentMap[string, chan *Event](),
+		done:     make(chan struct{}),
+		logger:   logger,
 	}
 }
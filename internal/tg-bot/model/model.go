@@ -1,6 +1,13 @@
 package model
 
-import "github.com/c0dered273/automation-remote-controller/pkg/proto"
+import (
+	"context"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+	"github.com/c0dered273/automation-remote-controller/pkg/proto"
+)
 
 // Event внутреннее описание события
 type Event struct {
@@ -12,6 +19,10 @@ type Event struct {
 type Notification struct {
 	ChatID int64
 	Text   string
+	// AlertKey ключ источника уведомления, если задан, к сообщению добавляются кнопки отключения
+	AlertKey string
+	// Silent отправить сообщение без звука
+	Silent bool
 }
 
 func NewNotification(chatID int64, text string) Notification {
@@ -20,3 +31,48 @@ func NewNotification(chatID int64, text string) Notification {
 		Text:   text,
 	}
 }
+
+// HubEvent событие хаба: уведомление, подключение или отключение клиентского приложения
+type HubEvent struct {
+	Type pkgmodel.RuleEvent
+	Hub  hubs.Hub
+	// Notify содержимое уведомления для событий типа notification
+	Notify pkgmodel.NotifyEvent
+	// State состояние устройств для событий типа state
+	State []pkgmodel.DeviceState
+	// Command результат команды для событий типа command
+	Command CommandResult
+	At      time.Time
+}
+
+// CommandResult результат отправки команды устройству
+type CommandResult struct {
+	TGUser   string `json:"tg_user"`
+	DeviceID string `json:"device_id"`
+	Action   string `json:"action"`
+	Source   string `json:"source"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+	// LatencyMs время от отправки команды до подтверждения хабом или до отправки, если подтверждение не ожидалось
+	LatencyMs int64 `json:"latency_ms"`
+}
+
+// HubNotifier рассылка уведомлений хаба получателям
+type HubNotifier interface {
+	Notify(ctx context.Context, hub hubs.Hub, event pkgmodel.NotifyEvent)
+}
+
+// EventPublisher получатель событий хабов
+type EventPublisher interface {
+	// Publish передает событие без блокировки отправителя
+	Publish(e HubEvent)
+}
+
+// Publishers передает событие всем получателям
+type Publishers []EventPublisher
+
+func (p Publishers) Publish(e HubEvent) {
+	for _, publisher := range p {
+		publisher.Publish(e)
+	}
+}
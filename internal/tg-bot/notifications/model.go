@@ -0,0 +1,86 @@
+package notifications
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// ClockLayout формат времени начала и окончания часов тишины
+const ClockLayout = "15:04"
+
+// Prefs настройки уведомлений пользователя
+type Prefs struct {
+	UserID int64 `db:"user_id"`
+	// QuietStart, QuietEnd часы тишины в часовом поясе пользователя, интервал может переходить через полночь
+	QuietStart sql.NullString `db:"quiet_start"`
+	QuietEnd   sql.NullString `db:"quiet_end"`
+	Timezone   string         `db:"timezone"`
+}
+
+// IsQuiet проверяет, попадает ли момент now в часы тишины пользователя
+func (p Prefs) IsQuiet(now time.Time) bool {
+	if !p.QuietStart.Valid || !p.QuietEnd.Valid {
+		return false
+	}
+	start, err := time.Parse(ClockLayout, p.QuietStart.String)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse(ClockLayout, p.QuietEnd.String)
+	if err != nil {
+		return false
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+	minutes := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from <= to {
+		return minutes >= from && minutes < to
+	}
+	return minutes >= from || minutes < to
+}
+
+// Mute отключенное на время уведомление
+type Mute struct {
+	UserID     int64     `db:"user_id"`
+	AlertKey   string    `db:"alert_key"`
+	AlertText  string    `db:"alert_text"`
+	MutedUntil time.Time `db:"muted_until"`
+}
+
+// Decision результат проверки уведомления правилами пользователя
+type Decision struct {
+	// Deliver отправлять ли уведомление
+	Deliver bool
+	// Silent отправить уведомление без звука
+	Silent bool
+}
+
+// AlertKey возвращает короткий ключ источника уведомления, который помещается в callback кнопки telegram
+func AlertKey(event pkgmodel.NotifyEvent) string {
+	source := event.AlertID
+	if len(source) == 0 {
+		source = event.Text
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ParseClock проверяет время в формате ЧЧ:ММ
+func ParseClock(s string) (string, error) {
+	t, err := time.Parse(ClockLayout, s)
+	if err != nil {
+		return "", fmt.Errorf("notifications: invalid time <%s>, expected HH:MM", s)
+	}
+	return t.Format(ClockLayout), nil
+}
@@ -0,0 +1,141 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+// NotifyRepository описывает методы работы с настройками уведомлений
+type NotifyRepository interface {
+	// FindPrefsByUserID возвращает настройки уведомлений вместе с часовым поясом пользователя
+	FindPrefsByUserID(ctx context.Context, userID int64) (Prefs, error)
+	// FindPrefsByTGUser возвращает настройки уведомлений пользователя telegram
+	FindPrefsByTGUser(ctx context.Context, tgUser string) (Prefs, error)
+	// SaveQuietHours сохраняет часы тишины, пустые значения отключают их
+	SaveQuietHours(ctx context.Context, tgUser string, start sql.NullString, end sql.NullString) error
+	// IsMuted проверяет, отключено ли уведомление на момент now
+	IsMuted(ctx context.Context, userID int64, alertKey string, now time.Time) (bool, error)
+	// SaveMute отключает уведомление до указанного времени
+	SaveMute(ctx context.Context, tgUser string, mute Mute) error
+	// FindMutesByTGUser возвращает действующие отключения уведомлений
+	FindMutesByTGUser(ctx context.Context, tgUser string, now time.Time) ([]Mute, error)
+	// DeleteMute снимает отключение уведомления
+	DeleteMute(ctx context.Context, tgUser string, alertKey string) error
+}
+
+type SQLNotifyRepo struct {
+	db *sqlx.DB
+}
+
+func (r SQLNotifyRepo) FindPrefsByUserID(ctx context.Context, userID int64) (Prefs, error) {
+	const sqlQuery = `SELECT u.id AS user_id, p.quiet_start, p.quiet_end, u.timezone
+		FROM users u LEFT JOIN notification_prefs p ON p.user_id = u.id
+		WHERE u.id = $1`
+
+	prefs := Prefs{}
+	err := r.db.GetContext(ctx, &prefs, sqlQuery, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Prefs{}, repository.ErrNotFound
+		}
+		return Prefs{}, err
+	}
+
+	return prefs, nil
+}
+
+func (r SQLNotifyRepo) FindPrefsByTGUser(ctx context.Context, tgUser string) (Prefs, error) {
+	const sqlQuery = `SELECT u.id AS user_id, p.quiet_start, p.quiet_end, u.timezone
+		FROM users u LEFT JOIN notification_prefs p ON p.user_id = u.id
+		WHERE u.tg_user = $1`
+
+	prefs := Prefs{}
+	err := r.db.GetContext(ctx, &prefs, sqlQuery, tgUser)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Prefs{}, repository.ErrNotFound
+		}
+		return Prefs{}, err
+	}
+
+	return prefs, nil
+}
+
+func (r SQLNotifyRepo) SaveQuietHours(ctx context.Context, tgUser string, start sql.NullString, end sql.NullString) error {
+	const sqlQuery = `INSERT INTO notification_prefs(user_id, quiet_start, quiet_end)
+		SELECT id, $2, $3 FROM users WHERE tg_user = $1
+		ON CONFLICT (user_id) DO UPDATE SET quiet_start = EXCLUDED.quiet_start, quiet_end = EXCLUDED.quiet_end`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, tgUser, start, end)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r SQLNotifyRepo) IsMuted(ctx context.Context, userID int64, alertKey string, now time.Time) (bool, error) {
+	const sqlQuery = `SELECT EXISTS(SELECT 1 FROM alert_mutes WHERE user_id = $1 AND alert_key = $2 AND muted_until > $3)`
+
+	var muted bool
+	err := r.db.GetContext(ctx, &muted, sqlQuery, userID, alertKey, now)
+	if err != nil {
+		return false, err
+	}
+
+	return muted, nil
+}
+
+func (r SQLNotifyRepo) SaveMute(ctx context.Context, tgUser string, mute Mute) error {
+	const sqlQuery = `INSERT INTO alert_mutes(user_id, alert_key, alert_text, muted_until)
+		SELECT id, $2, $3, $4 FROM users WHERE tg_user = $1
+		ON CONFLICT (user_id, alert_key) DO UPDATE SET alert_text = EXCLUDED.alert_text, muted_until = EXCLUDED.muted_until`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, tgUser, mute.AlertKey, mute.AlertText, mute.MutedUntil)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r SQLNotifyRepo) FindMutesByTGUser(ctx context.Context, tgUser string, now time.Time) ([]Mute, error) {
+	const sqlQuery = `SELECT m.user_id, m.alert_key, m.alert_text, m.muted_until
+		FROM alert_mutes m JOIN users u ON u.id = m.user_id
+		WHERE u.tg_user = $1 AND m.muted_until > $2
+		ORDER BY m.muted_until`
+
+	var result []Mute
+	err := r.db.SelectContext(ctx, &result, sqlQuery, tgUser, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r SQLNotifyRepo) DeleteMute(ctx context.Context, tgUser string, alertKey string) error {
+	const sqlQuery = `DELETE FROM alert_mutes m USING users u WHERE m.user_id = u.id AND u.tg_user = $1 AND m.alert_key = $2`
+
+	_, err := r.db.ExecContext(ctx, sqlQuery, tgUser, alertKey)
+	return err
+}
+
+func NewRepo(db *sqlx.DB) SQLNotifyRepo {
+	return SQLNotifyRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,115 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+	"github.com/rs/zerolog"
+)
+
+// NotifyService сервис применяет к уведомлениям правила пользователя: часы тишины, отключение и уровень важности
+type NotifyService interface {
+	// Check решает, доставлять ли уведомление пользователю и нужно ли отправить его без звука
+	Check(ctx context.Context, userID int64, event pkgmodel.NotifyEvent) Decision
+	// GetPrefs возвращает настройки уведомлений пользователя
+	GetPrefs(ctx context.Context, tgName string) (Prefs, error)
+	// SetQuietHours устанавливает часы тишины в формате ЧЧ:ММ
+	SetQuietHours(ctx context.Context, tgName string, start string, end string) error
+	// DisableQuietHours отключает часы тишины
+	DisableQuietHours(ctx context.Context, tgName string) error
+	// Mute отключает уведомление с ключом alertKey на время d
+	Mute(ctx context.Context, tgName string, alertKey string, alertText string, d time.Duration) error
+	// Unmute снимает отключение уведомления
+	Unmute(ctx context.Context, tgName string, alertKey string) error
+	// FindMutes возвращает действующие отключения уведомлений
+	FindMutes(ctx context.Context, tgName string) ([]Mute, error)
+}
+
+type NotifyServiceImpl struct {
+	notifyRepo NotifyRepository
+	logger     zerolog.Logger
+}
+
+// Check при ошибке чтения настроек уведомление доставляется, чтобы не потерять важное событие
+func (s NotifyServiceImpl) Check(ctx context.Context, userID int64, event pkgmodel.NotifyEvent) Decision {
+	now := time.Now()
+	decision := Decision{
+		Deliver: true,
+		Silent:  event.Severity == pkgmodel.SeverityLow,
+	}
+
+	muted, err := s.notifyRepo.IsMuted(ctx, userID, AlertKey(event), now)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("notify service: failed to check alert mute")
+	}
+	if muted {
+		decision.Deliver = false
+		return decision
+	}
+
+	if event.Severity == pkgmodel.SeverityCritical {
+		return decision
+	}
+	prefs, err := s.notifyRepo.FindPrefsByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("notify service: failed to find notification prefs")
+		return decision
+	}
+	if prefs.IsQuiet(now) {
+		decision.Silent = true
+	}
+
+	return decision
+}
+
+func (s NotifyServiceImpl) GetPrefs(ctx context.Context, tgName string) (Prefs, error) {
+	return s.notifyRepo.FindPrefsByTGUser(ctx, tgName)
+}
+
+func (s NotifyServiceImpl) SetQuietHours(ctx context.Context, tgName string, start string, end string) error {
+	from, err := ParseClock(start)
+	if err != nil {
+		return err
+	}
+	to, err := ParseClock(end)
+	if err != nil {
+		return err
+	}
+	return s.notifyRepo.SaveQuietHours(ctx, tgName,
+		sql.NullString{String: from, Valid: true},
+		sql.NullString{String: to, Valid: true},
+	)
+}
+
+func (s NotifyServiceImpl) DisableQuietHours(ctx context.Context, tgName string) error {
+	return s.notifyRepo.SaveQuietHours(ctx, tgName, sql.NullString{}, sql.NullString{})
+}
+
+func (s NotifyServiceImpl) Mute(ctx context.Context, tgName string, alertKey string, alertText string, d time.Duration) error {
+	if text := []rune(alertText); len(text) > 256 {
+		alertText = string(text[:256])
+	}
+	return s.notifyRepo.SaveMute(ctx, tgName, Mute{
+		AlertKey:   alertKey,
+		AlertText:  alertText,
+		MutedUntil: time.Now().Add(d),
+	})
+}
+
+func (s NotifyServiceImpl) Unmute(ctx context.Context, tgName string, alertKey string) error {
+	return s.notifyRepo.DeleteMute(ctx, tgName, alertKey)
+}
+
+func (s NotifyServiceImpl) FindMutes(ctx context.Context, tgName string) ([]Mute, error) {
+	return s.notifyRepo.FindMutesByTGUser(ctx, tgName, time.Now())
+}
+
+// NewNotifyService создает сервис правил уведомлений
+func NewNotifyService(notifyRepo NotifyRepository, logger zerolog.Logger) NotifyServiceImpl {
+	return NotifyServiceImpl{
+		notifyRepo: notifyRepo,
+		logger:     logger,
+	}
+}
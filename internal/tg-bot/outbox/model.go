@@ -0,0 +1,52 @@
+package outbox
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
+)
+
+// Message сообщение в очереди на отправку в telegram
+type Message struct {
+	ID       int64  `db:"id"`
+	ChatID   int64  `db:"chat_id"`
+	Text     string `db:"text"`
+	AlertKey string `db:"alert_key"`
+	Silent   bool   `db:"silent"`
+	// Count количество одинаковых уведомлений, объединенных в одно сообщение
+	Count     int       `db:"count"`
+	Attempts  int       `db:"attempts"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Notification возвращает уведомление для отправки, к тексту объединенных уведомлений добавляется счетчик
+func (m *Message) Notification() model.Notification {
+	n := model.NewNotification(m.ChatID, m.Text)
+	if m.Count > 1 {
+		n.Text = fmt.Sprintf("%s ×%d", m.Text, m.Count)
+	}
+	n.AlertKey = m.AlertKey
+	n.Silent = m.Silent
+	return n
+}
+
+// sameAlert проверяет, что сообщения содержат одно и то же уведомление
+func (m *Message) sameAlert(other *Message) bool {
+	return m.ChatID == other.ChatID &&
+		m.AlertKey == other.AlertKey &&
+		m.Text == other.Text &&
+		m.Silent == other.Silent
+}
+
+// NewMessage создает сообщение очереди из уведомления
+func NewMessage(n model.Notification) *Message {
+	return &Message{
+		ChatID:    n.ChatID,
+		Text:      n.Text,
+		AlertKey:  n.AlertKey,
+		Silent:    n.Silent,
+		Count:     1,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,77 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// OutboxRepository хранит неотправленные сообщения, чтобы они не терялись при перезапуске сервера
+type OutboxRepository interface {
+	// SaveMessage сохраняет новое сообщение и возвращает его идентификатор
+	SaveMessage(ctx context.Context, m *Message) (int64, error)
+	// UpdateCount обновляет счетчик объединенных уведомлений
+	UpdateCount(ctx context.Context, id int64, count int) error
+	// UpdateAttempts обновляет количество попыток отправки
+	UpdateAttempts(ctx context.Context, id int64, attempts int) error
+	// DeleteMessage удаляет отправленное или отброшенное сообщение
+	DeleteMessage(ctx context.Context, id int64) error
+	// FindMessages возвращает все неотправленные сообщения в порядке поступления
+	FindMessages(ctx context.Context) ([]*Message, error)
+}
+
+type SQLOutboxRepo struct {
+	db *sqlx.DB
+}
+
+func (r SQLOutboxRepo) SaveMessage(ctx context.Context, m *Message) (int64, error) {
+	const sqlQuery = `INSERT INTO outbox(chat_id, text, alert_key, silent, count, attempts, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	var id int64
+	err := r.db.GetContext(ctx, &id, sqlQuery, m.ChatID, m.Text, m.AlertKey, m.Silent, m.Count, m.Attempts, m.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r SQLOutboxRepo) UpdateCount(ctx context.Context, id int64, count int) error {
+	const sqlQuery = `UPDATE outbox SET count = $2 WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlQuery, id, count)
+	return err
+}
+
+func (r SQLOutboxRepo) UpdateAttempts(ctx context.Context, id int64, attempts int) error {
+	const sqlQuery = `UPDATE outbox SET attempts = $2 WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlQuery, id, attempts)
+	return err
+}
+
+func (r SQLOutboxRepo) DeleteMessage(ctx context.Context, id int64) error {
+	const sqlQuery = `DELETE FROM outbox WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlQuery, id)
+	return err
+}
+
+func (r SQLOutboxRepo) FindMessages(ctx context.Context) ([]*Message, error) {
+	const sqlQuery = `SELECT id, chat_id, text, alert_key, silent, count, attempts, created_at FROM outbox ORDER BY id`
+
+	var result []*Message
+	err := r.db.SelectContext(ctx, &result, sqlQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func NewRepo(db *sqlx.DB) SQLOutboxRepo {
+	return SQLOutboxRepo{
+		db: db,
+	}
+}
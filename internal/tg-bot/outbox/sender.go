@@ -0,0 +1,302 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/configs"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+	"golang.org/x/time/rate"
+)
+
+// BotSender отправляет сообщение в telegram api, реализуется *tgbotapi.BotAPI
+type BotSender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+}
+
+// sendResult результат попытки отправки
+type sendResult int
+
+const (
+	sent sendResult = iota
+	retryAfter
+	transient
+	permanent
+)
+
+// chatQueue очередь сообщений одного чата
+type chatQueue struct {
+	mu    sync.Mutex
+	items []*Message
+	// inFlight первое сообщение очереди отправляется прямо сейчас, объединять с ним новые уведомления нельзя
+	inFlight bool
+	signal   chan struct{}
+	limiter  *rate.Limiter
+}
+
+func (q *chatQueue) notify() {
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+// Sender очередь отправки сообщений в telegram.
+// Каждый чат обслуживается отдельной горутиной со своим ограничением скорости, дополнительно действует общее ограничение.
+// При ответе 429 отправка в чат приостанавливается на время retry_after, временные ошибки повторяются с экспоненциальной задержкой.
+// Одинаковые уведомления, ожидающие отправки, объединяются в одно сообщение со счетчиком.
+// Все сообщения до отправки хранятся в БД и восстанавливаются после перезапуска.
+type Sender struct {
+	ctx    context.Context
+	api    BotSender
+	build  func(n model.Notification) tgbotapi.Chattable
+	repo   OutboxRepository
+	in     chan model.Notification
+	global *rate.Limiter
+	config configs.SenderCfg
+	mu     sync.Mutex
+	queues map[int64]*chatQueue
+	logger zerolog.Logger
+}
+
+// GetNotifyChan отдает канал для отправки уведомлений
+func (s *Sender) GetNotifyChan() chan<- model.Notification {
+	return s.in
+}
+
+// queue возвращает очередь чата, при первом обращении запускает горутину отправки
+func (s *Sender) queue(chatID int64) *chatQueue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, ok := s.queues[chatID]
+	if !ok {
+		q = &chatQueue{
+			signal:  make(chan struct{}, 1),
+			limiter: rate.NewLimiter(rate.Limit(s.config.ChatRate), 1),
+		}
+		s.queues[chatID] = q
+		go s.worker(q)
+	}
+	return q
+}
+
+// enqueue сохраняет сообщение в БД и помещает его в очередь чата,
+// если в очереди уже ожидает такое же уведомление, увеличивается его счетчик
+func (s *Sender) enqueue(m *Message) {
+	q := s.queue(m.ChatID)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, pending := range q.items {
+		if i == 0 && q.inFlight {
+			continue
+		}
+		if pending.sameAlert(m) {
+			pending.Count += m.Count
+			if pending.ID != 0 {
+				if err := s.repo.UpdateCount(s.ctx, pending.ID, pending.Count); err != nil {
+					s.logger.Error().Err(err).Msg("sender: failed to update message count")
+				}
+			}
+			if m.ID != 0 {
+				if err := s.repo.DeleteMessage(s.ctx, m.ID); err != nil {
+					s.logger.Error().Err(err).Msg("sender: failed to delete merged message")
+				}
+			}
+			return
+		}
+	}
+
+	if m.ID == 0 {
+		id, err := s.repo.SaveMessage(s.ctx, m)
+		if err != nil {
+			s.logger.Error().Err(err).Msg("sender: failed to persist message")
+		}
+		m.ID = id
+	}
+	q.items = append(q.items, m)
+	q.notify()
+}
+
+// next ожидает первое сообщение в очереди и помечает его как отправляемое
+func (s *Sender) next(q *chatQueue) (*Message, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.items) > 0 {
+			q.inFlight = true
+			m := q.items[0]
+			q.mu.Unlock()
+			return m, true
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-s.ctx.Done():
+			return nil, false
+		case <-q.signal:
+		}
+	}
+}
+
+// done убирает первое сообщение из очереди и из БД
+func (s *Sender) done(q *chatQueue, m *Message) {
+	q.mu.Lock()
+	q.items = q.items[1:]
+	q.inFlight = false
+	q.mu.Unlock()
+
+	if m.ID != 0 {
+		if err := s.repo.DeleteMessage(s.ctx, m.ID); err != nil {
+			s.logger.Error().Err(err).Msg("sender: failed to delete message")
+		}
+	}
+}
+
+// release снимает пометку отправки, пока сообщение ждет повтора, к нему можно добавлять одинаковые уведомления
+func (s *Sender) release(q *chatQueue) {
+	q.mu.Lock()
+	q.inFlight = false
+	q.mu.Unlock()
+}
+
+func (s *Sender) sleep(d time.Duration) bool {
+	select {
+	case <-s.ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+func (s *Sender) backoff(attempts int) time.Duration {
+	d := s.config.BackoffMin
+	for i := 1; i < attempts && d < s.config.BackoffMax; i++ {
+		d *= 2
+	}
+	if d > s.config.BackoffMax {
+		d = s.config.BackoffMax
+	}
+	return d
+}
+
+func (s *Sender) send(m *Message) (sendResult, time.Duration, error) {
+	_, err := s.api.Send(s.build(m.Notification()))
+	if err == nil {
+		return sent, 0, nil
+	}
+
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests:
+			return retryAfter, time.Duration(apiErr.RetryAfter) * time.Second, err
+		case apiErr.Code >= http.StatusInternalServerError:
+			return transient, 0, err
+		case apiErr.Code != 0:
+			return permanent, 0, err
+		}
+	}
+	return transient, 0, err
+}
+
+func (s *Sender) worker(q *chatQueue) {
+	for {
+		m, ok := s.next(q)
+		if !ok {
+			return
+		}
+		if err := q.limiter.Wait(s.ctx); err != nil {
+			return
+		}
+		if err := s.global.Wait(s.ctx); err != nil {
+			return
+		}
+
+		result, delay, err := s.send(m)
+		log := s.logger.With().Int64("chatID", m.ChatID).Int64("messageID", m.ID).Logger()
+		switch result {
+		case sent:
+			s.done(q, m)
+		case permanent:
+			log.Error().Err(err).Msg("sender: message rejected by telegram, dropped")
+			s.done(q, m)
+		case retryAfter:
+			log.Warn().Err(err).Msgf("sender: rate limited, retry after %s", delay)
+			s.release(q)
+			if !s.sleep(delay) {
+				return
+			}
+		case transient:
+			m.Attempts++
+			if m.Attempts >= s.config.MaxAttempts {
+				log.Error().Err(err).Msgf("sender: message dropped after %d attempts", m.Attempts)
+				s.done(q, m)
+				continue
+			}
+			if m.ID != 0 {
+				if err := s.repo.UpdateAttempts(s.ctx, m.ID, m.Attempts); err != nil {
+					log.Error().Err(err).Msg("sender: failed to update attempts")
+				}
+			}
+			delay = s.backoff(m.Attempts)
+			log.Warn().Err(err).Msgf("sender: failed to send message, retry in %s", delay)
+			s.release(q)
+			if !s.sleep(delay) {
+				return
+			}
+		}
+	}
+}
+
+// Start восстанавливает неотправленные сообщения из БД и запускает прием новых уведомлений
+func (s *Sender) Start() {
+	pending, err := s.repo.FindMessages(s.ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("sender: failed to restore pending messages")
+	}
+	for _, m := range pending {
+		s.enqueue(m)
+	}
+	if len(pending) > 0 {
+		s.logger.Info().Msgf("sender: restored %d pending messages", len(pending))
+	}
+
+	go func() {
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case n := <-s.in:
+				s.enqueue(NewMessage(n))
+			}
+		}
+	}()
+}
+
+// NewSender создает очередь отправки сообщений
+// build преобразует уведомление в сообщение telegram api
+func NewSender(
+	ctx context.Context,
+	api BotSender,
+	build func(n model.Notification) tgbotapi.Chattable,
+	repo OutboxRepository,
+	config configs.SenderCfg,
+	logger zerolog.Logger,
+) *Sender {
+	return &Sender{
+		ctx:    ctx,
+		api:    api,
+		build:  build,
+		repo:   repo,
+		in:     make(chan model.Notification, 64),
+		global: rate.NewLimiter(rate.Limit(config.GlobalRate), int(config.GlobalRate)+1),
+		config: config,
+		queues: make(map[int64]*chatQueue),
+		logger: logger,
+	}
+}
@@ -0,0 +1,364 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/configs"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rs/zerolog"
+	"golang.org/x/time/rate"
+)
+
+// memRepo хранилище сообщений в памяти
+type memRepo struct {
+	mu       sync.Mutex
+	nextID   int64
+	messages map[int64]Message
+}
+
+func newMemRepo() *memRepo {
+	return &memRepo{messages: make(map[int64]Message)}
+}
+
+func (r *memRepo) SaveMessage(_ context.Context, m *Message) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	saved := *m
+	saved.ID = r.nextID
+	r.messages[saved.ID] = saved
+	return saved.ID, nil
+}
+
+func (r *memRepo) UpdateCount(_ context.Context, id int64, count int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := r.messages[id]
+	m.Count = count
+	r.messages[id] = m
+	return nil
+}
+
+func (r *memRepo) UpdateAttempts(_ context.Context, id int64, attempts int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := r.messages[id]
+	m.Attempts = attempts
+	r.messages[id] = m
+	return nil
+}
+
+func (r *memRepo) DeleteMessage(_ context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.messages, id)
+	return nil
+}
+
+func (r *memRepo) FindMessages(context.Context) ([]*Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]*Message, 0, len(r.messages))
+	for _, m := range r.messages {
+		m := m
+		result = append(result, &m)
+	}
+	return result, nil
+}
+
+func (r *memRepo) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.messages)
+}
+
+// scriptedAPI возвращает ошибки из списка по очереди, после окончания списка сообщения отправляются успешно
+type scriptedAPI struct {
+	mu    sync.Mutex
+	errs  []error
+	calls int
+	sent  []string
+}
+
+func (a *scriptedAPI) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls++
+	if len(a.errs) > 0 {
+		err := a.errs[0]
+		a.errs = a.errs[1:]
+		return tgbotapi.Message{}, err
+	}
+	a.sent = append(a.sent, c.(tgbotapi.MessageConfig).Text)
+	return tgbotapi.Message{}, nil
+}
+
+func (a *scriptedAPI) result() (int, []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.calls, append([]string(nil), a.sent...)
+}
+
+var testSenderCfg = configs.SenderCfg{
+	GlobalRate:  1000,
+	ChatRate:    1000,
+	MaxAttempts: 3,
+	BackoffMin:  time.Millisecond,
+	BackoffMax:  4 * time.Millisecond,
+}
+
+func newTestSender(ctx context.Context, api BotSender, repo OutboxRepository) *Sender {
+	return NewSender(ctx, api, func(n model.Notification) tgbotapi.Chattable {
+		return tgbotapi.NewMessage(n.ChatID, n.Text)
+	}, repo, testSenderCfg, zerolog.Nop())
+}
+
+// stoppedQueue регистрирует очередь чата без горутины отправки, чтобы проверять объединение сообщений
+func stoppedQueue(s *Sender, chatID int64) *chatQueue {
+	q := &chatQueue{signal: make(chan struct{}, 1), limiter: rate.NewLimiter(rate.Inf, 1)}
+	s.queues[chatID] = q
+	return q
+}
+
+func TestEnqueueMerge(t *testing.T) {
+	alert := func(chatID int64, text string) model.Notification {
+		n := model.NewNotification(chatID, text)
+		n.AlertKey = "hub:pump"
+		return n
+	}
+	tests := []struct {
+		name      string
+		inFlight  bool
+		in        []model.Notification
+		wantItems []int
+	}{
+		{
+			name:      "same alerts merged",
+			in:        []model.Notification{alert(1, "pump failure"), alert(1, "pump failure"), alert(1, "pump failure")},
+			wantItems: []int{3},
+		},
+		{
+			name:      "different text not merged",
+			in:        []model.Notification{alert(1, "pump failure"), alert(1, "pump ok")},
+			wantItems: []int{1, 1},
+		},
+		{
+			name:      "different alert key not merged",
+			in:        []model.Notification{alert(1, "pump failure"), model.NewNotification(1, "pump failure")},
+			wantItems: []int{1, 1},
+		},
+		{
+			name:      "merged with waiting message behind another",
+			in:        []model.Notification{alert(1, "pump failure"), alert(1, "pump ok"), alert(1, "pump failure")},
+			wantItems: []int{2, 1},
+		},
+		{
+			name:      "message in flight is not merged",
+			inFlight:  true,
+			in:        []model.Notification{alert(1, "pump failure"), alert(1, "pump failure"), alert(1, "pump failure")},
+			wantItems: []int{1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemRepo()
+			s := newTestSender(context.Background(), &scriptedAPI{}, repo)
+			q := stoppedQueue(s, 1)
+			for i, n := range tt.in {
+				s.enqueue(NewMessage(n))
+				if i == 0 {
+					q.inFlight = tt.inFlight
+				}
+			}
+
+			if len(q.items) != len(tt.wantItems) {
+				t.Fatalf("queue length = %d, want %d", len(q.items), len(tt.wantItems))
+			}
+			for i, m := range q.items {
+				if m.Count != tt.wantItems[i] {
+					t.Errorf("item #%d count = %d, want %d", i, m.Count, tt.wantItems[i])
+				}
+				saved, ok := repo.messages[m.ID]
+				if !ok {
+					t.Errorf("item #%d is not persisted", i)
+					continue
+				}
+				if saved.Count != m.Count {
+					t.Errorf("item #%d persisted count = %d, want %d", i, saved.Count, m.Count)
+				}
+			}
+			if repo.len() != len(tt.wantItems) {
+				t.Errorf("persisted messages = %d, want %d", repo.len(), len(tt.wantItems))
+			}
+		})
+	}
+}
+
+func TestEnqueueMergeRestored(t *testing.T) {
+	repo := newMemRepo()
+	s := newTestSender(context.Background(), &scriptedAPI{}, repo)
+	q := stoppedQueue(s, 1)
+
+	// сообщения, восстановленные из БД после перезапуска, уже имеют идентификаторы
+	for i := 0; i < 2; i++ {
+		m := NewMessage(model.NewNotification(1, "pump failure"))
+		id, _ := repo.SaveMessage(context.Background(), m)
+		m.ID = id
+		s.enqueue(m)
+	}
+
+	if len(q.items) != 1 || q.items[0].Count != 2 {
+		t.Fatalf("queue = %+v, want one message with count 2", q.items)
+	}
+	if repo.len() != 1 || repo.messages[q.items[0].ID].Count != 2 {
+		t.Errorf("persisted = %+v, want merged message only", repo.messages)
+	}
+}
+
+func TestMessageNotification(t *testing.T) {
+	tests := []struct {
+		name  string
+		count int
+		want  string
+	}{
+		{name: "single", count: 1, want: "pump failure"},
+		{name: "merged", count: 3, want: "pump failure ×3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMessage(model.NewNotification(1, "pump failure"))
+			m.Count = tt.count
+			if got := m.Notification().Text; got != tt.want {
+				t.Errorf("Notification().Text = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendResult(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		want      sendResult
+		wantDelay time.Duration
+	}{
+		{name: "sent", want: sent},
+		{
+			name:      "rate limited",
+			err:       &tgbotapi.Error{Code: 429, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 7}},
+			want:      retryAfter,
+			wantDelay: 7 * time.Second,
+		},
+		{name: "server error", err: &tgbotapi.Error{Code: 502}, want: transient},
+		{name: "bad request", err: &tgbotapi.Error{Code: 400, Message: "chat not found"}, want: permanent},
+		{name: "blocked by user", err: &tgbotapi.Error{Code: 403}, want: permanent},
+		{name: "network error", err: errors.New("connection reset"), want: transient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &scriptedAPI{}
+			if tt.err != nil {
+				api.errs = []error{tt.err}
+			}
+			s := newTestSender(context.Background(), api, newMemRepo())
+			got, delay, _ := s.send(NewMessage(model.NewNotification(1, "text")))
+			if got != tt.want || delay != tt.wantDelay {
+				t.Errorf("send() = %v, %v, want %v, %v", got, delay, tt.want, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	s := newTestSender(context.Background(), &scriptedAPI{}, newMemRepo())
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Millisecond},
+		{attempts: 2, want: 2 * time.Millisecond},
+		{attempts: 3, want: 4 * time.Millisecond},
+		{attempts: 10, want: 4 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := s.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestWorkerRetry(t *testing.T) {
+	serverErr := &tgbotapi.Error{Code: 500}
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantSent  int
+	}{
+		{name: "sent first time", wantCalls: 1, wantSent: 1},
+		{name: "sent after transient errors", errs: []error{serverErr, serverErr}, wantCalls: 3, wantSent: 1},
+		{name: "sent after rate limit", errs: []error{&tgbotapi.Error{Code: 429}}, wantCalls: 2, wantSent: 1},
+		{name: "dropped after max attempts", errs: []error{serverErr, serverErr, serverErr, serverErr}, wantCalls: 3},
+		{name: "permanent error is not retried", errs: []error{&tgbotapi.Error{Code: 400}}, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			api := &scriptedAPI{errs: tt.errs}
+			repo := newMemRepo()
+			s := newTestSender(ctx, api, repo)
+			s.Start()
+			s.GetNotifyChan() <- model.NewNotification(1, "pump failure")
+
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				calls, _ := api.result()
+				if calls >= tt.wantCalls && repo.len() == 0 {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("timeout: calls = %d, persisted = %d", calls, repo.len())
+				}
+				time.Sleep(time.Millisecond)
+			}
+			// лишних попыток после отправки или отбрасывания сообщения нет
+			time.Sleep(20 * time.Millisecond)
+			calls, sent := api.result()
+			if calls != tt.wantCalls {
+				t.Errorf("send calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if len(sent) != tt.wantSent {
+				t.Errorf("sent = %v, want %d messages", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestStartRestoresPending(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	repo := newMemRepo()
+	for _, text := range []string{"first", "second"} {
+		_, _ = repo.SaveMessage(ctx, NewMessage(model.NewNotification(1, text)))
+	}
+	api := &scriptedAPI{}
+	s := newTestSender(ctx, api, repo)
+	s.Start()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for repo.len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout: persisted = %d", repo.len())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, sent := api.result(); len(sent) != 2 {
+		t.Errorf("sent = %v, want 2 restored messages", sent)
+	}
+}
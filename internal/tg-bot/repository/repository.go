@@ -5,5 +5,6 @@ import (
 )
 
 var (
-	ErrNotFound = errors.New("repository: not found")
+	ErrAlreadyExists = errors.New("repository: already exists")
+	ErrNotFound      = errors.New("repository: not found")
 )
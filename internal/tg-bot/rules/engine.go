@@ -0,0 +1,239 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/devices"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/scenes"
+	"github.com/c0dered273/automation-remote-controller/pkg/collections"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+	"github.com/rs/zerolog"
+)
+
+// Engine движок правил автоматизации. Получает события всех хабов, проверяет условия включенных правил
+// и выполняет их действия от имени владельца правила.
+// Повторное срабатывание правила ограничивается cooldown правила. Защита от зацикливания: если правило
+// срабатывает больше loopMaxFires раз за loopWindow (например, команда одного правила вызывает уведомление,
+// запускающее другое правило, и наоборот), правило отключается.
+type Engine struct {
+	ctx           context.Context
+	ruleRepo      RuleRepository
+	hubService    hubs.HubService
+	policyService devices.PolicyService
+	sceneService  scenes.SceneService
+	notifier      model.HubNotifier
+	clients       *collections.ConcurrentMap[string, *model.ClientEvents]
+	events        chan model.HubEvent
+	// fires время последних срабатываний правил, используется только в горутине обработки событий
+	fires        map[int64][]time.Time
+	loopWindow   time.Duration
+	loopMaxFires int
+	logger       zerolog.Logger
+}
+
+// Publish ставит событие в очередь обработки, при переполнении очереди событие отбрасывается
+// события, на которые правила не срабатывают, не ставятся в очередь
+func (e *Engine) Publish(event model.HubEvent) {
+	switch event.Type {
+	case pkgmodel.EventNotification, pkgmodel.EventHubOnline, pkgmodel.EventHubOffline:
+	default:
+		return
+	}
+	select {
+	case e.events <- event:
+	default:
+		e.logger.Warn().Str("event", string(event.Type)).Str("hub", event.Hub.Name).Msg("rules: event queue is full, event dropped")
+	}
+}
+
+// Start запускает обработку событий
+func (e *Engine) Start() {
+	go func() {
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case event := <-e.events:
+				e.handle(event)
+			}
+		}
+	}()
+}
+
+func (e *Engine) handle(event model.HubEvent) {
+	rules, err := e.ruleRepo.FindEnabledRulesByEvent(e.ctx, event.Type)
+	if err != nil {
+		e.logger.Error().Err(err).Msg("rules: failed to find rules")
+		return
+	}
+
+	for _, rule := range rules {
+		log := e.logger.With().Int64("ruleID", rule.ID).Str("tgUser", rule.TGUser).Logger()
+		if !e.match(rule, event) {
+			continue
+		}
+		fired, err := e.ruleRepo.Fire(e.ctx, rule.ID, event.At)
+		if err != nil {
+			log.Error().Err(err).Msg("rules: failed to update rule")
+			continue
+		}
+		if !fired {
+			log.Debug().Msg("rules: rule is on cooldown")
+			continue
+		}
+		if e.looping(rule.ID, event.At) {
+			log.Error().Msgf("rules: rule fired more than %d times in %v, disabled", e.loopMaxFires, e.loopWindow)
+			if err := e.ruleRepo.Disable(e.ctx, rule.ID); err != nil {
+				log.Error().Err(err).Msg("rules: failed to disable rule")
+			}
+			continue
+		}
+
+		log.Info().Str("event", string(event.Type)).Str("hub", event.Hub.Name).Msgf("rules: rule %s fired", rule.Name)
+		// Сценарии могут содержать задержки, поэтому действия не блокируют обработку следующих событий
+		go func(rule Rule) {
+			for i, action := range rule.Actions {
+				if err := e.run(rule, action, event); err != nil {
+					log.Error().Err(err).Msgf("rules: action %d (%s) failed", i+1, action.Type)
+				}
+			}
+		}(rule)
+	}
+}
+
+// match проверяет условия правила, владелец правила должен иметь доступ к хабу - источнику события
+func (e *Engine) match(rule Rule, event model.HubEvent) bool {
+	c := rule.Condition
+	if len(c.Hub) != 0 && c.Hub != event.Hub.Name {
+		return false
+	}
+	if event.Type == pkgmodel.EventNotification && !c.MatchNotify(event.Notify) {
+		return false
+	}
+	if !c.InWindow(event.At, rule.Location()) {
+		return false
+	}
+	for _, name := range c.HubsOnline {
+		if _, ok := e.clients.Get(name); !ok {
+			return false
+		}
+	}
+	for _, name := range c.HubsOffline {
+		if _, ok := e.clients.Get(name); ok {
+			return false
+		}
+	}
+	if _, err := e.hubService.AuthorizeByID(e.ctx, rule.TGUser, event.Hub.ID, pkgmodel.ViewStatus); err != nil {
+		return false
+	}
+	return true
+}
+
+// looping учитывает срабатывание правила и проверяет, не превышен ли лимит срабатываний за окно loopWindow
+func (e *Engine) looping(ruleID int64, now time.Time) bool {
+	recent := e.fires[ruleID][:0]
+	for _, t := range e.fires[ruleID] {
+		if now.Sub(t) < e.loopWindow {
+			recent = append(recent, t)
+		}
+	}
+	recent = append(recent, now)
+	e.fires[ruleID] = recent
+	return len(recent) > e.loopMaxFires
+}
+
+func (e *Engine) run(rule Rule, action pkgmodel.RuleAction, event model.HubEvent) error {
+	switch action.Type {
+	case pkgmodel.RuleActionDevice:
+		hub, err := e.hubService.Authorize(e.ctx, rule.TGUser, action.Hub, pkgmodel.SendActions)
+		if err != nil {
+			return err
+		}
+		a, err := pkgmodel.NewAction(action.Action)
+		if err != nil {
+			return err
+		}
+		return e.policyService.Execute(e.ctx, devices.ActionRequest{
+			TGUser:   rule.TGUser,
+			HubID:    hub.ID,
+			DeviceID: action.DeviceID,
+			Action:   a,
+			Source:   devices.SourceRule,
+		})
+	case pkgmodel.RuleActionNotify:
+		hub, err := e.hubService.Authorize(e.ctx, rule.TGUser, action.Hub, pkgmodel.SendActions)
+		if err != nil {
+			return err
+		}
+		severity, err := pkgmodel.NewSeverity(string(action.Severity))
+		if err != nil {
+			return err
+		}
+		e.notifier.Notify(e.ctx, hub, pkgmodel.NotifyEvent{
+			AlertID:  fmt.Sprintf("rule:%d", rule.ID),
+			Text:     notifyText(rule, action, event),
+			Severity: severity,
+		})
+		return nil
+	case pkgmodel.RuleActionScene:
+		_, results, err := e.sceneService.RunScene(e.ctx, rule.TGUser, action.SceneID)
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			if r.Err != nil {
+				return fmt.Errorf("scene step %s: %w", r.Step.DeviceID, r.Err)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("rules: unknown action type <%s>", action.Type)
+}
+
+// notifyText текст уведомления правила, по умолчанию пересылается текст исходного события
+func notifyText(rule Rule, action pkgmodel.RuleAction, event model.HubEvent) string {
+	if len(action.Text) != 0 {
+		return action.Text
+	}
+	switch event.Type {
+	case pkgmodel.EventHubOnline:
+		return fmt.Sprintf("%s: хаб %s подключен", rule.Name, event.Hub.Name)
+	case pkgmodel.EventHubOffline:
+		return fmt.Sprintf("%s: хаб %s отключен", rule.Name, event.Hub.Name)
+	}
+	return fmt.Sprintf("%s: %s", rule.Name, event.Notify.Text)
+}
+
+// NewEngine создает движок правил автоматизации
+func NewEngine(
+	ctx context.Context,
+	ruleRepo RuleRepository,
+	hubService hubs.HubService,
+	policyService devices.PolicyService,
+	sceneService scenes.SceneService,
+	notifier model.HubNotifier,
+	clients *collections.ConcurrentMap[string, *model.ClientEvents],
+	queueSize int,
+	loopWindow time.Duration,
+	loopMaxFires int,
+	logger zerolog.Logger,
+) *Engine {
+	return &Engine{
+		ctx:           ctx,
+		ruleRepo:      ruleRepo,
+		hubService:    hubService,
+		policyService: policyService,
+		sceneService:  sceneService,
+		notifier:      notifier,
+		clients:       clients,
+		events:        make(chan model.HubEvent, queueSize),
+		fires:         make(map[int64][]time.Time),
+		loopWindow:    loopWindow,
+		loopMaxFires:  loopMaxFires,
+		logger:        logger,
+	}
+}
@@ -0,0 +1,30 @@
+package rules
+
+import (
+	"time"
+
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// Rule правило автоматизации: при событии хаба, подходящем под условия, выполняются действия
+// действия выполняются от имени владельца правила с его правами в хабах
+type Rule struct {
+	ID        int64                  `db:"id"`
+	UserID    int64                  `db:"user_id"`
+	TGUser    string                 `db:"tg_user"`
+	Timezone  string                 `db:"timezone"`
+	Name      string                 `db:"name"`
+	Condition pkgmodel.RuleCondition `db:"condition"`
+	Actions   pkgmodel.RuleActions   `db:"actions"`
+	// CooldownSec минимальный интервал между срабатываниями правила
+	CooldownSec int `db:"cooldown_sec"`
+}
+
+// Location часовой пояс владельца правила для окна времени
+func (r Rule) Location() *time.Location {
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"context"
+	"time"
+
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// RuleRepository описывает методы работы с правилами автоматизации
+type RuleRepository interface {
+	// FindEnabledRulesByEvent возвращает включенные правила, срабатывающие на событие указанного типа
+	FindEnabledRulesByEvent(ctx context.Context, event pkgmodel.RuleEvent) ([]Rule, error)
+	// Fire отмечает срабатывание правила, если с прошлого срабатывания прошло больше cooldown
+	// возвращает false, если правило еще не может сработать
+	Fire(ctx context.Context, id int64, now time.Time) (bool, error)
+	// Disable отключает правило
+	Disable(ctx context.Context, id int64) error
+}
+
+type SQLRuleRepo struct {
+	db *sqlx.DB
+}
+
+func (r SQLRuleRepo) FindEnabledRulesByEvent(ctx context.Context, event pkgmodel.RuleEvent) ([]Rule, error) {
+	const sqlQuery = `SELECT r.id, r.user_id, u.tg_user, u.timezone, r.name, r.condition, r.actions, r.cooldown_sec
+		FROM rules r JOIN users u ON u.id = r.user_id
+		WHERE r.enabled AND r.condition ->> 'event' = $1
+		ORDER BY r.id`
+
+	result := make([]Rule, 0)
+	err := r.db.SelectContext(ctx, &result, sqlQuery, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r SQLRuleRepo) Fire(ctx context.Context, id int64, now time.Time) (bool, error) {
+	const sqlQuery = `UPDATE rules SET last_fired_at = $2
+		WHERE id = $1 AND enabled
+		  AND (last_fired_at IS NULL OR last_fired_at + make_interval(secs => cooldown_sec) <= $2)`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, id, now)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r SQLRuleRepo) Disable(ctx context.Context, id int64) error {
+	const sqlQuery = `UPDATE rules SET enabled = false WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlQuery, id)
+	return err
+}
+
+func NewRepo(db *sqlx.DB) SQLRuleRepo {
+	return SQLRuleRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,48 @@
+package scenes
+
+import (
+	"time"
+)
+
+// Scene именованная группа команд, выполняемых последовательно на одном хабе
+type Scene struct {
+	ID         int64  `db:"id"`
+	UserID     int64  `db:"user_id"`
+	ClientName string `db:"client_name"`
+	Name       string `db:"name"`
+	// StopOnFailure прерывает выполнение сценария после первого неудачного шага
+	StopOnFailure bool   `db:"stop_on_failure"`
+	TGUser        string `db:"tg_user"`
+	Steps         []Step `db:"-"`
+}
+
+// Step шаг сценария
+type Step struct {
+	SceneID  int64  `db:"scene_id"`
+	Position int    `db:"position"`
+	DeviceID string `db:"device_id"`
+	Action   string `db:"action"`
+	// DelayMs задержка перед выполнением шага
+	DelayMs int64 `db:"delay_ms"`
+}
+
+// Delay задержка перед выполнением шага
+func (s Step) Delay() time.Duration {
+	return time.Duration(s.DelayMs) * time.Millisecond
+}
+
+// StepResult результат выполнения шага сценария
+type StepResult struct {
+	Step    Step
+	Err     error
+	Skipped bool
+}
+
+// NewSceneRequest запрос на создание сценария
+type NewSceneRequest struct {
+	TGUser        string
+	ClientName    string
+	Name          string
+	StopOnFailure bool
+	Steps         []Step
+}
@@ -0,0 +1,127 @@
+package scenes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/repository"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// SceneRepository описывает методы работы со сценариями
+type SceneRepository interface {
+	// SaveScene сохраняет сценарий вместе с шагами
+	SaveScene(ctx context.Context, scene Scene) (int64, error)
+	// FindScenesByTGUser возвращает сценарии пользователя без шагов
+	FindScenesByTGUser(ctx context.Context, tgUser string) ([]Scene, error)
+	// FindSceneByTGUser возвращает сценарий пользователя вместе с шагами
+	FindSceneByTGUser(ctx context.Context, tgUser string, id int64) (Scene, error)
+	// DeleteSceneByTGUser удаляет сценарий, если он принадлежит пользователю
+	DeleteSceneByTGUser(ctx context.Context, tgUser string, id int64) error
+}
+
+type SQLSceneRepo struct {
+	db *sqlx.DB
+}
+
+const selectScenes = `SELECT s.id, s.user_id, c.name AS client_name, s.name, s.stop_on_failure, u.tg_user
+FROM scenes s
+         JOIN users u ON u.id = s.user_id
+         JOIN clients c ON c.id = s.client_id`
+
+func (r SQLSceneRepo) SaveScene(ctx context.Context, scene Scene) (int64, error) {
+	const sceneQuery = `INSERT INTO scenes(user_id, client_id, name, stop_on_failure)
+			SELECT u.id, c.id, $3, $4
+			FROM users u JOIN hub_members m ON m.user_id = u.id JOIN clients c ON c.id = m.client_id
+			WHERE u.tg_user = $1 AND c.name = $2
+			RETURNING id`
+	const stepQuery = `INSERT INTO scene_steps(scene_id, position, device_id, action, delay_ms)
+			VALUES(:scene_id, :position, :device_id, :action, :delay_ms)`
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var id int64
+	err = tx.GetContext(ctx, &id, sceneQuery, scene.TGUser, scene.ClientName, scene.Name, scene.StopOnFailure)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, repository.ErrNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, repository.ErrAlreadyExists
+		}
+		return 0, err
+	}
+
+	for i, step := range scene.Steps {
+		step.SceneID = id
+		step.Position = i
+		if _, err := tx.NamedExecContext(ctx, stepQuery, step); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, tx.Commit()
+}
+
+func (r SQLSceneRepo) FindScenesByTGUser(ctx context.Context, tgUser string) ([]Scene, error) {
+	const sqlQuery = selectScenes + ` WHERE u.tg_user = $1 ORDER BY s.name`
+
+	var result []Scene
+	err := r.db.SelectContext(ctx, &result, sqlQuery, tgUser)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r SQLSceneRepo) FindSceneByTGUser(ctx context.Context, tgUser string, id int64) (Scene, error) {
+	const sceneQuery = selectScenes + ` WHERE u.tg_user = $1 AND s.id = $2`
+	const stepsQuery = `SELECT scene_id, position, device_id, action, delay_ms FROM scene_steps WHERE scene_id = $1 ORDER BY position`
+
+	scene := Scene{}
+	err := r.db.GetContext(ctx, &scene, sceneQuery, tgUser, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Scene{}, repository.ErrNotFound
+		}
+		return Scene{}, err
+	}
+
+	err = r.db.SelectContext(ctx, &scene.Steps, stepsQuery, id)
+	if err != nil {
+		return Scene{}, err
+	}
+
+	return scene, nil
+}
+
+func (r SQLSceneRepo) DeleteSceneByTGUser(ctx context.Context, tgUser string, id int64) error {
+	const sqlQuery = `DELETE FROM scenes s USING users u WHERE s.user_id = u.id AND u.tg_user = $1 AND s.id = $2`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, tgUser, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func NewRepo(db *sqlx.DB) SQLSceneRepo {
+	return SQLSceneRepo{
+		db: db,
+	}
+}
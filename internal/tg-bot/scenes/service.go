@@ -0,0 +1,148 @@
+package scenes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/devices"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+var (
+	ErrEmptyScene = errors.New("scenes: scene has no steps")
+)
+
+// SceneService сервис управления и запуска сценариев
+type SceneService interface {
+	// NewScene проверяет шаги и сохраняет сценарий
+	NewScene(ctx context.Context, req NewSceneRequest) (Scene, error)
+	// FindScenesByTGName возвращает сценарии пользователя
+	FindScenesByTGName(ctx context.Context, tgName string) ([]Scene, error)
+	// DeleteScene удаляет сценарий пользователя
+	DeleteScene(ctx context.Context, tgName string, id int64) error
+	// RunScene последовательно отправляет команды шагов сценария на хаб и возвращает результат каждого шага
+	RunScene(ctx context.Context, tgName string, id int64) (Scene, []StepResult, error)
+}
+
+type SceneServiceImpl struct {
+	sceneRepo     SceneRepository
+	hubService    hubs.HubService
+	policyService devices.PolicyService
+}
+
+func (s SceneServiceImpl) NewScene(ctx context.Context, req NewSceneRequest) (Scene, error) {
+	if len(req.Steps) == 0 {
+		return Scene{}, ErrEmptyScene
+	}
+	hub, err := s.hubService.Authorize(ctx, req.TGUser, req.ClientName, pkgmodel.SendActions)
+	if err != nil {
+		return Scene{}, err
+	}
+	for i, step := range req.Steps {
+		action, err := pkgmodel.NewAction(step.Action)
+		if err != nil {
+			return Scene{}, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		// Устройства, требующие подтверждения или PIN-кода, в сценарии не добавляются
+		_, err = s.policyService.Check(ctx, devices.ActionRequest{
+			TGUser:   req.TGUser,
+			HubID:    hub.ID,
+			DeviceID: step.DeviceID,
+			Action:   action,
+			Source:   devices.SourceScene,
+		})
+		if err != nil {
+			return Scene{}, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		req.Steps[i].Action = action.String()
+	}
+
+	scene := Scene{
+		ClientName:    req.ClientName,
+		Name:          req.Name,
+		StopOnFailure: req.StopOnFailure,
+		TGUser:        req.TGUser,
+		Steps:         req.Steps,
+	}
+	id, err := s.sceneRepo.SaveScene(ctx, scene)
+	if err != nil {
+		return Scene{}, fmt.Errorf("save scene: %w", err)
+	}
+	scene.ID = id
+
+	return scene, nil
+}
+
+func (s SceneServiceImpl) FindScenesByTGName(ctx context.Context, tgName string) ([]Scene, error) {
+	return s.sceneRepo.FindScenesByTGUser(ctx, tgName)
+}
+
+func (s SceneServiceImpl) DeleteScene(ctx context.Context, tgName string, id int64) error {
+	return s.sceneRepo.DeleteSceneByTGUser(ctx, tgName, id)
+}
+
+func (s SceneServiceImpl) RunScene(ctx context.Context, tgName string, id int64) (Scene, []StepResult, error) {
+	scene, err := s.sceneRepo.FindSceneByTGUser(ctx, tgName, id)
+	if err != nil {
+		return Scene{}, nil, fmt.Errorf("find scene: %w", err)
+	}
+	hub, err := s.hubService.Authorize(ctx, tgName, scene.ClientName, pkgmodel.SendActions)
+	if err != nil {
+		return Scene{}, nil, err
+	}
+
+	results := make([]StepResult, 0, len(scene.Steps))
+	failed := false
+	for _, step := range scene.Steps {
+		if failed && scene.StopOnFailure {
+			results = append(results, StepResult{Step: step, Skipped: true})
+			continue
+		}
+
+		if step.DelayMs > 0 {
+			select {
+			case <-ctx.Done():
+				return scene, results, ctx.Err()
+			case <-time.After(step.Delay()):
+			}
+		}
+
+		err := s.runStep(ctx, tgName, hub, step)
+		if err != nil {
+			failed = true
+		}
+		results = append(results, StepResult{Step: step, Err: err})
+	}
+
+	return scene, results, nil
+}
+
+func (s SceneServiceImpl) runStep(ctx context.Context, tgName string, hub hubs.Hub, step Step) error {
+	action, err := pkgmodel.NewAction(step.Action)
+	if err != nil {
+		return err
+	}
+	return s.policyService.Execute(ctx, devices.ActionRequest{
+		TGUser:   tgName,
+		HubID:    hub.ID,
+		DeviceID: step.DeviceID,
+		Action:   action,
+		Source:   devices.SourceScene,
+	})
+}
+
+// NewSceneService создает сервис сценариев
+func NewSceneService(
+	sceneRepo SceneRepository,
+	hubService hubs.HubService,
+	policyService devices.PolicyService,
+) SceneServiceImpl {
+	return SceneServiceImpl{
+		sceneRepo:     sceneRepo,
+		hubService:    hubService,
+		policyService: policyService,
+	}
+}
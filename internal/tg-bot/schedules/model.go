@@ -0,0 +1,122 @@
+package schedules
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Kind тип расписания
+type Kind string
+
+const (
+	// Once однократное выполнение в указанное время
+	Once Kind = "once"
+	// Cron периодическое выполнение по cron выражению
+	Cron Kind = "cron"
+)
+
+// MisfirePolicy определяет, что делать с запуском, пропущенным во время простоя сервера
+type MisfirePolicy string
+
+const (
+	// Skip пропущенный запуск не выполняется, расписание переходит к следующему времени
+	Skip MisfirePolicy = "skip"
+	// CatchUp пропущенный запуск выполняется один раз сразу после старта
+	CatchUp MisfirePolicy = "catchup"
+)
+
+// OnceLayout формат времени для однократного расписания
+const OnceLayout = "2006-01-02 15:04"
+
+// Schedule описывает запланированную команду для устройства
+type Schedule struct {
+	ID         int64         `db:"id"`
+	UserID     int64         `db:"user_id"`
+	ClientID   int64         `db:"client_id"`
+	ClientName string        `db:"client_name"`
+	DeviceID   string        `db:"device_id"`
+	Action     string        `db:"action"`
+	Kind       Kind          `db:"kind"`
+	Spec       string        `db:"spec"`
+	Misfire    MisfirePolicy `db:"misfire"`
+	NextRunAt  time.Time     `db:"next_run_at"`
+	LastRunAt  sql.NullTime  `db:"last_run_at"`
+	// FailedAt время, когда однократное расписание не удалось выполнить до окончания допустимой задержки
+	FailedAt sql.NullTime `db:"failed_at"`
+	// LastError ошибка последнего запуска, сбрасывается после успешного запуска
+	LastError sql.NullString `db:"last_error"`
+	// TGUser, ChatID, Timezone и Language заполняются из таблицы пользователей
+	TGUser   string `db:"tg_user"`
+	ChatID   int64  `db:"chat_id"`
+	Timezone string `db:"timezone"`
+	Language string `db:"language"`
+}
+
+// NewScheduleRequest запрос на создание расписания
+type NewScheduleRequest struct {
+	TGUser     string
+	ClientName string
+	DeviceID   string
+	Action     string
+	Spec       string
+	Misfire    MisfirePolicy
+}
+
+// Location возвращает часовой пояс владельца расписания, при ошибке используется UTC
+func (s Schedule) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Next вычисляет время следующего запуска после from в часовом поясе пользователя.
+// Для однократного расписания возвращает false, если время уже прошло
+func Next(kind Kind, spec string, loc *time.Location, from time.Time) (time.Time, bool, error) {
+	switch kind {
+	case Once:
+		t, err := time.ParseInLocation(OnceLayout, spec, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("schedules: failed to parse time <%s>, %w", spec, err)
+		}
+		return t, t.After(from), nil
+	case Cron:
+		sched, err := cron.ParseStandard(spec)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("schedules: failed to parse cron spec <%s>, %w", spec, err)
+		}
+		next := sched.Next(from.In(loc))
+		return next, !next.IsZero(), nil
+	}
+	return time.Time{}, false, fmt.Errorf("schedules: unknown kind <%s>", kind)
+}
+
+// ParseSpec определяет тип расписания по строке: "2006-01-02 15:04" - однократное,
+// пять полей или дескриптор вида @daily - cron выражение
+func ParseSpec(spec string) (Kind, string, error) {
+	fields := strings.Fields(spec)
+	spec = strings.Join(fields, " ")
+	switch {
+	case len(fields) == 2 && strings.Contains(fields[0], "-"):
+		return Once, spec, nil
+	case len(fields) == 5, len(fields) == 1 && strings.HasPrefix(fields[0], "@"):
+		return Cron, spec, nil
+	}
+	return "", "", fmt.Errorf("schedules: unsupported spec <%s>", spec)
+}
+
+// NewMisfirePolicy создает политику пропущенных запусков из строки
+func NewMisfirePolicy(s string) (MisfirePolicy, error) {
+	switch MisfirePolicy(strings.ToLower(s)) {
+	case Skip:
+		return Skip, nil
+	case CatchUp:
+		return CatchUp, nil
+	}
+	return "", fmt.Errorf("schedules: unknown misfire policy <%s>", s)
+}
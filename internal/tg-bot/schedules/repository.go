@@ -0,0 +1,142 @@
+package schedules
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+// ScheduleRepository описывает методы работы с расписаниями
+type ScheduleRepository interface {
+	// SaveSchedule сохраняет новое расписание, хаб ищется по имени среди хабов пользователя
+	SaveSchedule(ctx context.Context, schedule Schedule) (int64, error)
+	// FindSchedulesByTGUser возвращает расписания пользователя
+	FindSchedulesByTGUser(ctx context.Context, tgUser string) ([]Schedule, error)
+	// FindDueSchedules возвращает расписания, время запуска которых наступило
+	FindDueSchedules(ctx context.Context, now time.Time) ([]Schedule, error)
+	// UpdateNextRun сохраняет время следующего и последнего успешного запуска и ошибку последнего запуска
+	UpdateNextRun(ctx context.Context, id int64, nextRunAt time.Time, lastRunAt sql.NullTime, lastError sql.NullString) error
+	// MarkFailed помечает расписание как невыполненное, такое расписание больше не запускается
+	MarkFailed(ctx context.Context, id int64, failedAt time.Time, reason string) error
+	// DeleteSchedule удаляет расписание по идентификатору
+	DeleteSchedule(ctx context.Context, id int64) error
+	// DeleteScheduleByTGUser удаляет расписание, если оно принадлежит пользователю
+	DeleteScheduleByTGUser(ctx context.Context, tgUser string, id int64) error
+}
+
+type SQLScheduleRepo struct {
+	db *sqlx.DB
+}
+
+const selectSchedules = `SELECT s.id, s.user_id, s.client_id, c.name AS client_name, s.device_id, s.action, s.kind, s.spec,
+       s.misfire, s.next_run_at, s.last_run_at, s.failed_at, s.last_error, u.tg_user,
+       COALESCE(u.chat_id, 0) AS chat_id, u.timezone, u.language
+FROM schedules s
+         JOIN users u ON u.id = s.user_id
+         JOIN clients c ON c.id = s.client_id`
+
+func (r SQLScheduleRepo) SaveSchedule(ctx context.Context, schedule Schedule) (int64, error) {
+	const sqlQuery = `INSERT INTO schedules(user_id, client_id, device_id, action, kind, spec, misfire, next_run_at)
+			SELECT u.id, c.id, $3, $4, $5, $6, $7, $8
+			FROM users u JOIN hub_members m ON m.user_id = u.id JOIN clients c ON c.id = m.client_id
+			WHERE u.tg_user = $1 AND c.name = $2
+			RETURNING id`
+
+	var id int64
+	err := r.db.GetContext(ctx, &id, sqlQuery, schedule.TGUser, schedule.ClientName, schedule.DeviceID, schedule.Action,
+		schedule.Kind, schedule.Spec, schedule.Misfire, schedule.NextRunAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, repository.ErrNotFound
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r SQLScheduleRepo) FindSchedulesByTGUser(ctx context.Context, tgUser string) ([]Schedule, error) {
+	const sqlQuery = selectSchedules + ` WHERE u.tg_user = $1 ORDER BY s.next_run_at`
+
+	var result []Schedule
+	err := r.db.SelectContext(ctx, &result, sqlQuery, tgUser)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r SQLScheduleRepo) FindDueSchedules(ctx context.Context, now time.Time) ([]Schedule, error) {
+	const sqlQuery = selectSchedules + ` WHERE s.next_run_at <= $1 AND s.failed_at IS NULL ORDER BY s.next_run_at`
+
+	var result []Schedule
+	err := r.db.SelectContext(ctx, &result, sqlQuery, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r SQLScheduleRepo) UpdateNextRun(ctx context.Context, id int64, nextRunAt time.Time, lastRunAt sql.NullTime, lastError sql.NullString) error {
+	const sqlQuery = `UPDATE schedules SET next_run_at = $2, last_run_at = $3, last_error = left($4, 256) WHERE id = $1`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, id, nextRunAt, lastRunAt, lastError)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r SQLScheduleRepo) MarkFailed(ctx context.Context, id int64, failedAt time.Time, reason string) error {
+	const sqlQuery = `UPDATE schedules SET failed_at = $2, last_error = left($3, 256) WHERE id = $1`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, id, failedAt, reason)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r SQLScheduleRepo) DeleteSchedule(ctx context.Context, id int64) error {
+	const sqlQuery = `DELETE FROM schedules WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlQuery, id)
+	return err
+}
+
+func (r SQLScheduleRepo) DeleteScheduleByTGUser(ctx context.Context, tgUser string, id int64) error {
+	const sqlQuery = `DELETE FROM schedules s USING users u WHERE s.user_id = u.id AND u.tg_user = $1 AND s.id = $2`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, tgUser, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func NewRepo(db *sqlx.DB) SQLScheduleRepo {
+	return SQLScheduleRepo{
+		db: db,
+	}
+}
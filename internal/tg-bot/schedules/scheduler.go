@@ -0,0 +1,168 @@
+package schedules
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/devices"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/i18n"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+	"github.com/rs/zerolog"
+)
+
+// Scheduler периодически выбирает из БД наступившие расписания и отправляет команды клиентским приложениям.
+// Запуск считается пропущенным, если с момента запланированного времени прошло больше misfireGrace,
+// например, после простоя сервера. Пропущенные запуски выполняются один раз или пропускаются
+// в зависимости от политики расписания. Однократное расписание, команду которого не удалось отправить,
+// повторяется до окончания misfireGrace, после чего помечается невыполненным, а владелец получает уведомление.
+// Периодическое расписание после ошибки переходит к следующему запуску, ошибка сохраняется до успешного запуска,
+// владелец получает уведомление о первой ошибке.
+type Scheduler struct {
+	ctx           context.Context
+	scheduleRepo  ScheduleRepository
+	hubService    hubs.HubService
+	policyService devices.PolicyService
+	interval      time.Duration
+	misfireGrace  time.Duration
+	notify        chan<- model.Notification
+	logger        zerolog.Logger
+}
+
+// fire отправляет команду расписания, если у автора расписания сохранилось право управлять устройством
+func (s *Scheduler) fire(schedule Schedule) error {
+	hub, err := s.hubService.Authorize(s.ctx, schedule.TGUser, schedule.ClientName, pkgmodel.SendActions)
+	if err != nil {
+		return err
+	}
+	action, err := pkgmodel.NewAction(schedule.Action)
+	if err != nil {
+		return err
+	}
+	return s.policyService.Execute(s.ctx, devices.ActionRequest{
+		TGUser:   schedule.TGUser,
+		HubID:    hub.ID,
+		DeviceID: schedule.DeviceID,
+		Action:   action,
+		Source:   devices.SourceSchedule,
+	})
+}
+
+// fail помечает однократное расписание невыполненным и сообщает об этом владельцу
+func (s *Scheduler) fail(schedule Schedule, now time.Time, reason error, log zerolog.Logger) {
+	if err := s.scheduleRepo.MarkFailed(s.ctx, schedule.ID, now, reason.Error()); err != nil {
+		log.Error().Err(err).Msg("scheduler: failed to mark schedule as failed")
+		return
+	}
+	log.Warn().Msgf("scheduler: schedule failed, action %s was not sent to %s", schedule.Action, schedule.DeviceID)
+	s.notifyOwner(schedule, "schedule.failed", schedule.ID, schedule.ClientName, schedule.DeviceID, schedule.Action, reason)
+}
+
+// notifyOwner отправляет владельцу расписания сообщение на его языке
+func (s *Scheduler) notifyOwner(schedule Schedule, key string, args ...any) {
+	if schedule.ChatID == 0 || s.notify == nil {
+		return
+	}
+
+	lang, _ := i18n.Parse(schedule.Language)
+	n := model.NewNotification(schedule.ChatID, i18n.New(lang).T(key, args...))
+	select {
+	case s.notify <- n:
+	case <-s.ctx.Done():
+	}
+}
+
+func (s *Scheduler) process(now time.Time) {
+	due, err := s.scheduleRepo.FindDueSchedules(s.ctx, now)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("scheduler: failed to find due schedules")
+		return
+	}
+
+	for _, schedule := range due {
+		log := s.logger.With().Int64("scheduleID", schedule.ID).Str("tgUser", schedule.TGUser).Logger()
+
+		missed := now.Sub(schedule.NextRunAt) > s.misfireGrace
+		lastRunAt := schedule.LastRunAt
+		var lastError sql.NullString
+		if !missed || schedule.Misfire == CatchUp {
+			if err := s.fire(schedule); err != nil {
+				log.Error().Err(err).Msgf("scheduler: failed to send action %s to %s", schedule.Action, schedule.DeviceID)
+				// Однократное расписание остается в очереди и повторяется на следующих проверках
+				if schedule.Kind == Once {
+					if missed {
+						s.fail(schedule, now, err, log)
+					}
+					continue
+				}
+				lastError = sql.NullString{String: err.Error(), Valid: true}
+			} else {
+				log.Info().Msgf("scheduler: action %s sent to %s", schedule.Action, schedule.DeviceID)
+				lastRunAt = sql.NullTime{Time: now, Valid: true}
+			}
+		} else {
+			log.Warn().Time("nextRunAt", schedule.NextRunAt).Msg("scheduler: missed run skipped")
+			lastError = schedule.LastError
+		}
+
+		next, ok, err := Next(schedule.Kind, schedule.Spec, schedule.Location(), now)
+		if err != nil || !ok {
+			if err != nil {
+				log.Error().Err(err).Send()
+			}
+			if err := s.scheduleRepo.DeleteSchedule(s.ctx, schedule.ID); err != nil {
+				log.Error().Err(err).Msg("scheduler: failed to delete schedule")
+			}
+			continue
+		}
+		if err := s.scheduleRepo.UpdateNextRun(s.ctx, schedule.ID, next, lastRunAt, lastError); err != nil {
+			log.Error().Err(err).Msg("scheduler: failed to update schedule")
+		}
+		// о повторяющихся ошибках владелец не уведомляется, пока расписание не выполнится успешно
+		if lastError.Valid && !schedule.LastError.Valid {
+			s.notifyOwner(schedule, "schedule.run_failed", schedule.ID, schedule.ClientName, schedule.DeviceID, schedule.Action,
+				lastError.String, next.In(schedule.Location()).Format(OnceLayout))
+		}
+	}
+}
+
+// Start запускает циклическую проверку расписаний, уведомления об ошибках расписаний отправляются в notify
+func (s *Scheduler) Start(notify chan<- model.Notification) {
+	s.notify = notify
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		s.process(time.Now())
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.process(now)
+			}
+		}
+	}()
+}
+
+// NewScheduler создает планировщик команд
+func NewScheduler(
+	ctx context.Context,
+	scheduleRepo ScheduleRepository,
+	hubService hubs.HubService,
+	policyService devices.PolicyService,
+	interval time.Duration,
+	misfireGrace time.Duration,
+	logger zerolog.Logger,
+) *Scheduler {
+	return &Scheduler{
+		ctx:           ctx,
+		scheduleRepo:  scheduleRepo,
+		hubService:    hubService,
+		policyService: policyService,
+		interval:      interval,
+		misfireGrace:  misfireGrace,
+		logger:        logger,
+	}
+}
@@ -0,0 +1,105 @@
+package schedules
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/devices"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// ScheduleService сервис управления расписаниями пользователя
+type ScheduleService interface {
+	// NewSchedule проверяет запрос, вычисляет время первого запуска и сохраняет расписание
+	NewSchedule(ctx context.Context, req NewScheduleRequest, timezone string) (Schedule, error)
+	// FindSchedulesByTGName возвращает расписания пользователя
+	FindSchedulesByTGName(ctx context.Context, tgName string) ([]Schedule, error)
+	// DeleteSchedule удаляет расписание пользователя
+	DeleteSchedule(ctx context.Context, tgName string, id int64) error
+}
+
+type ScheduleServiceImpl struct {
+	scheduleRepo  ScheduleRepository
+	hubService    hubs.HubService
+	policyService devices.PolicyService
+}
+
+func (s ScheduleServiceImpl) NewSchedule(ctx context.Context, req NewScheduleRequest, timezone string) (Schedule, error) {
+	action, err := pkgmodel.NewAction(req.Action)
+	if err != nil {
+		return Schedule{}, err
+	}
+	hub, err := s.hubService.Authorize(ctx, req.TGUser, req.ClientName, pkgmodel.SendActions)
+	if err != nil {
+		return Schedule{}, err
+	}
+	// Устройства, требующие подтверждения или PIN-кода, по расписанию не управляются
+	_, err = s.policyService.Check(ctx, devices.ActionRequest{
+		TGUser:   req.TGUser,
+		HubID:    hub.ID,
+		DeviceID: req.DeviceID,
+		Action:   action,
+		Source:   devices.SourceSchedule,
+	})
+	if err != nil {
+		return Schedule{}, err
+	}
+	kind, spec, err := ParseSpec(req.Spec)
+	if err != nil {
+		return Schedule{}, err
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("schedules: invalid timezone %q, %w", timezone, err)
+	}
+	next, ok, err := Next(kind, spec, loc, time.Now())
+	if err != nil {
+		return Schedule{}, err
+	}
+	if !ok {
+		return Schedule{}, fmt.Errorf("schedules: time <%s> is in the past", spec)
+	}
+
+	misfire := req.Misfire
+	if len(misfire) == 0 {
+		misfire = Skip
+	}
+
+	schedule := Schedule{
+		ClientName: req.ClientName,
+		DeviceID:   req.DeviceID,
+		Action:     action.String(),
+		Kind:       kind,
+		Spec:       spec,
+		Misfire:    misfire,
+		NextRunAt:  next,
+		TGUser:     req.TGUser,
+		Timezone:   loc.String(),
+	}
+	id, err := s.scheduleRepo.SaveSchedule(ctx, schedule)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("save schedule: %w", err)
+	}
+	schedule.ID = id
+
+	return schedule, nil
+}
+
+func (s ScheduleServiceImpl) FindSchedulesByTGName(ctx context.Context, tgName string) ([]Schedule, error) {
+	return s.scheduleRepo.FindSchedulesByTGUser(ctx, tgName)
+}
+
+func (s ScheduleServiceImpl) DeleteSchedule(ctx context.Context, tgName string, id int64) error {
+	return s.scheduleRepo.DeleteScheduleByTGUser(ctx, tgName, id)
+}
+
+// NewScheduleService создает сервис расписаний
+func NewScheduleService(scheduleRepo ScheduleRepository, hubService hubs.HubService, policyService devices.PolicyService) ScheduleServiceImpl {
+	return ScheduleServiceImpl{
+		scheduleRepo:  scheduleRepo,
+		hubService:    hubService,
+		policyService: policyService,
+	}
+}
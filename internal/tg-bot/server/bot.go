@@ -4,37 +4,45 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/configs"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/handlers"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/i18n"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/outbox"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/users"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/rs/zerolog"
 )
 
 // TGBot содержит объект для взаимодействия с telegram api и реализует модель обработчиков для команд, поступающих от tg api
 type TGBot struct {
-	ctx          context.Context
-	botApi       *tgbotapi.BotAPI
-	notification chan model.Notification
-	handler      MessageHandler
-	logger       zerolog.Logger
+	ctx     context.Context
+	botApi  *tgbotapi.BotAPI
+	sender  *outbox.Sender
+	handler MessageHandler
+	logger  zerolog.Logger
 }
 
-// notify отправляет сообщение в telegram api
-func (b *TGBot) notify(n model.Notification) error {
-	msg := tgbotapi.NewMessage(n.ChatID, n.Text)
-	if _, err := b.botApi.Send(msg); err != nil {
-		return err
+// notifyMessage формирует сообщение telegram api из уведомления
+// если у уведомления есть ключ источника, к сообщению добавляются кнопки временного отключения на языке получателя
+func notifyMessage(ctx context.Context, userService users.UserService) func(n model.Notification) tgbotapi.Chattable {
+	return func(n model.Notification) tgbotapi.Chattable {
+		msg := tgbotapi.NewMessage(n.ChatID, n.Text)
+		msg.DisableNotification = n.Silent
+		if len(n.AlertKey) != 0 {
+			msg.ReplyMarkup = handlers.MuteKeyboard(userService.ChatLocalizer(ctx, n.ChatID), n.AlertKey)
+		}
+		return msg
 	}
-	return nil
 }
 
 // GetNotifyChan отдает канал для отправки уведомлений в telegram
 func (b *TGBot) GetNotifyChan() chan<- model.Notification {
-	return b.notification
+	return b.sender.GetNotifyChan()
 }
 
 // ServeAndNotify запускает циклический опрос обновлений от telegram api,
-// а также опрашивает внутренний канал отправки уведомлений и перенаправляет сообщения в telegram
+// а также запускает очередь отправки уведомлений в telegram
 func (b *TGBot) ServeAndNotify() {
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
@@ -49,24 +57,25 @@ func (b *TGBot) ServeAndNotify() {
 			b.handler.ServeBotMessage(update, b.botApi)
 		}
 	}()
-	go func() {
-		for {
-			select {
-			case <-b.ctx.Done():
-				return
-			case n := <-b.notification:
-				err := b.notify(n)
-				if err != nil {
-					b.logger.Error().Err(err).Send()
-				}
-				continue
-			}
-		}
-	}()
+	b.sender.Start()
+}
+
+// SetMenuButton устанавливает кнопку меню бота по умолчанию, открывающую Mini App по адресу url,
+// в личных чатах пользователей надпись кнопки заменяется на язык пользователя
+func (b *TGBot) SetMenuButton(url string) error {
+	return handlers.SetMenuButton(b.botApi, 0, i18n.New(i18n.Default), url)
 }
 
 // NewTGBot настраивает и возвращает настроенного бота
-func NewTGBot(ctx context.Context, token string, handler MessageHandler, logger zerolog.Logger) (*TGBot, error) {
+func NewTGBot(
+	ctx context.Context,
+	token string,
+	senderCfg configs.SenderCfg,
+	outboxRepo outbox.OutboxRepository,
+	userService users.UserService,
+	handler MessageHandler,
+	logger zerolog.Logger,
+) (*TGBot, error) {
 	bot, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		return nil, err
@@ -76,11 +85,11 @@ func NewTGBot(ctx context.Context, token string, handler MessageHandler, logger
 
 	logger.Info().Msgf("remote-control-tg-bot: authorized on account: %s", bot.Self.UserName)
 	return &TGBot{
-		ctx:          ctx,
-		botApi:       bot,
-		notification: make(chan model.Notification, 1),
-		handler:      handler,
-		logger:       logger,
+		ctx:     ctx,
+		botApi:  bot,
+		sender:  outbox.NewSender(ctx, bot, notifyMessage(ctx, userService), outboxRepo, senderCfg, logger),
+		handler: handler,
+		logger:  logger,
 	}, nil
 }
 
@@ -95,6 +104,7 @@ type DefaultMessageHandler struct {
 	logger       zerolog.Logger
 	messages     map[string]func(update tgbotapi.Update, botApi *tgbotapi.BotAPI)
 	callbacks    map[string]func(update tgbotapi.Update, botApi *tgbotapi.BotAPI)
+	membership   func(update tgbotapi.Update, botApi *tgbotapi.BotAPI)
 	unknownRoute func(update tgbotapi.Update, botApi *tgbotapi.BotAPI)
 }
 
@@ -107,6 +117,14 @@ func (h *DefaultMessageHandler) ServeBotMessage(update tgbotapi.Update, botApi *
 			handler(update, botApi)
 			return
 		}
+		// Команды с аргументами ищутся только по имени команды, аргументы разбирает сам обработчик
+		if update.Message.IsCommand() {
+			handler, ok = h.messages["/"+update.Message.Command()]
+			if ok {
+				handler(update, botApi)
+				return
+			}
+		}
 	} else if update.CallbackQuery != nil {
 		handlerName := handlers.ParseReqHandler(update.CallbackQuery.Data)
 		if len(handlerName) != 0 {
@@ -142,6 +160,11 @@ func (h *DefaultMessageHandler) Callback(handlerName string, handler func(update
 	h.callbacks[handlerName] = handler
 }
 
+// Membership регистрирует обработчик изменения статуса бота в чате (добавление в группу, удаление из группы)
+func (h *DefaultMessageHandler) Membership(handler func(update tgbotapi.Update, botApi *tgbotapi.BotAPI)) {
+	h.membership = handler
+}
+
 // Unknown регистрирует обработчик для неизвестной команды
 func (h *DefaultMessageHandler) Unknown(handler func(update tgbotapi.Update, botApi *tgbotapi.BotAPI)) {
 	h.unknownRoute = handler
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/configs"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/services"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/users"
@@ -94,8 +95,10 @@ func NewGRPCServer(
 	config *configs.TGBotCfg,
 	logger zerolog.Logger,
 	clients *collections.ConcurrentMap[string, *model.ClientEvents],
-	notify chan<- model.Notification,
+	notifier model.HubNotifier,
+	events model.EventPublisher,
 	userService users.UserService,
+	hubService hubs.HubService,
 ) (*grpc.Server, error) {
 	creds, err := newServerCredentials(config, logger)
 	if err != nil {
@@ -104,7 +107,7 @@ func NewGRPCServer(
 	serverOptions := newServerOptions(logger, creds)
 	server := grpc.NewServer(serverOptions...)
 
-	proto.RegisterEventMultiServiceServer(server, services.NewEventMultiService(ctx, logger, clients, notify, userService))
+	proto.RegisterEventMultiServiceServer(server, services.NewEventMultiService(ctx, logger, clients, notifier, events, userService, hubService))
 
 	return server, err
 }
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/configs"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/control"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/devices"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/inbound"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/live"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/services"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/webapp"
+	"github.com/c0dered273/automation-remote-controller/pkg/loggers"
+	"github.com/c0dered273/automation-remote-controller/pkg/validators"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/rs/zerolog"
+)
+
+// eventsPaths маршруты потоков событий, которые не ограничиваются по времени
+var eventsPaths = map[string]bool{
+	"/internal/users/:tgUser/events": true,
+	"/webapp/api/events":             true,
+}
+
+// NewHTTPServer возвращает настроенный http сервер бота: входящие webhook, внутреннее API управления устройствами
+// и Telegram Mini App
+func NewHTTPServer(
+	config *configs.TGBotCfg,
+	s services.Services,
+	notifier model.HubNotifier,
+	logger zerolog.Logger,
+) *echo.Echo {
+	e := echo.New()
+	e.HideBanner = true
+	e.Logger = loggers.NewEchoLogger(LogWriter, "echo", logger)
+	e.Validator = validators.NewValidatorWithTagFieldName("json", logger)
+	e.Use(middleware.BodyLimit("1M"))
+	e.Use(middleware.TimeoutWithConfig(middleware.TimeoutConfig{
+		Skipper: func(c echo.Context) bool {
+			return eventsPaths[c.Path()]
+		},
+		Timeout:      15 * time.Second,
+		ErrorMessage: "Connection timeout",
+	}))
+	e.Use(loggers.RequestLoggerMiddleware(logger))
+	e.Use(middleware.Recover())
+
+	// Inbound webhooks
+	inboundService := inbound.NewInboundService(s.InboundRepo, s.HubService, notifier, logger)
+	w := e.Group("/webhooks", inbound.TokenAuth(inboundService))
+	w.POST("/alerts", inbound.ReceiveGeneric(inboundService))
+	w.POST("/alertmanager", inbound.ReceiveAlertmanager(inboundService))
+
+	// Internal API
+	if len(config.Internal.Token) != 0 {
+		i := e.Group("/internal", control.InternalAuth(config.Internal.Token))
+		i.GET("/users/:tgUser/hubs", control.FindHubs(s.ControlService, control.ParamUser))
+		i.GET("/users/:tgUser/hubs/:hubName/devices", control.ReadState(s.ControlService, control.ParamUser))
+		i.POST("/users/:tgUser/hubs/:hubName/devices/:deviceID/actions",
+			control.Execute(s.ControlService, control.ParamUser, devices.SourceAPI))
+		i.GET("/users/:tgUser/events", live.Stream(s.LiveBroker, s.HubService, config.Live.Heartbeat, control.ParamUser))
+	}
+
+	// Telegram Mini App
+	if len(config.WebApp.URL) != 0 {
+		e.GET("/webapp", func(c echo.Context) error {
+			return c.Redirect(http.StatusMovedPermanently, "/webapp/")
+		})
+		e.GET("/webapp/*", webapp.Static())
+		a := e.Group("/webapp/api", webapp.InitDataAuth(config.BotToken, config.WebApp.InitDataTTL))
+		a.GET("/hubs", control.FindHubs(s.ControlService, webapp.ContextUser))
+		a.GET("/hubs/:hubName/devices", control.ReadState(s.ControlService, webapp.ContextUser))
+		a.POST("/hubs/:hubName/devices/:deviceID/actions",
+			control.Execute(s.ControlService, webapp.ContextUser, devices.SourceWebApp))
+		a.GET("/events", live.Stream(s.LiveBroker, s.HubService, config.Live.Heartbeat, webapp.ContextUser))
+	}
+
+	return e
+}
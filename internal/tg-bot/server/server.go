@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/configs"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/handlers"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/services"
@@ -13,25 +14,76 @@ import (
 // NewBotServer конфигурирует обработчики команд telegram и возвращает готового к работе бота
 func NewBotServer(
 	ctx context.Context,
-	token string,
+	config *configs.TGBotCfg,
 	s services.Services,
 	clientsMap *collections.ConcurrentMap[string, *model.ClientEvents],
 	logger zerolog.Logger,
 ) *TGBot {
 	// tg bot
 	h := NewMessageHandler(logger)
+	// setpointInputs общие для меню уставки и команды /set, которая задает значение устройству из меню
+	setpointInputs := handlers.NewSetpointInputs()
 	h.Message("/menu", handlers.MenuHandler(ctx, logger, s.UserService))
-	h.Message("/start", handlers.StartNotificationsHandler(ctx, logger, s.UserService, clientsMap))
-	h.Message("/stop", handlers.StopNotificationsHandler(ctx, logger, s.UserService, clientsMap))
-	h.Callback("status", handlers.StatusHandler(ctx, logger, s.UserService))
-	h.Callback("lightControl", handlers.LightControlHandler(ctx, logger, s.UserService))
+	h.Message("/start", handlers.StartNotificationsHandler(ctx, logger, s.UserService, config.WebApp.URL))
+	h.Message("/stop", handlers.StopNotificationsHandler(ctx, logger, s.UserService))
+	h.Message("/schedules", handlers.SchedulesHandler(ctx, logger, s.UserService, s.ScheduleService))
+	h.Message("/schedule_add", handlers.NewScheduleHandler(ctx, logger, s.UserService, s.ScheduleService))
+	h.Message("/scenes", handlers.ScenesHandler(ctx, logger, s.UserService, s.SceneService))
+	h.Message("/scene_add", handlers.NewSceneHandler(ctx, logger, s.UserService, s.SceneService))
+	h.Message("/quiet", handlers.QuietHoursHandler(ctx, logger, s.UserService, s.NotifyService))
+	h.Message("/mutes", handlers.MutesHandler(ctx, logger, s.UserService, s.NotifyService))
+	h.Message("/timezone", handlers.TimezoneHandler(ctx, logger, s.UserService))
+	h.Message("/language", handlers.LanguageHandler(ctx, logger, s.UserService, config.WebApp.URL))
+	h.Message("/bind", handlers.BindChatHandler(ctx, logger, s.UserService, s.HubService))
+	h.Message("/unbind", handlers.UnbindChatHandler(ctx, logger, s.UserService, s.HubService))
+	h.Message("/subscriptions", handlers.SubscriptionsHandler(ctx, logger, s.UserService, s.HubService))
+	h.Message("/hubs", handlers.HubsHandler(ctx, logger, s.UserService, s.HubService))
+	h.Message("/invite", handlers.InviteHandler(ctx, logger, s.UserService, s.HubService))
+	h.Message("/join", handlers.JoinHandler(ctx, logger, s.UserService, s.HubService))
+	h.Message("/members", handlers.MembersHandler(ctx, logger, s.UserService, s.HubService))
+	h.Message("/pin", handlers.PINHandler(ctx, logger, s.UserService, s.PolicyService))
+	h.Message("/set", handlers.SetHandler(ctx, logger, s.UserService, s.PolicyService, setpointInputs))
+	h.Message("/history", handlers.HistoryHandler(ctx, logger, s.UserService, s.AuditService))
+	h.Message("/channels", handlers.ChannelsHandler(ctx, logger, s.UserService, s.ChannelService))
+	h.Message("/channel_add", handlers.NewChannelHandler(ctx, logger, s.UserService, s.ChannelService))
+	h.Message("/channel_severity", handlers.ChannelSeverityHandler(ctx, logger, s.UserService, s.ChannelService))
+	h.Membership(handlers.BotMembershipHandler(ctx, logger, s.UserService, s.HubService))
+	h.Callback("status", handlers.StatusHandler(ctx, logger, s.UserService, s.HubService, clientsMap))
+	h.Callback("lightControl", handlers.LightControlHandler(ctx, logger, s.UserService, s.HubService))
 	h.Callback("lampMenu", handlers.LampMenuHandler(ctx, logger, s.UserService))
-	h.Callback("lampSwitch", handlers.LampSwitchHandler(ctx, logger, s.UserService, clientsMap))
+	h.Callback("lampSwitch", handlers.LampSwitchHandler(ctx, logger, s.UserService, s.PolicyService))
+	h.Callback("lampConfirm", handlers.LampConfirmHandler(ctx, logger, s.UserService, s.PolicyService))
+	h.Callback("setpoints", handlers.SetpointsHandler(ctx, logger, s.UserService, s.HubService, s.ControlService))
+	h.Callback("setpointMenu", handlers.SetpointMenuHandler(ctx, logger, s.UserService, s.HubService, s.ControlService, setpointInputs))
+	h.Callback("setValue", handlers.SetValueHandler(ctx, logger, s.UserService, s.PolicyService))
+	h.Callback("schedules", handlers.SchedulesHandler(ctx, logger, s.UserService, s.ScheduleService))
+	h.Callback("scheduleDelete", handlers.DeleteScheduleHandler(ctx, logger, s.UserService, s.ScheduleService))
+	h.Callback("mute", handlers.MuteHandler(ctx, logger, s.UserService, s.NotifyService))
+	h.Callback("unmute", handlers.UnmuteHandler(ctx, logger, s.UserService, s.NotifyService))
+	h.Callback("scenes", handlers.ScenesHandler(ctx, logger, s.UserService, s.SceneService))
+	h.Callback("sceneRun", handlers.RunSceneHandler(ctx, logger, s.UserService, s.SceneService))
+	h.Callback("sceneDelete", handlers.DeleteSceneHandler(ctx, logger, s.UserService, s.SceneService))
+	h.Callback("subscriptions", handlers.SubscriptionsHandler(ctx, logger, s.UserService, s.HubService))
+	h.Callback("hubs", handlers.HubsHandler(ctx, logger, s.UserService, s.HubService))
+	h.Callback("members", handlers.MembersHandler(ctx, logger, s.UserService, s.HubService))
+	h.Callback("memberRole", handlers.MemberRoleHandler(ctx, logger, s.UserService, s.HubService))
+	h.Callback("memberRemove", handlers.MemberRemoveHandler(ctx, logger, s.UserService, s.HubService))
+	h.Callback("history", handlers.HistoryHandler(ctx, logger, s.UserService, s.AuditService))
+	h.Callback("unsubscribe", handlers.UnsubscribeHandler(ctx, logger, s.UserService, s.HubService))
+	h.Callback("channels", handlers.ChannelsHandler(ctx, logger, s.UserService, s.ChannelService))
+	h.Callback("channelDelete", handlers.DeleteChannelHandler(ctx, logger, s.UserService, s.ChannelService))
+	h.Callback("language", handlers.LanguageHandler(ctx, logger, s.UserService, config.WebApp.URL))
+	h.Callback("channelTest", handlers.TestChannelHandler(ctx, logger, s.UserService, s.ChannelService))
 
-	bot, err := NewTGBot(ctx, token, h, logger)
+	bot, err := NewTGBot(ctx, config.BotToken, config.Sender, s.OutboxRepo, s.UserService, h, logger)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("remote-control-tg-bot: bot init error")
 	}
+	if len(config.WebApp.URL) != 0 {
+		if err := bot.SetMenuButton(config.WebApp.URL); err != nil {
+			logger.Error().Err(err).Msg("remote-control-tg-bot: failed to set menu button")
+		}
+	}
 
 	return bot
 }
@@ -3,11 +3,14 @@ package services
 import (
 	"context"
 	"errors"
+	"time"
 
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/repository"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/users"
 	"github.com/c0dered273/automation-remote-controller/pkg/collections"
+	pkgmodel "github.com/c0dered273/automation-remote-controller/pkg/model"
 	"github.com/c0dered273/automation-remote-controller/pkg/proto"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc/codes"
@@ -21,8 +24,10 @@ type EventMultiService struct {
 	ctx         context.Context
 	logger      zerolog.Logger
 	clients     *collections.ConcurrentMap[string, *model.ClientEvents]
-	notify      chan<- model.Notification
+	notifier    model.HubNotifier
+	events      model.EventPublisher
 	userService users.UserService
+	hubService  hubs.HubService
 }
 
 // EventStreaming получает двунаправленный поток отк клиента, достает из метаданных идентификаторы, идентифицирует клиента.
@@ -68,11 +73,25 @@ func (s *EventMultiService) EventStreaming(stream proto.EventMultiService_EventS
 	if user.ChatID == 0 {
 		return status.Error(codes.InvalidArgument, "unable to get chatID, please register with telegram")
 	}
+	hub, err := s.hubService.FindHubByClientID(s.ctx, certID)
+	if err != nil {
+		return status.Error(codes.Internal, "Internal error")
+	}
 
-	clientEvents := model.NewClientEvents(s.ctx, user.ChatID, s.notify, user.NotifyEnabled, s.logger)
+	clientEvents := model.NewClientEvents(s.ctx, hub, s.notifier, s.events, s.logger)
 	clientEvents.ContinuousReadAndNotify()
-	s.clients.Put(tgName, clientEvents)
+	s.clients.Put(hub.Name, clientEvents)
 	s.logger.Info().Msgf("new connect from %s, %s", tgName, certID)
+	s.events.Publish(model.HubEvent{Type: pkgmodel.EventHubOnline, Hub: hub, At: time.Now()})
+	defer func() {
+		clientEvents.Close()
+		// Клиент мог переподключиться, тогда в словаре уже новый стрим
+		if current, ok := s.clients.Get(hub.Name); ok && current == clientEvents {
+			s.clients.Take(hub.Name)
+		}
+		s.logger.Info().Msgf("client disconnected %s, %s", tgName, certID)
+		s.events.Publish(model.HubEvent{Type: pkgmodel.EventHubOffline, Hub: hub, At: time.Now()})
+	}()
 
 	// Получаем события из стрима. Метод stream.Recv() блокирующий, поэтому запускаем в отдельной горутине
 	go func() {
@@ -121,14 +140,18 @@ func NewEventMultiService(
 	ctx context.Context,
 	logger zerolog.Logger,
 	clients *collections.ConcurrentMap[string, *model.ClientEvents],
-	notify chan<- model.Notification,
+	notifier model.HubNotifier,
+	events model.EventPublisher,
 	userService users.UserService,
+	hubService hubs.HubService,
 ) *EventMultiService {
 	return &EventMultiService{
 		ctx:         ctx,
 		logger:      logger,
 		clients:     clients,
-		notify:      notify,
+		notifier:    notifier,
+		events:      events,
 		userService: userService,
+		hubService:  hubService,
 	}
 }
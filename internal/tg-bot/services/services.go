@@ -1,26 +1,116 @@
 package services
 
 import (
+	"context"
+
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/audit"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/channels"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/configs"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/control"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/devices"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/inbound"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/live"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/model"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/notifications"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/outbox"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/rules"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/scenes"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/schedules"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/storage"
 	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/users"
+	"github.com/c0dered273/automation-remote-controller/pkg/collections"
 	"github.com/rs/zerolog"
 )
 
 // Services содержит настроенный сервисный слой приложения
 type Services struct {
-	UserService users.UserService
+	UserService     users.UserService
+	ScheduleService schedules.ScheduleService
+	Scheduler       *schedules.Scheduler
+	SceneService    scenes.SceneService
+	NotifyService   notifications.NotifyService
+	OutboxRepo      outbox.OutboxRepository
+	HubService      hubs.HubService
+	PolicyService   devices.PolicyService
+	AuditService    audit.AuditService
+	RuleRepo        rules.RuleRepository
+	ChannelRepo     channels.ChannelRepository
+	ChannelService  channels.ChannelService
+	InboundRepo     inbound.InboundRepository
+	ControlService  control.ControlService
+	// LiveBroker поток событий хабов для панелей мониторинга
+	LiveBroker *live.Broker
+	// Dispatcher каналы доставки уведомлений, telegram добавляется после запуска бота
+	Dispatcher *channels.Dispatcher
 }
 
 // NewServices настраивает сервисный слой приложения
-func NewServices(databaseUri string, logger zerolog.Logger) Services {
-	db, err := storage.NewConnection(databaseUri)
+func NewServices(
+	ctx context.Context,
+	config *configs.TGBotCfg,
+	clientsMap *collections.ConcurrentMap[string, *model.ClientEvents],
+	logger zerolog.Logger,
+) Services {
+	db, err := storage.NewConnection(config.DatabaseUri)
 	if err != nil {
 		logger.Fatal().Err(err).Send()
 	}
 	usersRepo := users.NewRepo(db)
 	userService := users.NewUserService(usersRepo)
 
+	hubRepo := hubs.NewRepo(db)
+	hubService := hubs.NewHubService(hubRepo, userService)
+
+	auditRepo := audit.NewRepo(db)
+	auditService := audit.NewAuditService(ctx, auditRepo, config.Audit.Retention, config.Audit.CleanupInterval, logger)
+
+	liveBroker := live.NewBroker(config.Live.BufferSize, config.Live.SubscriberBuffer, logger)
+
+	policyRepo := devices.NewRepo(db)
+	policyService := devices.NewPolicyService(policyRepo, hubService, auditService, clientsMap, liveBroker, logger)
+
+	scheduleRepo := schedules.NewRepo(db)
+	scheduleService := schedules.NewScheduleService(scheduleRepo, hubService, policyService)
+	scheduler := schedules.NewScheduler(ctx, scheduleRepo, hubService, policyService, config.Scheduler.Interval, config.Scheduler.MisfireGrace, logger)
+
+	sceneRepo := scenes.NewRepo(db)
+	sceneService := scenes.NewSceneService(sceneRepo, hubService, policyService)
+
+	notifyRepo := notifications.NewRepo(db)
+	notifyService := notifications.NewNotifyService(notifyRepo, logger)
+
+	outboxRepo := outbox.NewRepo(db)
+
+	ruleRepo := rules.NewRepo(db)
+
+	dispatcher := channels.NewDispatcher(ctx, logger, channels.NewWebhookChannel(config.Webhook, logger))
+	if len(config.SMTP.Addr) != 0 {
+		dispatcher.Register(channels.NewEmailChannel(config.SMTP))
+	}
+	channelRepo := channels.NewRepo(db)
+	channelService := channels.NewChannelService(channelRepo, dispatcher)
+
+	inboundRepo := inbound.NewRepo(db)
+
+	controlService := control.NewControlService(hubService, policyService, clientsMap, config.Internal.AckTimeout)
+
 	return Services{
-		UserService: userService,
+		UserService:     userService,
+		ScheduleService: scheduleService,
+		Scheduler:       scheduler,
+		SceneService:    sceneService,
+		NotifyService:   notifyService,
+		OutboxRepo:      outboxRepo,
+		HubService:      hubService,
+		PolicyService:   policyService,
+		AuditService:    auditService,
+		RuleRepo:        ruleRepo,
+		ChannelRepo:     channelRepo,
+		ChannelService:  channelService,
+		InboundRepo:     inboundRepo,
+		ControlService:  controlService,
+		LiveBroker:      liveBroker,
+		Dispatcher:      dispatcher,
 	}
 }
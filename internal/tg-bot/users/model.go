@@ -2,8 +2,12 @@ package users
 
 // User описывает сущность пользователя
 type User struct {
+	ID            int64  `db:"id"`
 	Username      string `db:"username"`
 	TGUser        string `db:"tg_user"`
 	ChatID        int64  `db:"chat_id"`
 	NotifyEnabled bool   `db:"notify_enabled"`
+	Timezone      string `db:"timezone"`
+	// Language выбранный язык сообщений бота, пустая строка - язык из настроек telegram
+	Language string `db:"language"`
 }
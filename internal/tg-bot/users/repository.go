@@ -21,6 +21,12 @@ type UserRepository interface {
 	IsUserExists(ctx context.Context, tgUser string) (bool, error)
 	// FindUserByClientID поиск пользователя по идентификатору клиента
 	FindUserByClientID(ctx context.Context, clientID string) (User, error)
+	// UpdateTimezoneByTGUser изменение часового пояса пользователя
+	UpdateTimezoneByTGUser(ctx context.Context, tgName string, timezone string) error
+	// FindUserByChatID поиск пользователя по идентификатору личного чата с ботом
+	FindUserByChatID(ctx context.Context, chatID int64) (User, error)
+	// UpdateLanguageByTGUser изменение языка сообщений пользователя
+	UpdateLanguageByTGUser(ctx context.Context, tgName string, language string) error
 }
 
 type SQLUserRepo struct {
@@ -58,7 +64,7 @@ func (r SQLUserRepo) UpdateNotificationByTGUser(ctx context.Context, tgName stri
 }
 
 func (r SQLUserRepo) FindUserByTGUser(ctx context.Context, tgUser string) (User, error) {
-	const sqlQuery = "SELECT username, tg_user, chat_id, notify_enabled FROM users WHERE tg_user = $1"
+	const sqlQuery = "SELECT id, username, tg_user, chat_id, notify_enabled, timezone, language FROM users WHERE tg_user = $1"
 
 	user := User{}
 	err := r.db.GetContext(ctx, &user, sqlQuery, tgUser)
@@ -84,7 +90,7 @@ func (r SQLUserRepo) IsUserExists(ctx context.Context, tgUser string) (bool, err
 }
 
 func (r SQLUserRepo) FindUserByClientID(ctx context.Context, clientID string) (User, error) {
-	const sqlQuery = "SELECT username, tg_user, chat_id, notify_enabled FROM users u JOIN clients c ON u.id = c.user_id WHERE c.uuid = $1"
+	const sqlQuery = "SELECT u.id, username, tg_user, chat_id, notify_enabled, timezone, language FROM users u JOIN clients c ON u.id = c.user_id WHERE c.uuid = $1"
 
 	user := User{}
 	err := r.db.GetContext(ctx, &user, sqlQuery, clientID)
@@ -98,6 +104,51 @@ func (r SQLUserRepo) FindUserByClientID(ctx context.Context, clientID string) (U
 	return user, nil
 }
 
+func (r SQLUserRepo) UpdateTimezoneByTGUser(ctx context.Context, tgName string, timezone string) error {
+	const sqlQuery = `UPDATE users SET timezone = $2 WHERE tg_user=$1`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, tgName, timezone)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r SQLUserRepo) FindUserByChatID(ctx context.Context, chatID int64) (User, error) {
+	const sqlQuery = "SELECT id, username, tg_user, chat_id, notify_enabled, timezone, language FROM users WHERE chat_id = $1 LIMIT 1"
+
+	user := User{}
+	err := r.db.GetContext(ctx, &user, sqlQuery, chatID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return User{}, repository.ErrNotFound
+		}
+		return User{}, err
+	}
+
+	return user, nil
+}
+
+func (r SQLUserRepo) UpdateLanguageByTGUser(ctx context.Context, tgName string, language string) error {
+	const sqlQuery = `UPDATE users SET language = $2 WHERE tg_user=$1`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, tgName, language)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
 func NewRepo(db *sqlx.DB) SQLUserRepo {
 	return SQLUserRepo{
 		db: db,
@@ -2,7 +2,12 @@ package users
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/i18n"
+	"github.com/c0dered273/automation-remote-controller/internal/tg-bot/repository"
 	"github.com/c0dered273/automation-remote-controller/pkg/collections"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -19,13 +24,25 @@ type UserService interface {
 	IsUserExists(ctx context.Context, tgName string) bool
 	// FindUserByClientID поиск пользователя по идентификатору клиентского приложения
 	FindUserByClientID(ctx context.Context, clientID string) (User, error)
+	// SetTimezone проверяет и сохраняет часовой пояс пользователя в формате IANA (Europe/Moscow)
+	SetTimezone(ctx context.Context, tgName string, timezone string) error
+	// SetLanguage сохраняет выбранный пользователем язык сообщений бота
+	SetLanguage(ctx context.Context, tgName string, lang i18n.Lang) error
+	// Localizer возвращает локализатор на языке пользователя:
+	// выбранном командой /language, иначе на языке из настроек telegram
+	Localizer(ctx context.Context, from *tgbotapi.User) i18n.Localizer
+	// ChatLocalizer возвращает локализатор для сообщений в чат, для групп и неизвестных чатов используется язык по умолчанию
+	ChatLocalizer(ctx context.Context, chatID int64) i18n.Localizer
 	SetUserLastMessage(tgName string, message tgbotapi.Message)
-	GetUserLastMessage(tgName string) (tgbotapi.Message, bool)
+	// GetUserLastMessage возвращает последнее сообщение бота пользователю, если оно было отправлено в указанный чат
+	GetUserLastMessage(tgName string, chatID int64) (tgbotapi.Message, bool)
 }
 
 type UserServiceImpl struct {
 	userRepo    UserRepository
 	userLastMsg *collections.ConcurrentMap[string, tgbotapi.Message]
+	// userLang сохраненный язык пользователей, чтобы не обращаться к БД при каждом сообщении
+	userLang *collections.ConcurrentMap[string, string]
 }
 
 func (u UserServiceImpl) SetNotification(ctx context.Context, tgName string, flag bool) error {
@@ -65,12 +82,66 @@ func (u UserServiceImpl) FindUserByClientID(ctx context.Context, clientID string
 	return user, err
 }
 
+func (u UserServiceImpl) SetTimezone(ctx context.Context, tgName string, timezone string) error {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return fmt.Errorf("user service: invalid timezone %q, %w", timezone, err)
+	}
+	return u.userRepo.UpdateTimezoneByTGUser(ctx, tgName, loc.String())
+}
+
+func (u UserServiceImpl) SetLanguage(ctx context.Context, tgName string, lang i18n.Lang) error {
+	if err := u.userRepo.UpdateLanguageByTGUser(ctx, tgName, string(lang)); err != nil {
+		return err
+	}
+	u.userLang.Put(tgName, string(lang))
+	return nil
+}
+
+func (u UserServiceImpl) Localizer(ctx context.Context, from *tgbotapi.User) i18n.Localizer {
+	if from == nil {
+		return i18n.New(i18n.Default)
+	}
+	saved, ok := u.userLang.Get(from.UserName)
+	if !ok {
+		user, err := u.userRepo.FindUserByTGUser(ctx, from.UserName)
+		switch {
+		case err == nil:
+			saved = user.Language
+			u.userLang.Put(from.UserName, saved)
+		case errors.Is(err, repository.ErrNotFound):
+			// Для незарегистрированного пользователя язык определяется настройками telegram
+			u.userLang.Put(from.UserName, "")
+		}
+	}
+	if lang, ok := i18n.Parse(saved); ok {
+		return i18n.New(lang)
+	}
+	if lang, ok := i18n.Parse(from.LanguageCode); ok {
+		return i18n.New(lang)
+	}
+	return i18n.New(i18n.Default)
+}
+
+func (u UserServiceImpl) ChatLocalizer(ctx context.Context, chatID int64) i18n.Localizer {
+	user, err := u.userRepo.FindUserByChatID(ctx, chatID)
+	if err != nil {
+		return i18n.New(i18n.Default)
+	}
+	lang, _ := i18n.Parse(user.Language)
+	return i18n.New(lang)
+}
+
 func (u UserServiceImpl) SetUserLastMessage(tgName string, message tgbotapi.Message) {
 	u.userLastMsg.Put(tgName, message)
 }
 
-func (u UserServiceImpl) GetUserLastMessage(tgName string) (tgbotapi.Message, bool) {
-	return u.userLastMsg.Get(tgName)
+func (u UserServiceImpl) GetUserLastMessage(tgName string, chatID int64) (tgbotapi.Message, bool) {
+	msg, ok := u.userLastMsg.Get(tgName)
+	if !ok || msg.Chat == nil || msg.Chat.ID != chatID {
+		return tgbotapi.Message{}, false
+	}
+	return msg, true
 }
 
 // NewUserService создает сервис пользователей
@@ -78,5 +149,6 @@ func NewUserService(userRepo UserRepository) UserServiceImpl {
 	return UserServiceImpl{
 		userRepo:    userRepo,
 		userLastMsg: collections.NewConcurrentMap[string, tgbotapi.Message](),
+		userLang:    collections.NewConcurrentMap[string, string](),
 	}
 }
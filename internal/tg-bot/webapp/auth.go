@@ -0,0 +1,79 @@
+package webapp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidInitData = errors.New("webapp: invalid init data")
+	ErrInitDataExpired = errors.New("webapp: init data expired")
+)
+
+// webAppDataKey ключ для получения секрета из токена бота, определен протоколом Telegram Mini Apps
+const webAppDataKey = "WebAppData"
+
+// ValidateInitData проверяет подпись данных запуска Mini App токеном бота:
+// hash = HMAC_SHA256(HMAC_SHA256("WebAppData", token), data_check_string),
+// где data_check_string - отсортированные пары key=value без hash, разделенные переводом строки
+func ValidateInitData(raw string, botToken string, ttl time.Duration, now time.Time) (InitData, error) {
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return InitData{}, ErrInvalidInitData
+	}
+	hash := values.Get("hash")
+	if len(hash) == 0 {
+		return InitData{}, ErrInvalidInitData
+	}
+
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		if key == "hash" {
+			continue
+		}
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+
+	secret := hmacSHA256([]byte(webAppDataKey), []byte(botToken))
+	expected := hmacSHA256(secret, []byte(strings.Join(pairs, "\n")))
+	got, err := hex.DecodeString(hash)
+	if err != nil || !hmac.Equal(expected, got) {
+		return InitData{}, ErrInvalidInitData
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return InitData{}, ErrInvalidInitData
+	}
+	data := InitData{
+		AuthDate: time.Unix(authDate, 0),
+		QueryID:  values.Get("query_id"),
+	}
+	if ttl > 0 && now.Sub(data.AuthDate) > ttl {
+		return InitData{}, ErrInitDataExpired
+	}
+
+	if err := json.Unmarshal([]byte(values.Get("user")), &data.User); err != nil {
+		return InitData{}, fmt.Errorf("%w: %v", ErrInvalidInitData, err)
+	}
+	if len(data.User.Username) == 0 {
+		return InitData{}, ErrInvalidInitData
+	}
+	return data, nil
+}
+
+func hmacSHA256(key []byte, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
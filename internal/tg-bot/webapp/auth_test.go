@@ -0,0 +1,114 @@
+package webapp
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBotToken = "123456:test-token"
+
+// signInitData подписывает данные запуска так же, как Telegram
+func signInitData(values url.Values, token string) string {
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+	secret := hmacSHA256([]byte(webAppDataKey), []byte(token))
+	signed := url.Values{}
+	for key := range values {
+		signed.Set(key, values.Get(key))
+	}
+	signed.Set("hash", hex.EncodeToString(hmacSHA256(secret, []byte(strings.Join(pairs, "\n")))))
+	return signed.Encode()
+}
+
+func testInitValues(authDate time.Time) url.Values {
+	return url.Values{
+		"auth_date": {strconv.FormatInt(authDate.Unix(), 10)},
+		"query_id":  {"AAF1"},
+		"user":      {`{"id":42,"first_name":"Иван","username":"ivan","language_code":"ru"}`},
+	}
+}
+
+func TestValidateInitData(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	ttl := time.Hour
+	valid := signInitData(testInitValues(now.Add(-time.Minute)), testBotToken)
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr error
+	}{
+		{name: "valid", raw: valid},
+		{name: "valid at ttl boundary", raw: signInitData(testInitValues(now.Add(-ttl)), testBotToken)},
+		{name: "expired", raw: signInitData(testInitValues(now.Add(-ttl-time.Second)), testBotToken), wantErr: ErrInitDataExpired},
+		{
+			name:    "tampered user",
+			raw:     strings.Replace(valid, "ivan", "mallory", 1),
+			wantErr: ErrInvalidInitData,
+		},
+		{
+			name:    "tampered auth date",
+			raw:     strings.Replace(valid, "auth_date="+strconv.FormatInt(now.Add(-time.Minute).Unix(), 10), "auth_date="+strconv.FormatInt(now.Unix(), 10), 1),
+			wantErr: ErrInvalidInitData,
+		},
+		{
+			name: "added field",
+			raw:  valid + "&start_param=admin",
+			// добавленное поле меняет строку проверки подписи
+			wantErr: ErrInvalidInitData,
+		},
+		{name: "signed with another token", raw: signInitData(testInitValues(now), "654321:other-token"), wantErr: ErrInvalidInitData},
+		{name: "without hash", raw: testInitValues(now).Encode(), wantErr: ErrInvalidInitData},
+		{name: "hash is not hex", raw: testInitValues(now).Encode() + "&hash=zz", wantErr: ErrInvalidInitData},
+		{name: "malformed query", raw: "user=%zz&hash=00", wantErr: ErrInvalidInitData},
+		{
+			name: "without username",
+			raw: signInitData(url.Values{
+				"auth_date": {strconv.FormatInt(now.Unix(), 10)},
+				"user":      {`{"id":42,"first_name":"Иван"}`},
+			}, testBotToken),
+			wantErr: ErrInvalidInitData,
+		},
+		{
+			name: "without auth date",
+			raw: signInitData(url.Values{
+				"user": {`{"id":42,"username":"ivan"}`},
+			}, testBotToken),
+			wantErr: ErrInvalidInitData,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ValidateInitData(tt.raw, testBotToken, ttl, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ValidateInitData() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateInitData() error = %v", err)
+			}
+			if data.User.Username != "ivan" || data.QueryID != "AAF1" {
+				t.Errorf("ValidateInitData() = %+v", data)
+			}
+		})
+	}
+}
+
+func TestValidateInitDataWithoutTTL(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	raw := signInitData(testInitValues(now.Add(-365*24*time.Hour)), testBotToken)
+	if _, err := ValidateInitData(raw, testBotToken, 0, now); err != nil {
+		t.Errorf("ValidateInitData() error = %v", err)
+	}
+}
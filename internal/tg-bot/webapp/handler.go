@@ -0,0 +1,58 @@
+package webapp
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const userContextKey = "webappUser"
+
+//go:embed static
+var static embed.FS
+
+// InitDataAuth middleware проверяет данные запуска Mini App из заголовка Authorization: tma <initData>
+// или параметра init_data, который нужен для EventSource, не поддерживающего заголовки
+func InitDataAuth(botToken string, ttl time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			raw, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "tma ")
+			if !ok {
+				raw = c.QueryParam("init_data")
+			}
+			if len(raw) == 0 {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Missing init data")
+			}
+
+			data, err := ValidateInitData(raw, botToken, ttl, time.Now())
+			if err != nil {
+				if errors.Is(err, ErrInitDataExpired) {
+					return echo.NewHTTPError(http.StatusUnauthorized, "Init data expired")
+				}
+				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid init data")
+			}
+
+			c.Set(userContextKey, data.User.Username)
+			return next(c)
+		}
+	}
+}
+
+// ContextUser пользователь telegram, прошедший проверку InitDataAuth
+func ContextUser(c echo.Context) string {
+	return c.Get(userContextKey).(string)
+}
+
+// Static GET /webapp/* - страница Mini App
+func Static() echo.HandlerFunc {
+	sub, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	return echo.WrapHandler(http.StripPrefix("/webapp/", http.FileServer(http.FS(sub))))
+}
@@ -0,0 +1,18 @@
+package webapp
+
+import "time"
+
+// User пользователь telegram из данных запуска Mini App
+type User struct {
+	ID        int64  `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// InitData проверенные данные запуска Mini App
+type InitData struct {
+	User     User
+	AuthDate time.Time
+	QueryID  string
+}
@@ -0,0 +1,289 @@
+"use strict";
+
+const tg = window.Telegram.WebApp;
+const initData = tg.initData;
+
+// Названия стандартных команд, остальные команды показываются как есть
+const actionTitles = {
+    switchon: "Вкл",
+    switchoff: "Выкл",
+    toggle: "Переключить",
+    pulse: "Импульс",
+    setvalue: "Задать",
+};
+
+const state = {
+    hubs: [],
+    hub: null,
+    devices: new Map(),
+    events: null,
+};
+
+function toast(text) {
+    const el = document.getElementById("toast");
+    el.textContent = text;
+    el.classList.remove("hidden");
+    clearTimeout(toast.timer);
+    toast.timer = setTimeout(() => el.classList.add("hidden"), 3000);
+}
+
+async function api(method, path, body) {
+    const resp = await fetch("api/" + path, {
+        method: method,
+        headers: {
+            "Authorization": "tma " + initData,
+            "Content-Type": "application/json",
+        },
+        body: body ? JSON.stringify(body) : undefined,
+    });
+    const data = await resp.json().catch(() => ({}));
+    if (!resp.ok) {
+        const err = new Error(data.message || resp.statusText);
+        err.status = resp.status;
+        throw err;
+    }
+    return data;
+}
+
+function renderHubs() {
+    const select = document.getElementById("hubs");
+    select.innerHTML = "";
+    for (const hub of state.hubs) {
+        const option = document.createElement("option");
+        option.value = hub.name;
+        option.textContent = hub.name;
+        select.appendChild(option);
+    }
+    if (state.hub) {
+        select.value = state.hub.name;
+    }
+}
+
+function renderOnline() {
+    const badge = document.getElementById("online");
+    const online = state.hub && state.hub.online;
+    badge.textContent = online ? "online" : "offline";
+    badge.classList.toggle("online", Boolean(online));
+}
+
+function canControl() {
+    return state.hub && state.hub.role !== "viewer";
+}
+
+function renderDevices() {
+    const main = document.getElementById("rooms");
+    main.innerHTML = "";
+    const rooms = new Map();
+    for (const device of state.devices.values()) {
+        const room = device.room || "Без помещения";
+        if (!rooms.has(room)) {
+            rooms.set(room, []);
+        }
+        rooms.get(room).push(device);
+    }
+
+    for (const [room, devices] of [...rooms.entries()].sort()) {
+        const title = document.createElement("h2");
+        title.textContent = room;
+        main.appendChild(title);
+
+        for (const device of devices) {
+            const row = document.createElement("div");
+            row.className = "device";
+
+            const info = document.createElement("div");
+            const name = document.createElement("div");
+            name.className = "name";
+            name.textContent = device.device_id;
+            const value = document.createElement("div");
+            value.className = "value" + (device.error ? " error" : "");
+            const unit = device.setpoint && device.setpoint.unit ? " " + device.setpoint.unit : "";
+            value.textContent = device.error ? device.error : "Значение: " + (device.value || "-") + unit;
+            info.append(name, value);
+
+            const actions = document.createElement("div");
+            actions.className = "actions";
+            for (const action of device.actions || []) {
+                if (action.toLowerCase() === "setvalue" && device.setpoint) {
+                    actions.appendChild(renderSetpoint(device));
+                    continue;
+                }
+                const button = document.createElement("button");
+                button.textContent = actionTitles[action.toLowerCase()] || action;
+                button.disabled = !canControl() || !state.hub.online;
+                button.onclick = () => execute(device.device_id, {action: action});
+                actions.appendChild(button);
+            }
+
+            row.append(info, actions);
+            main.appendChild(row);
+        }
+    }
+    if (state.devices.size === 0) {
+        main.textContent = state.hub && state.hub.online ? "Нет устройств" : "Хаб не в сети";
+    }
+}
+
+// renderSetpoint поле ввода значения уставки, диапазон и шаг окончательно проверяются клиентским приложением хаба
+function renderSetpoint(device) {
+    const sp = device.setpoint;
+    const form = document.createElement("form");
+    form.className = "setpoint";
+    const input = document.createElement("input");
+    input.type = "number";
+    input.min = sp.min;
+    input.max = sp.max;
+    input.step = sp.step || "any";
+    input.value = device.value || "";
+    input.title = sp.min + ".." + sp.max + (sp.unit ? " " + sp.unit : "");
+    const button = document.createElement("button");
+    button.type = "submit";
+    button.textContent = actionTitles.setvalue;
+    input.disabled = button.disabled = !canControl() || !state.hub.online;
+    form.onsubmit = (e) => {
+        e.preventDefault();
+        if (!input.reportValidity()) {
+            return;
+        }
+        execute(device.device_id, {action: "SetValue", value: Number(input.value)});
+    };
+    form.append(input, button);
+    return form;
+}
+
+function askPIN() {
+    return new Promise((resolve) => {
+        const dialog = document.getElementById("pin");
+        const input = document.getElementById("pin-value");
+        input.value = "";
+        dialog.classList.remove("hidden");
+        input.focus();
+        const done = (pin) => {
+            dialog.classList.add("hidden");
+            resolve(pin);
+        };
+        document.getElementById("pin-ok").onclick = () => done(input.value);
+        document.getElementById("pin-cancel").onclick = () => done(null);
+    });
+}
+
+function askConfirm(text) {
+    return new Promise((resolve) => tg.showConfirm(text, resolve));
+}
+
+async function execute(deviceID, cmd) {
+    try {
+        const path = "hubs/" + encodeURIComponent(state.hub.name) + "/devices/" + encodeURIComponent(deviceID) + "/actions";
+        await api("POST", path, cmd);
+        tg.HapticFeedback.notificationOccurred("success");
+        await loadDevices([deviceID]);
+    } catch (err) {
+        if (err.status === 428 && !cmd.confirmed && !cmd.pin) {
+            if (err.message === "PIN is required") {
+                const pin = await askPIN();
+                if (pin) {
+                    return execute(deviceID, {...cmd, pin: pin});
+                }
+                return;
+            }
+            const action = cmd.value === undefined ? cmd.action : cmd.action + "=" + cmd.value;
+            if (await askConfirm("Выполнить команду " + action + " для " + deviceID + "?")) {
+                return execute(deviceID, {...cmd, confirmed: true});
+            }
+            return;
+        }
+        tg.HapticFeedback.notificationOccurred("error");
+        toast("Ошибка: " + err.message);
+    }
+}
+
+function mergeDevices(devices) {
+    for (const device of devices || []) {
+        const current = state.devices.get(device.device_id) || {};
+        state.devices.set(device.device_id, {...current, ...device});
+    }
+}
+
+async function loadDevices(deviceIDs) {
+    if (!state.hub || !state.hub.online) {
+        state.devices.clear();
+        renderDevices();
+        return;
+    }
+    const query = (deviceIDs || []).map((id) => "device_id=" + encodeURIComponent(id)).join("&");
+    try {
+        const result = await api("GET", "hubs/" + encodeURIComponent(state.hub.name) + "/devices" + (query ? "?" + query : ""));
+        if (!deviceIDs) {
+            state.devices.clear();
+        }
+        mergeDevices(result.devices);
+    } catch (err) {
+        toast("Ошибка: " + err.message);
+    }
+    renderDevices();
+}
+
+function subscribe() {
+    if (state.events) {
+        state.events.close();
+    }
+    const params = new URLSearchParams({init_data: initData, hub: state.hub.name});
+    const events = new EventSource("api/events?" + params.toString());
+    events.addEventListener("state", (e) => {
+        mergeDevices(JSON.parse(e.data).devices);
+        renderDevices();
+    });
+    events.addEventListener("command", (e) => {
+        const command = JSON.parse(e.data).command;
+        if (!command.success) {
+            toast(command.device_id + ": " + (command.error || "ошибка"));
+        }
+    });
+    events.addEventListener("notification", (e) => {
+        toast(JSON.parse(e.data).notify.text);
+    });
+    events.addEventListener("hub_online", () => {
+        state.hub.online = true;
+        renderOnline();
+        loadDevices();
+    });
+    events.addEventListener("hub_offline", () => {
+        state.hub.online = false;
+        renderOnline();
+        renderDevices();
+    });
+    events.addEventListener("reset", () => loadDevices());
+    state.events = events;
+}
+
+async function selectHub(name) {
+    state.hub = state.hubs.find((h) => h.name === name) || null;
+    state.devices.clear();
+    renderOnline();
+    if (!state.hub) {
+        renderDevices();
+        return;
+    }
+    subscribe();
+    await loadDevices();
+}
+
+async function main() {
+    tg.ready();
+    tg.expand();
+    document.getElementById("hubs").onchange = (e) => selectHub(e.target.value);
+    try {
+        state.hubs = await api("GET", "hubs");
+    } catch (err) {
+        document.getElementById("rooms").textContent = "Ошибка: " + err.message;
+        return;
+    }
+    if (state.hubs.length === 0) {
+        document.getElementById("rooms").textContent = "Нет доступных хабов";
+        return;
+    }
+    renderHubs();
+    await selectHub(state.hubs[0].name);
+}
+
+main();
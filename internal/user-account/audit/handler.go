@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"net/http"
+
+	"github.com/c0dered273/automation-remote-controller/pkg/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+func usernameFromToken(c echo.Context) string {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(*auth.JwtCustomClaims)
+	return claims.Username
+}
+
+// FindHistory godoc
+//
+//	@Tags			history
+//	@Summary		Возвращает журнал команд и уведомлений.
+//	@Description	Возвращает постранично записи журнала по хабам, участником которых является пользователь, от новых к старым.
+//	@ID				findHistory
+//	@Produce		json
+//	@Param			hub			query		string	false	"Hub name"
+//	@Param			device		query		string	false	"Device id"
+//	@Param			kind		query		string	false	"Entry kind"	Enums(action, notification)
+//	@Param			page		query		int		false	"Page number, starting from 1"
+//	@Param			per_page	query		int		false	"Entries per page, up to 100"
+//	@Success		200			{object}	audit.HistoryPage
+//	@Failure		400			{string}	string	"Bad Request"
+//	@Failure		500			{string}	string	"Internal Server Error"
+//	@Router			/history [get]
+func FindHistory(service AuditService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		req := HistoryRequest{}
+		if err := c.Bind(&req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+		if err := c.Validate(req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		page, err := service.FindHistory(c.Request().Context(), usernameFromToken(c), req)
+		if err != nil {
+			c.Logger().Error(err)
+			return echo.ErrInternalServerError
+		}
+
+		return c.JSON(http.StatusOK, page)
+	}
+}
@@ -0,0 +1,40 @@
+package audit
+
+import "time"
+
+// Entry запись журнала команд устройствам и уведомлений
+type Entry struct {
+	ID        int64     `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	// Kind тип записи: action или notification
+	Kind     string `db:"kind" json:"kind"`
+	TGUser   string `db:"tg_user" json:"tg_user,omitempty"`
+	ChatID   int64  `db:"chat_id" json:"chat_id,omitempty"`
+	HubName  string `db:"client_name" json:"hub_name"`
+	DeviceID string `db:"device_id" json:"device_id,omitempty"`
+	Action   string `db:"action" json:"action,omitempty"`
+	// Source источник команды: bot, scene, schedule
+	Source string `db:"source" json:"source,omitempty"`
+	// Result результат: ok, denied, failed, muted
+	Result    string `db:"result" json:"result"`
+	Error     string `db:"error" json:"error,omitempty"`
+	LatencyMs int64  `db:"latency_ms" json:"latency_ms"`
+	Text      string `db:"text" json:"text,omitempty"`
+}
+
+// HistoryRequest фильтры и параметры страницы журнала
+type HistoryRequest struct {
+	Hub     string `query:"hub"`
+	Device  string `query:"device"`
+	Kind    string `query:"kind" validate:"omitempty,oneof=action notification"`
+	Page    int    `query:"page" validate:"omitempty,gte=1"`
+	PerPage int    `query:"per_page" validate:"omitempty,gte=1,lte=100"`
+}
+
+// HistoryPage страница журнала
+type HistoryPage struct {
+	Items   []Entry `json:"items"`
+	Page    int     `json:"page"`
+	PerPage int     `json:"per_page"`
+	Total   int     `json:"total"`
+}
@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// AuditRepository описывает методы чтения журнала команд и уведомлений
+type AuditRepository interface {
+	// FindEntries возвращает записи журнала по хабам, участником которых является пользователь, от новых к старым
+	FindEntries(ctx context.Context, username string, req HistoryRequest) ([]Entry, error)
+	// CountEntries возвращает количество записей журнала, подходящих под фильтры
+	CountEntries(ctx context.Context, username string, req HistoryRequest) (int, error)
+}
+
+// SQLAuditRepo для хранения данных используется стандартный пакет database/sql c оберткой sqlx
+type SQLAuditRepo struct {
+	db *sqlx.DB
+}
+
+const fromEntries = `FROM audit_log a
+         JOIN clients c ON c.id = a.client_id
+         JOIN hub_members m ON m.client_id = a.client_id
+         JOIN users mu ON mu.id = m.user_id
+         LEFT JOIN users u ON u.id = a.user_id
+WHERE mu.username = $1
+  AND ($2::text = '' OR c.name = $2)
+  AND ($3::text = '' OR a.device_id = $3)
+  AND ($4::text = '' OR a.kind = $4)`
+
+func (r SQLAuditRepo) FindEntries(ctx context.Context, username string, req HistoryRequest) ([]Entry, error) {
+	const sqlQuery = `SELECT a.id, a.created_at, a.kind, COALESCE(u.tg_user, '') AS tg_user, COALESCE(a.chat_id, 0) AS chat_id,
+       c.name AS client_name, a.device_id, a.action, a.source, a.result, a.error, a.latency_ms, a.text ` + fromEntries + `
+ORDER BY a.id DESC
+LIMIT $5 OFFSET $6`
+
+	result := make([]Entry, 0)
+	err := r.db.SelectContext(ctx, &result, sqlQuery,
+		username, req.Hub, req.Device, req.Kind, req.PerPage, (req.Page-1)*req.PerPage)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r SQLAuditRepo) CountEntries(ctx context.Context, username string, req HistoryRequest) (int, error) {
+	const sqlQuery = `SELECT count(*) ` + fromEntries
+
+	var count int
+	err := r.db.GetContext(ctx, &count, sqlQuery, username, req.Hub, req.Device, req.Kind)
+	return count, err
+}
+
+func NewRepo(db *sqlx.DB) SQLAuditRepo {
+	return SQLAuditRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultPerPage количество записей журнала на странице по умолчанию
+const DefaultPerPage = 20
+
+// AuditService сервис чтения журнала команд и уведомлений
+type AuditService interface {
+	// FindHistory возвращает страницу журнала по хабам пользователя
+	FindHistory(ctx context.Context, username string, req HistoryRequest) (HistoryPage, error)
+}
+
+type AuditServiceImpl struct {
+	auditRepo AuditRepository
+}
+
+func (s AuditServiceImpl) FindHistory(ctx context.Context, username string, req HistoryRequest) (HistoryPage, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PerPage <= 0 {
+		req.PerPage = DefaultPerPage
+	}
+
+	total, err := s.auditRepo.CountEntries(ctx, username, req)
+	if err != nil {
+		return HistoryPage{}, fmt.Errorf("count entries: %w", err)
+	}
+	items, err := s.auditRepo.FindEntries(ctx, username, req)
+	if err != nil {
+		return HistoryPage{}, fmt.Errorf("find entries: %w", err)
+	}
+
+	return HistoryPage{
+		Items:   items,
+		Page:    req.Page,
+		PerPage: req.PerPage,
+		Total:   total,
+	}, nil
+}
+
+func NewAuditService(auditRepo AuditRepository) AuditServiceImpl {
+	return AuditServiceImpl{
+		auditRepo: auditRepo,
+	}
+}
@@ -18,8 +18,13 @@ type SQLClientRepo struct {
 }
 
 func (r SQLClientRepo) SaveClient(ctx context.Context, client Client) error {
-	const sqlQuery = `INSERT INTO clients(name, uuid, user_id) 
-					VALUES(:name, :uuid, (SELECT id FROM users u WHERE u.username=:username))`
+	// Владелец клиентского приложения сразу добавляется в участники хаба с ролью owner
+	const sqlQuery = `WITH c AS (
+						INSERT INTO clients(name, uuid, user_id)
+						VALUES(:name, :uuid, (SELECT id FROM users u WHERE u.username=:username))
+						RETURNING id, user_id
+					)
+					INSERT INTO hub_members(client_id, user_id, role) SELECT id, user_id, 'owner' FROM c`
 
 	_, err := r.db.NamedExecContext(ctx, sqlQuery, client)
 	if err != nil {
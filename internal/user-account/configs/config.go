@@ -20,6 +20,8 @@ var (
 	// API_SECRET - ключ для подписи jwt
 	// CERT_FILE - путь к файлу сертификата
 	// PKEY_FILE - путь к файлу приватного ключа
+	// BOT_API_URL - адрес внутреннего API rc-tg-bot
+	// INTERNAL_TOKEN - общий секрет внутреннего API rc-tg-bot
 	envVars = []string{
 		"PORT",
 		"DOMAIN_NAME",
@@ -41,6 +43,7 @@ type UserAccountConfig struct {
 	CertFile       string        `mapstructure:"cert_file" validate:"required"`
 	PKeyFile       string        `mapstructure:"pkey_file" validate:"required"`
 	Client         ClientConfig  `mapstructure:"client"`
+	Bot            BotConfig     `mapstructure:"bot"`
 	configs.Logger `mapstructure:"logger"`
 }
 
@@ -48,10 +51,21 @@ type ClientConfig struct {
 	DomainName []string `mapstructure:"domain_name"`
 }
 
+// BotConfig настройки внутреннего API rc-tg-bot для управления устройствами, если APIUrl не задан, управление отключено
+type BotConfig struct {
+	// APIUrl адрес http сервера rc-tg-bot
+	APIUrl string `mapstructure:"api_url" validate:"omitempty,url"`
+	// InternalToken общий секрет, должен совпадать с internal.token бота
+	InternalToken string `mapstructure:"internal_token" validate:"required_with=APIUrl"`
+	// Timeout ограничение времени запроса, должно быть больше времени ожидания подтверждения хабом
+	Timeout time.Duration `mapstructure:"timeout" validate:"required"`
+}
+
 func setDefaults() {
 	viper.SetDefault("port", "8080")
 	viper.SetDefault("client.domain_name", "c0dered.pro")
 	viper.SetDefault("token_expire", 720*time.Hour)
+	viper.SetDefault("bot.timeout", 10*time.Second)
 	viper.SetDefault("logger.level", "info")
 	viper.SetDefault("logger.format", "pretty")
 }
@@ -96,7 +110,11 @@ func bindEnvVars() error {
 		}
 	}
 
-	return nil
+	err := viper.BindEnv("bot.api_url", "BOT_API_URL")
+	if err != nil {
+		return err
+	}
+	return viper.BindEnv("bot.internal_token", "INTERNAL_TOKEN")
 }
 
 func newConfig() (*UserAccountConfig, error) {
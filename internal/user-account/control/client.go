@@ -0,0 +1,154 @@
+package control
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/configs"
+	"github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// APIError ответ внутреннего API rc-tg-bot с кодом ошибки
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("bot api: %d %s", e.Status, e.Message)
+}
+
+// BotClient клиент внутреннего API rc-tg-bot, к которому подключены клиентские приложения
+type BotClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+	// stream клиент без ограничения времени для потока событий
+	stream *http.Client
+}
+
+// FindHubs хабы пользователя telegram
+func (c *BotClient) FindHubs(ctx context.Context, tgName string) ([]model.HubStatus, error) {
+	var result []model.HubStatus
+	err := c.do(ctx, http.MethodGet, c.userPath(tgName, "hubs"), nil, nil, &result)
+	return result, err
+}
+
+// ReadState состояние устройств хаба, пустой список означает все устройства
+func (c *BotClient) ReadState(ctx context.Context, tgName string, hubName string, deviceIDs []string) (model.StateEvent, error) {
+	var result model.StateEvent
+	query := url.Values{"device_id": deviceIDs}
+	err := c.do(ctx, http.MethodGet, c.userPath(tgName, "hubs", hubName, "devices"), query, nil, &result)
+	return result, err
+}
+
+// Execute команда устройству, ответ приходит после подтверждения хабом
+func (c *BotClient) Execute(
+	ctx context.Context,
+	tgName string,
+	hubName string,
+	deviceID string,
+	cmd model.CommandRequest,
+) (model.AckEvent, error) {
+	var result model.AckEvent
+	err := c.do(ctx, http.MethodPost, c.userPath(tgName, "hubs", hubName, "devices", deviceID, "actions"), nil, cmd, &result)
+	return result, err
+}
+
+// Events открывает поток событий хабов пользователя в формате Server-Sent Events
+// lastEventID передается в заголовке Last-Event-ID для продолжения потока, поток нужно закрыть после чтения
+func (c *BotClient) Events(ctx context.Context, tgName string, query url.Values, lastEventID string) (io.ReadCloser, error) {
+	u := c.baseURL + "/" + c.userPath(tgName, "events")
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("bot api: failed to create request, %w", err)
+	}
+	req.Header.Set(model.InternalTokenHeader, c.token)
+	req.Header.Set("Accept", "text/event-stream")
+	if len(lastEventID) != 0 {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := c.stream.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("bot api: %w", err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, apiError(resp)
+	}
+	return resp.Body, nil
+}
+
+func (c *BotClient) userPath(tgName string, elem ...string) string {
+	parts := []string{"internal", "users", url.PathEscape(tgName)}
+	for _, e := range elem {
+		parts = append(parts, url.PathEscape(e))
+	}
+	return strings.Join(parts, "/")
+}
+
+func (c *BotClient) do(ctx context.Context, method string, path string, query url.Values, body any, result any) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return fmt.Errorf("bot api: failed to marshal request, %w", err)
+		}
+	}
+	u := c.baseURL + "/" + path
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, &reqBody)
+	if err != nil {
+		return fmt.Errorf("bot api: failed to create request, %w", err)
+	}
+	req.Header.Set(model.InternalTokenHeader, c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("bot api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return apiError(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("bot api: failed to unmarshal response, %w", err)
+	}
+	return nil
+}
+
+// apiError читает ошибку из ответа, ошибки echo возвращаются в виде {"message": "..."}
+func apiError(resp *http.Response) error {
+	apiErr := struct {
+		Message string `json:"message"`
+	}{}
+	_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+	return &APIError{Status: resp.StatusCode, Message: apiErr.Message}
+}
+
+// NewBotClient создает клиент внутреннего API rc-tg-bot
+func NewBotClient(config configs.BotConfig) *BotClient {
+	return &BotClient{
+		baseURL: strings.TrimRight(config.APIUrl, "/"),
+		token:   config.InternalToken,
+		client:  &http.Client{Timeout: config.Timeout},
+		stream:  &http.Client{},
+	}
+}
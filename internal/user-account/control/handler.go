@@ -0,0 +1,185 @@
+package control
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/c0dered273/automation-remote-controller/pkg/auth"
+	"github.com/c0dered273/automation-remote-controller/pkg/model"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+func usernameFromToken(c echo.Context) string {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(*auth.JwtCustomClaims)
+	return claims.Username
+}
+
+// controlError приводит ошибки внутреннего API к ответам http
+// ответы бота передаются как есть, кроме ошибок авторизации сервиса и внутренних ошибок бота
+func controlError(c echo.Context, err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Status != http.StatusUnauthorized && apiErr.Status != http.StatusInternalServerError {
+		return echo.NewHTTPError(apiErr.Status, apiErr.Message)
+	}
+	c.Logger().Error(err)
+	return echo.NewHTTPError(http.StatusBadGateway, "Bot API unavailable")
+}
+
+// FindHubs godoc
+//
+//	@Tags			control
+//	@Summary		Возвращает хабы пользователя.
+//	@Description	Возвращает хабы пользователя, его роль и признак подключения клиентского приложения.
+//	@ID				controlFindHubs
+//	@Produce		json
+//	@Success		200	{array}		model.HubStatus
+//	@Failure		502	{string}	string	"Bot API unavailable"
+//	@Router			/control/hubs [get]
+func FindHubs(service ControlService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		result, err := service.FindHubs(c.Request().Context(), usernameFromToken(c))
+		if err != nil {
+			return controlError(c, err)
+		}
+
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// ReadState godoc
+//
+//	@Tags			control
+//	@Summary		Возвращает состояние устройств хаба.
+//	@Description	Запрашивает у хаба текущие значения устройств. Без параметра device_id возвращаются все устройства хаба.
+//	@ID				controlReadState
+//	@Produce		json
+//	@Param			hub_name	path		string		true	"Hub name"
+//	@Param			device_id	query		[]string	false	"Device id"	collectionFormat(multi)
+//	@Success		200			{object}	model.StateEvent
+//	@Failure		403			{string}	string	"Forbidden"
+//	@Failure		404			{string}	string	"Hub not found"
+//	@Failure		502			{string}	string	"Bot API unavailable"
+//	@Failure		503			{string}	string	"Hub is offline"
+//	@Failure		504			{string}	string	"Hub did not reply in time"
+//	@Router			/control/hubs/{hub_name}/devices [get]
+func ReadState(service ControlService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		state, err := service.ReadState(
+			c.Request().Context(), usernameFromToken(c), c.Param("hubName"), c.QueryParams()["device_id"],
+		)
+		if err != nil {
+			return controlError(c, err)
+		}
+
+		return c.JSON(http.StatusOK, state)
+	}
+}
+
+// Execute godoc
+//
+//	@Tags			control
+//	@Summary		Отправляет команду устройству.
+//	@Description	Отправляет команду устройству с учетом правил устройства и ожидает подтверждение выполнения хабом.
+//	@Description	Для устройств, требующих подтверждения, нужно передать confirmed, для устройств с PIN-кодом - pin, для команды SetValue - value.
+//	@ID				controlExecute
+//	@Accept			json
+//	@Produce		json
+//	@Param			hub_name	path		string					true	"Hub name"
+//	@Param			device_id	path		string					true	"Device id"
+//	@Param			request		body		model.CommandRequest	true	"Command"
+//	@Success		200			{object}	model.AckEvent
+//	@Failure		400			{string}	string	"Bad Request"
+//	@Failure		403			{string}	string	"Forbidden"
+//	@Failure		404			{string}	string	"Hub not found"
+//	@Failure		428			{string}	string	"Action must be confirmed or PIN is required"
+//	@Failure		429			{string}	string	"PIN entry is locked"
+//	@Failure		502			{string}	string	"Hub failed to execute action"
+//	@Failure		503			{string}	string	"Hub is offline"
+//	@Failure		504			{string}	string	"Hub did not reply in time"
+//	@Router			/control/hubs/{hub_name}/devices/{device_id}/actions [post]
+func Execute(service ControlService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		req := model.CommandRequest{}
+		if err := c.Bind(&req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		if err := c.Validate(req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		ack, err := service.Execute(c.Request().Context(), usernameFromToken(c), c.Param("hubName"), c.Param("deviceID"), req)
+		if err != nil {
+			return controlError(c, err)
+		}
+
+		return c.JSON(http.StatusOK, ack)
+	}
+}
+
+// eventsQuery параметры фильтра потока событий, передаваемые боту
+var eventsQuery = []string{"hub", "type", "severity", "device", "last_event_id"}
+
+// Events godoc
+//
+//	@Tags			control
+//	@Summary		Поток событий хабов в формате Server-Sent Events.
+//	@Description	Передает уведомления, подключение и отключение хабов, состояние устройств и результаты команд.
+//	@Description	Каждое событие содержит id, после переподключения поток продолжается с заголовка Last-Event-ID или параметра last_event_id.
+//	@Description	Событие ping отправляется периодически для проверки соединения, событие reset означает, что часть событий потеряна.
+//	@ID				controlEvents
+//	@Produce		text/event-stream
+//	@Param			hub				query		[]string	false	"Hub name"		collectionFormat(multi)
+//	@Param			type			query		[]string	false	"Event type: notification, hub_online, hub_offline, state, command"	collectionFormat(multi)
+//	@Param			severity		query		[]string	false	"Notification severity"	collectionFormat(multi)
+//	@Param			device			query		[]string	false	"Device id"		collectionFormat(multi)
+//	@Param			last_event_id	query		string		false	"Last received event id"
+//	@Param			Last-Event-ID	header		string		false	"Last received event id"
+//	@Success		200				{string}	string
+//	@Failure		400				{string}	string	"Bad Request"
+//	@Failure		502				{string}	string	"Bot API unavailable"
+//	@Router			/events [get]
+func Events(service ControlService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		query := url.Values{}
+		for _, key := range eventsQuery {
+			if values, ok := c.QueryParams()[key]; ok {
+				query[key] = values
+			}
+		}
+
+		stream, err := service.Events(c.Request().Context(), usernameFromToken(c), query, c.Request().Header.Get("Last-Event-ID"))
+		if err != nil {
+			return controlError(c, err)
+		}
+		defer stream.Close()
+
+		w := c.Response()
+		w.Header().Set(echo.HeaderContentType, "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set(echo.HeaderConnection, "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		w.Flush()
+
+		buf := make([]byte, 4096)
+		for {
+			n, err := stream.Read(buf)
+			if n > 0 {
+				if _, wErr := w.Write(buf[:n]); wErr != nil {
+					return nil
+				}
+				w.Flush()
+			}
+			if err != nil {
+				// Поток завершен ботом или клиентом, клиент переподключится с Last-Event-ID
+				return nil
+			}
+		}
+	}
+}
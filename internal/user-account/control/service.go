@@ -0,0 +1,82 @@
+package control
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/users"
+	"github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// ControlService управление устройствами хабов, команды передаются в rc-tg-bot от имени пользователя telegram
+type ControlService interface {
+	// FindHubs хабы пользователя с признаком подключения клиентского приложения
+	FindHubs(ctx context.Context, username string) ([]model.HubStatus, error)
+	// ReadState состояние устройств хаба, пустой список означает все устройства
+	ReadState(ctx context.Context, username string, hubName string, deviceIDs []string) (model.StateEvent, error)
+	// Execute отправляет команду устройству и ожидает подтверждение хаба
+	Execute(ctx context.Context, username string, hubName string, deviceID string, cmd model.CommandRequest) (model.AckEvent, error)
+	// Events открывает поток событий хабов пользователя в формате Server-Sent Events
+	Events(ctx context.Context, username string, query url.Values, lastEventID string) (io.ReadCloser, error)
+}
+
+type ControlServiceImpl struct {
+	userRepo users.UserRepository
+	bot      *BotClient
+}
+
+func (s ControlServiceImpl) tgName(ctx context.Context, username string) (string, error) {
+	tgName, err := s.userRepo.FindTGNameByUsername(ctx, username)
+	if err != nil {
+		return "", fmt.Errorf("find telegram user: %w", err)
+	}
+	return tgName, nil
+}
+
+func (s ControlServiceImpl) FindHubs(ctx context.Context, username string) ([]model.HubStatus, error) {
+	tgName, err := s.tgName(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return s.bot.FindHubs(ctx, tgName)
+}
+
+func (s ControlServiceImpl) ReadState(ctx context.Context, username string, hubName string, deviceIDs []string) (model.StateEvent, error) {
+	tgName, err := s.tgName(ctx, username)
+	if err != nil {
+		return model.StateEvent{}, err
+	}
+	return s.bot.ReadState(ctx, tgName, hubName, deviceIDs)
+}
+
+func (s ControlServiceImpl) Execute(
+	ctx context.Context,
+	username string,
+	hubName string,
+	deviceID string,
+	cmd model.CommandRequest,
+) (model.AckEvent, error) {
+	tgName, err := s.tgName(ctx, username)
+	if err != nil {
+		return model.AckEvent{}, err
+	}
+	return s.bot.Execute(ctx, tgName, hubName, deviceID, cmd)
+}
+
+func (s ControlServiceImpl) Events(ctx context.Context, username string, query url.Values, lastEventID string) (io.ReadCloser, error) {
+	tgName, err := s.tgName(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return s.bot.Events(ctx, tgName, query, lastEventID)
+}
+
+// NewControlService создает сервис управления устройствами
+func NewControlService(userRepo users.UserRepository, bot *BotClient) ControlServiceImpl {
+	return ControlServiceImpl{
+		userRepo: userRepo,
+		bot:      bot,
+	}
+}
@@ -0,0 +1,115 @@
+package devices
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/repository"
+	"github.com/c0dered273/automation-remote-controller/pkg/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+func usernameFromToken(c echo.Context) string {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(*auth.JwtCustomClaims)
+	return claims.Username
+}
+
+// FindPolicies godoc
+//
+//	@Tags			device
+//	@Summary		Возвращает правила управления устройствами хаба.
+//	@Description	Возвращает устройства хаба, для которых заданы ограничения. Остальными устройствами могут управлять владельцы и операторы без подтверждения.
+//	@ID				findPolicies
+//	@Produce		json
+//	@Param			hub_name	path		string	true	"Hub name"
+//	@Success		200			{array}		devices.Policy
+//	@Failure		403			{string}	string	"Forbidden"
+//	@Failure		500			{string}	string	"Internal Server Error"
+//	@Router			/hubs/{hub_name}/devices [get]
+func FindPolicies(service PolicyService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		result, err := service.FindPolicies(c.Request().Context(), usernameFromToken(c), c.Param("hubName"))
+		if err != nil {
+			return policyError(c, err)
+		}
+
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// SetPolicy godoc
+//
+//	@Tags			device
+//	@Summary		Задает правила управления устройством.
+//	@Description	Задает минимальную роль для управления устройством, необходимость подтверждения команды и PIN-код.
+//	@Description	Если PIN-код не указан, сохраняется прежний PIN-код, для отключения проверки PIN-кода нужно передать clear_pin. Доступно владельцам хаба.
+//	@ID				setPolicy
+//	@Accept			json
+//	@Produce		json
+//	@Param			hub_name	path		string					true	"Hub name"
+//	@Param			device_id	path		string					true	"Device id"
+//	@Param			request		body		devices.PolicyRequest	true	"Device policy"
+//	@Success		200			{object}	devices.Policy
+//	@Failure		400			{string}	string	"Bad Request"
+//	@Failure		403			{string}	string	"Forbidden"
+//	@Failure		500			{string}	string	"Internal Server Error"
+//	@Router			/hubs/{hub_name}/devices/{device_id}/policy [put]
+func SetPolicy(service PolicyService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		req := PolicyRequest{}
+		if err := c.Bind(&req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+		if err := c.Validate(req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		policy, err := service.SetPolicy(c.Request().Context(), usernameFromToken(c), c.Param("hubName"), c.Param("deviceID"), req)
+		if err != nil {
+			return policyError(c, err)
+		}
+
+		return c.JSON(http.StatusOK, policy)
+	}
+}
+
+// DeletePolicy godoc
+//
+//	@Tags			device
+//	@Summary		Удаляет правила управления устройством.
+//	@Description	Удаляет ограничения, после чего устройством могут управлять владельцы и операторы без подтверждения. Доступно владельцам хаба.
+//	@ID				deletePolicy
+//	@Param			hub_name	path	string	true	"Hub name"
+//	@Param			device_id	path	string	true	"Device id"
+//	@Success		204
+//	@Failure		403	{string}	string	"Forbidden"
+//	@Failure		404	{string}	string	"Policy not found"
+//	@Failure		500	{string}	string	"Internal Server Error"
+//	@Router			/hubs/{hub_name}/devices/{device_id}/policy [delete]
+func DeletePolicy(service PolicyService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		err := service.DeletePolicy(c.Request().Context(), usernameFromToken(c), c.Param("hubName"), c.Param("deviceID"))
+		if err != nil {
+			return policyError(c, err)
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// policyError приводит ошибки сервиса к ответам http
+func policyError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, hubs.ErrForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	case errors.Is(err, repository.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Policy not found")
+	}
+	c.Logger().Error(err)
+	return echo.ErrInternalServerError
+}
@@ -0,0 +1,25 @@
+package devices
+
+import "github.com/c0dered273/automation-remote-controller/pkg/model"
+
+// Policy правила управления устройством хаба
+type Policy struct {
+	HubID    int64  `db:"client_id" json:"-"`
+	DeviceID string `db:"device_id" json:"device_id"`
+	// MinRole минимальная роль участника хаба, которой разрешено управлять устройством
+	MinRole model.Role `db:"min_role" json:"min_role"`
+	// Confirm команда выполняется только после подтверждения пользователем
+	Confirm bool `db:"confirm" json:"confirm"`
+	// HasPIN перед выполнением команды нужно ввести PIN-код
+	HasPIN bool `db:"has_pin" json:"has_pin"`
+}
+
+// PolicyRequest запрос изменения правил управления устройством
+// если PIN не указан, сохраняется прежний PIN-код устройства
+type PolicyRequest struct {
+	MinRole string `json:"min_role" validate:"required,oneof=owner operator"`
+	Confirm bool   `json:"confirm"`
+	PIN     string `json:"pin" validate:"omitempty,numeric,min=4,max=12"`
+	// ClearPIN отключает проверку PIN-кода, не может быть передан вместе с PIN
+	ClearPIN bool `json:"clear_pin" validate:"excluded_with=PIN"`
+}
@@ -0,0 +1,80 @@
+package devices
+
+import (
+	"context"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+// PolicyRepository описывает методы работы с правилами управления устройствами
+// идентификатор устройства хранится в нижнем регистре, клиентское приложение сопоставляет устройства без учета регистра
+type PolicyRepository interface {
+	// FindPolicies возвращает правила управления устройствами хаба
+	FindPolicies(ctx context.Context, hubID int64) ([]Policy, error)
+	// SavePolicy создает или заменяет правила управления устройством, PIN-код хранится в виде хеша
+	// пустой pin оставляет прежний PIN-код, clearPIN удаляет его
+	SavePolicy(ctx context.Context, policy Policy, pin string, clearPIN bool) (Policy, error)
+	// DeletePolicy удаляет правила управления устройством
+	DeletePolicy(ctx context.Context, hubID int64, deviceID string) error
+}
+
+// SQLPolicyRepo для хранения данных используется стандартный пакет database/sql c оберткой sqlx
+type SQLPolicyRepo struct {
+	db *sqlx.DB
+}
+
+func (r SQLPolicyRepo) FindPolicies(ctx context.Context, hubID int64) ([]Policy, error) {
+	const sqlQuery = `SELECT client_id, device_id, min_role, confirm, pin IS NOT NULL AS has_pin
+		FROM device_policies
+		WHERE client_id = $1
+		ORDER BY device_id`
+
+	result := make([]Policy, 0)
+	err := r.db.SelectContext(ctx, &result, sqlQuery, hubID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r SQLPolicyRepo) SavePolicy(ctx context.Context, policy Policy, pin string, clearPIN bool) (Policy, error) {
+	const sqlQuery = `INSERT INTO device_policies(client_id, device_id, min_role, confirm, pin)
+		VALUES($1, lower($2), $3, $4, crypt(NULLIF($5::text, ''), gen_salt('bf')))
+		ON CONFLICT (client_id, device_id) DO UPDATE
+		SET min_role = excluded.min_role,
+			confirm = excluded.confirm,
+			pin = CASE WHEN $6 THEN NULL ELSE COALESCE(excluded.pin, device_policies.pin) END,
+			updated_at = now()
+		RETURNING client_id, device_id, min_role, confirm, pin IS NOT NULL AS has_pin`
+
+	result := Policy{}
+	err := r.db.GetContext(ctx, &result, sqlQuery, policy.HubID, policy.DeviceID, policy.MinRole, policy.Confirm, pin, clearPIN)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	return result, nil
+}
+
+func (r SQLPolicyRepo) DeletePolicy(ctx context.Context, hubID int64, deviceID string) error {
+	const sqlQuery = `DELETE FROM device_policies WHERE client_id = $1 AND device_id = lower($2)`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, hubID, deviceID)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func NewRepo(db *sqlx.DB) SQLPolicyRepo {
+	return SQLPolicyRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,66 @@
+package devices
+
+import (
+	"context"
+	"strings"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/hubs"
+	"github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// PolicyService сервис управления правилами доступа к устройствам хаба
+type PolicyService interface {
+	// FindPolicies возвращает правила управления устройствами хаба
+	FindPolicies(ctx context.Context, username string, hubName string) ([]Policy, error)
+	// SetPolicy создает или заменяет правила управления устройством, доступно владельцам хаба
+	SetPolicy(ctx context.Context, username string, hubName string, deviceID string, req PolicyRequest) (Policy, error)
+	// DeletePolicy удаляет правила управления устройством, доступно владельцам хаба
+	DeletePolicy(ctx context.Context, username string, hubName string, deviceID string) error
+}
+
+type PolicyServiceImpl struct {
+	policyRepo PolicyRepository
+	hubService hubs.HubService
+}
+
+func (s PolicyServiceImpl) FindPolicies(ctx context.Context, username string, hubName string) ([]Policy, error) {
+	hub, err := s.hubService.Authorize(ctx, username, hubName, model.ViewStatus)
+	if err != nil {
+		return nil, err
+	}
+	return s.policyRepo.FindPolicies(ctx, hub.ID)
+}
+
+func (s PolicyServiceImpl) SetPolicy(ctx context.Context, username string, hubName string, deviceID string, req PolicyRequest) (Policy, error) {
+	hub, err := s.hubService.Authorize(ctx, username, hubName, model.ManageHub)
+	if err != nil {
+		return Policy{}, err
+	}
+	minRole, err := model.NewRole(req.MinRole)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	policy := Policy{
+		HubID:    hub.ID,
+		DeviceID: strings.ToLower(deviceID),
+		MinRole:  minRole,
+		Confirm:  req.Confirm,
+	}
+	return s.policyRepo.SavePolicy(ctx, policy, req.PIN, req.ClearPIN)
+}
+
+func (s PolicyServiceImpl) DeletePolicy(ctx context.Context, username string, hubName string, deviceID string) error {
+	hub, err := s.hubService.Authorize(ctx, username, hubName, model.ManageHub)
+	if err != nil {
+		return err
+	}
+	return s.policyRepo.DeletePolicy(ctx, hub.ID, deviceID)
+}
+
+func NewPolicyService(policyRepo PolicyRepository, hubService hubs.HubService) PolicyServiceImpl {
+	return PolicyServiceImpl{
+		policyRepo: policyRepo,
+		hubService: hubService,
+	}
+}
@@ -0,0 +1,214 @@
+package hubs
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/repository"
+	"github.com/c0dered273/automation-remote-controller/pkg/auth"
+	"github.com/c0dered273/automation-remote-controller/pkg/model"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+func usernameFromToken(c echo.Context) string {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(*auth.JwtCustomClaims)
+	return claims.Username
+}
+
+// bindRole читает и проверяет запрос с ролью
+func bindRole(c echo.Context) (model.Role, error) {
+	req := RoleRequest{}
+	if err := c.Bind(&req); err != nil {
+		c.Logger().Error(err)
+		return "", echo.ErrBadRequest
+	}
+	if err := c.Validate(req); err != nil {
+		c.Logger().Error(err)
+		return "", echo.ErrBadRequest
+	}
+	role, err := model.NewRole(req.Role)
+	if err != nil {
+		return "", echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return role, nil
+}
+
+// FindHubs godoc
+//
+//	@Tags			hub
+//	@Summary		Возвращает хабы пользователя.
+//	@Description	Возвращает хабы, участником которых является пользователь, и его роль в каждом из них.
+//	@ID				findHubs
+//	@Produce		json
+//	@Success		200	{array}		hubs.Hub
+//	@Failure		500	{string}	string	"Internal Server Error"
+//	@Router			/hubs [get]
+func FindHubs(service HubService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		result, err := service.FindHubs(c.Request().Context(), usernameFromToken(c))
+		if err != nil {
+			c.Logger().Error(err)
+			return echo.ErrInternalServerError
+		}
+
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// FindMembers godoc
+//
+//	@Tags			hub
+//	@Summary		Возвращает участников хаба.
+//	@Description	Возвращает участников хаба и их роли. Доступно владельцам хаба.
+//	@ID				findMembers
+//	@Produce		json
+//	@Param			hub_name	path		string	true	"Hub name"
+//	@Success		200			{array}		hubs.Member
+//	@Failure		403			{string}	string	"Forbidden"
+//	@Failure		500			{string}	string	"Internal Server Error"
+//	@Router			/hubs/{hub_name}/members [get]
+func FindMembers(service HubService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		result, err := service.FindMembers(c.Request().Context(), usernameFromToken(c), c.Param("hubName"))
+		if err != nil {
+			return hubError(c, err)
+		}
+
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// NewInvitation godoc
+//
+//	@Tags			hub
+//	@Summary		Создает приглашение в хаб.
+//	@Description	Создает одноразовое приглашение в хаб с указанной ролью. Доступно владельцам хаба.
+//	@Description	Приглашение принимается через POST /invitations/{code} или командой бота /join.
+//	@ID				newInvitation
+//	@Accept			json
+//	@Produce		json
+//	@Param			hub_name	path		string				true	"Hub name"
+//	@Param			request		body		hubs.RoleRequest	true	"Invitation role"
+//	@Success		201			{object}	hubs.Invitation
+//	@Failure		400			{string}	string	"Bad Request"
+//	@Failure		403			{string}	string	"Forbidden"
+//	@Failure		500			{string}	string	"Internal Server Error"
+//	@Router			/hubs/{hub_name}/invitations [post]
+func NewInvitation(service HubService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		role, err := bindRole(c)
+		if err != nil {
+			return err
+		}
+
+		inv, err := service.Invite(c.Request().Context(), usernameFromToken(c), c.Param("hubName"), role)
+		if err != nil {
+			return hubError(c, err)
+		}
+
+		return c.JSON(http.StatusCreated, inv)
+	}
+}
+
+// AcceptInvitation godoc
+//
+//	@Tags			hub
+//	@Summary		Принимает приглашение в хаб.
+//	@Description	Добавляет пользователя в хаб с ролью из приглашения.
+//	@ID				acceptInvitation
+//	@Produce		json
+//	@Param			code	path		string	true	"Invitation code"
+//	@Success		200		{object}	hubs.Invitation
+//	@Failure		404		{string}	string	"Invitation not found or expired"
+//	@Failure		409		{string}	string	"Already a member"
+//	@Failure		500		{string}	string	"Internal Server Error"
+//	@Router			/invitations/{code} [post]
+func AcceptInvitation(service HubService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		inv, err := service.Join(c.Request().Context(), usernameFromToken(c), c.Param("code"))
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return echo.NewHTTPError(http.StatusNotFound, "Invitation not found or expired")
+			}
+			return hubError(c, err)
+		}
+
+		return c.JSON(http.StatusOK, inv)
+	}
+}
+
+// SetMemberRole godoc
+//
+//	@Tags			hub
+//	@Summary		Изменяет роль участника хаба.
+//	@Description	Изменяет роль участника хаба. Доступно владельцам хаба, последнего владельца понизить нельзя.
+//	@ID				setMemberRole
+//	@Accept			json
+//	@Param			hub_name	path	string				true	"Hub name"
+//	@Param			username	path	string				true	"Member username"
+//	@Param			request		body	hubs.RoleRequest	true	"New role"
+//	@Success		204
+//	@Failure		400	{string}	string	"Bad Request"
+//	@Failure		403	{string}	string	"Forbidden"
+//	@Failure		404	{string}	string	"Member not found"
+//	@Failure		409	{string}	string	"Hub must have at least one owner"
+//	@Failure		500	{string}	string	"Internal Server Error"
+//	@Router			/hubs/{hub_name}/members/{username} [put]
+func SetMemberRole(service HubService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		role, err := bindRole(c)
+		if err != nil {
+			return err
+		}
+
+		err = service.SetRole(c.Request().Context(), usernameFromToken(c), c.Param("hubName"), c.Param("username"), role)
+		if err != nil {
+			return hubError(c, err)
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// RemoveMember godoc
+//
+//	@Tags			hub
+//	@Summary		Удаляет участника хаба.
+//	@Description	Удаляет участника хаба. Доступно владельцам хаба, участник может покинуть хаб сам.
+//	@ID				removeMember
+//	@Param			hub_name	path	string	true	"Hub name"
+//	@Param			username	path	string	true	"Member username"
+//	@Success		204
+//	@Failure		403	{string}	string	"Forbidden"
+//	@Failure		404	{string}	string	"Member not found"
+//	@Failure		409	{string}	string	"Hub must have at least one owner"
+//	@Failure		500	{string}	string	"Internal Server Error"
+//	@Router			/hubs/{hub_name}/members/{username} [delete]
+func RemoveMember(service HubService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		err := service.RemoveMember(c.Request().Context(), usernameFromToken(c), c.Param("hubName"), c.Param("username"))
+		if err != nil {
+			return hubError(c, err)
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// hubError приводит ошибки сервиса к ответам http
+func hubError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	case errors.Is(err, ErrLastOwner):
+		return echo.NewHTTPError(http.StatusConflict, "Hub must have at least one owner")
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return echo.NewHTTPError(http.StatusConflict, "Already a member")
+	case errors.Is(err, repository.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Member not found")
+	}
+	c.Logger().Error(err)
+	return echo.ErrInternalServerError
+}
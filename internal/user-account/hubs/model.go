@@ -0,0 +1,37 @@
+package hubs
+
+import (
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// Hub хаб, доступный пользователю, и роль пользователя в нем
+type Hub struct {
+	ID   int64      `db:"id" json:"-"`
+	Name string     `db:"name" json:"name"`
+	Role model.Role `db:"role" json:"role"`
+}
+
+// Member участник хаба
+type Member struct {
+	UserID   int64      `db:"user_id" json:"-"`
+	Username string     `db:"username" json:"username"`
+	TGUser   string     `db:"tg_user" json:"tg_user"`
+	Role     model.Role `db:"role" json:"role"`
+}
+
+// Invitation приглашение в хаб, действует до ExpiresAt и может быть использовано один раз
+type Invitation struct {
+	Code      string     `db:"code" json:"code"`
+	HubID     int64      `db:"client_id" json:"-"`
+	HubName   string     `db:"client_name" json:"hub_name"`
+	Role      model.Role `db:"role" json:"role"`
+	CreatedBy int64      `db:"created_by" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+}
+
+// RoleRequest запрос приглашения в хаб или изменения роли участника
+type RoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner operator viewer"`
+}
@@ -0,0 +1,172 @@
+package hubs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/repository"
+	"github.com/c0dered273/automation-remote-controller/pkg/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// HubRepository описывает методы работы с участниками хабов и приглашениями
+type HubRepository interface {
+	// FindHubsByUsername возвращает хабы, участником которых является пользователь
+	FindHubsByUsername(ctx context.Context, username string) ([]Hub, error)
+	// FindHubByUsername возвращает хаб и роль пользователя в нем
+	FindHubByUsername(ctx context.Context, username string, hubName string) (Hub, error)
+	// FindMembers возвращает участников хаба
+	FindMembers(ctx context.Context, hubID int64) ([]Member, error)
+	// UpdateMemberRole изменяет роль участника хаба
+	UpdateMemberRole(ctx context.Context, hubID int64, username string, role model.Role) error
+	// DeleteMember удаляет участника хаба
+	DeleteMember(ctx context.Context, hubID int64, username string) error
+	// CountOwners возвращает количество владельцев хаба
+	CountOwners(ctx context.Context, hubID int64) (int, error)
+	// SaveInvitation сохраняет приглашение в хаб
+	SaveInvitation(ctx context.Context, username string, inv Invitation) error
+	// AcceptInvitation добавляет пользователя в хаб по действующему приглашению и удаляет приглашение
+	AcceptInvitation(ctx context.Context, code string, username string, now time.Time) (Invitation, error)
+}
+
+// SQLHubRepo для хранения данных используется стандартный пакет database/sql c оберткой sqlx
+type SQLHubRepo struct {
+	db *sqlx.DB
+}
+
+const selectHubs = `SELECT c.id, c.name, m.role
+FROM hub_members m
+         JOIN users u ON u.id = m.user_id
+         JOIN clients c ON c.id = m.client_id`
+
+func (r SQLHubRepo) FindHubsByUsername(ctx context.Context, username string) ([]Hub, error) {
+	const sqlQuery = selectHubs + ` WHERE u.username = $1 ORDER BY c.name`
+
+	result := make([]Hub, 0)
+	err := r.db.SelectContext(ctx, &result, sqlQuery, username)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r SQLHubRepo) FindHubByUsername(ctx context.Context, username string, hubName string) (Hub, error) {
+	const sqlQuery = selectHubs + ` WHERE u.username = $1 AND c.name = $2`
+
+	hub := Hub{}
+	err := r.db.GetContext(ctx, &hub, sqlQuery, username, hubName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Hub{}, repository.ErrNotFound
+		}
+		return Hub{}, err
+	}
+
+	return hub, nil
+}
+
+func (r SQLHubRepo) FindMembers(ctx context.Context, hubID int64) ([]Member, error) {
+	const sqlQuery = `SELECT m.user_id, u.username, u.tg_user, m.role
+		FROM hub_members m JOIN users u ON u.id = m.user_id
+		WHERE m.client_id = $1
+		ORDER BY m.created_at`
+
+	result := make([]Member, 0)
+	err := r.db.SelectContext(ctx, &result, sqlQuery, hubID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r SQLHubRepo) UpdateMemberRole(ctx context.Context, hubID int64, username string, role model.Role) error {
+	const sqlQuery = `UPDATE hub_members m SET role = $3 FROM users u
+		WHERE m.user_id = u.id AND m.client_id = $1 AND u.username = $2`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, hubID, username, role)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r SQLHubRepo) DeleteMember(ctx context.Context, hubID int64, username string) error {
+	const sqlQuery = `DELETE FROM hub_members m USING users u
+		WHERE m.user_id = u.id AND m.client_id = $1 AND u.username = $2`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, hubID, username)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r SQLHubRepo) CountOwners(ctx context.Context, hubID int64) (int, error) {
+	const sqlQuery = `SELECT count(*) FROM hub_members WHERE client_id = $1 AND role = $2`
+
+	var count int
+	err := r.db.GetContext(ctx, &count, sqlQuery, hubID, model.RoleOwner)
+	return count, err
+}
+
+func (r SQLHubRepo) SaveInvitation(ctx context.Context, username string, inv Invitation) error {
+	const sqlQuery = `INSERT INTO hub_invitations(code, client_id, role, created_by, expires_at)
+		SELECT $1, $2, $3, u.id, $4 FROM users u WHERE u.username = $5`
+
+	_, err := r.db.ExecContext(ctx, sqlQuery, inv.Code, inv.HubID, inv.Role, inv.ExpiresAt, username)
+	return err
+}
+
+func (r SQLHubRepo) AcceptInvitation(ctx context.Context, code string, username string, now time.Time) (Invitation, error) {
+	const takeQuery = `DELETE FROM hub_invitations i USING clients c
+		WHERE c.id = i.client_id AND i.code = $1 AND i.expires_at > $2
+		RETURNING i.code, i.client_id, c.name AS client_name, i.role, i.created_by, i.expires_at`
+	const memberQuery = `INSERT INTO hub_members(client_id, user_id, role)
+		SELECT $1, u.id, $2 FROM users u WHERE u.username = $3`
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return Invitation{}, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	inv := Invitation{}
+	if err := tx.GetContext(ctx, &inv, takeQuery, code, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Invitation{}, repository.ErrNotFound
+		}
+		return Invitation{}, err
+	}
+	if _, err := tx.ExecContext(ctx, memberQuery, inv.HubID, inv.Role, username); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return Invitation{}, repository.ErrAlreadyExists
+		}
+		return Invitation{}, err
+	}
+
+	return inv, tx.Commit()
+}
+
+func NewRepo(db *sqlx.DB) SQLHubRepo {
+	return SQLHubRepo{
+		db: db,
+	}
+}
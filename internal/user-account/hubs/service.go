@@ -0,0 +1,156 @@
+package hubs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/repository"
+	"github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// InvitationTTL время действия приглашения в хаб
+const InvitationTTL = 24 * time.Hour
+
+var (
+	ErrForbidden = errors.New("hubs: access denied")
+	ErrLastOwner = errors.New("hubs: hub must have at least one owner")
+)
+
+// HubService сервис управления доступом к хабам
+type HubService interface {
+	// FindHubs возвращает хабы, участником которых является пользователь
+	FindHubs(ctx context.Context, username string) ([]Hub, error)
+	// Authorize проверяет, что пользователю разрешено действие с хабом
+	Authorize(ctx context.Context, username string, hubName string, perm model.Permission) (Hub, error)
+	// FindMembers возвращает участников хаба
+	FindMembers(ctx context.Context, username string, hubName string) ([]Member, error)
+	// Invite создает приглашение в хаб с указанной ролью
+	Invite(ctx context.Context, username string, hubName string, role model.Role) (Invitation, error)
+	// Join добавляет пользователя в хаб по коду приглашения
+	Join(ctx context.Context, username string, code string) (Invitation, error)
+	// SetRole изменяет роль участника хаба
+	SetRole(ctx context.Context, username string, hubName string, member string, role model.Role) error
+	// RemoveMember удаляет участника хаба, участник может покинуть хаб сам
+	RemoveMember(ctx context.Context, username string, hubName string, member string) error
+}
+
+type HubServiceImpl struct {
+	hubRepo HubRepository
+}
+
+func (s HubServiceImpl) FindHubs(ctx context.Context, username string) ([]Hub, error) {
+	return s.hubRepo.FindHubsByUsername(ctx, username)
+}
+
+func (s HubServiceImpl) Authorize(ctx context.Context, username string, hubName string, perm model.Permission) (Hub, error) {
+	hub, err := s.hubRepo.FindHubByUsername(ctx, username, hubName)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return Hub{}, ErrForbidden
+		}
+		return Hub{}, err
+	}
+	if !hub.Role.Can(perm) {
+		return Hub{}, ErrForbidden
+	}
+	return hub, nil
+}
+
+func (s HubServiceImpl) FindMembers(ctx context.Context, username string, hubName string) ([]Member, error) {
+	hub, err := s.Authorize(ctx, username, hubName, model.ManageHub)
+	if err != nil {
+		return nil, err
+	}
+	return s.hubRepo.FindMembers(ctx, hub.ID)
+}
+
+func (s HubServiceImpl) Invite(ctx context.Context, username string, hubName string, role model.Role) (Invitation, error) {
+	hub, err := s.Authorize(ctx, username, hubName, model.ManageHub)
+	if err != nil {
+		return Invitation{}, err
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return Invitation{}, fmt.Errorf("generate invitation code: %w", err)
+	}
+	inv := Invitation{
+		Code:      hex.EncodeToString(b),
+		HubID:     hub.ID,
+		HubName:   hub.Name,
+		Role:      role,
+		ExpiresAt: time.Now().Add(InvitationTTL),
+	}
+	if err := s.hubRepo.SaveInvitation(ctx, username, inv); err != nil {
+		return Invitation{}, fmt.Errorf("save invitation: %w", err)
+	}
+
+	return inv, nil
+}
+
+func (s HubServiceImpl) Join(ctx context.Context, username string, code string) (Invitation, error) {
+	return s.hubRepo.AcceptInvitation(ctx, code, username, time.Now())
+}
+
+// checkOwners не дает удалить или понизить последнего владельца хаба
+func (s HubServiceImpl) checkOwners(ctx context.Context, hubID int64, member string) error {
+	members, err := s.hubRepo.FindMembers(ctx, hubID)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		if m.Username != member {
+			continue
+		}
+		if m.Role != model.RoleOwner {
+			return nil
+		}
+		owners, err := s.hubRepo.CountOwners(ctx, hubID)
+		if err != nil {
+			return err
+		}
+		if owners <= 1 {
+			return ErrLastOwner
+		}
+		return nil
+	}
+	return repository.ErrNotFound
+}
+
+func (s HubServiceImpl) SetRole(ctx context.Context, username string, hubName string, member string, role model.Role) error {
+	hub, err := s.Authorize(ctx, username, hubName, model.ManageHub)
+	if err != nil {
+		return err
+	}
+	if role != model.RoleOwner {
+		if err := s.checkOwners(ctx, hub.ID, member); err != nil {
+			return err
+		}
+	}
+	return s.hubRepo.UpdateMemberRole(ctx, hub.ID, member, role)
+}
+
+func (s HubServiceImpl) RemoveMember(ctx context.Context, username string, hubName string, member string) error {
+	perm := model.ManageHub
+	if username == member {
+		perm = model.ViewStatus
+	}
+	hub, err := s.Authorize(ctx, username, hubName, perm)
+	if err != nil {
+		return err
+	}
+	if err := s.checkOwners(ctx, hub.ID, member); err != nil {
+		return err
+	}
+	return s.hubRepo.DeleteMember(ctx, hub.ID, member)
+}
+
+func NewHubService(hubRepo HubRepository) HubServiceImpl {
+	return HubServiceImpl{
+		hubRepo: hubRepo,
+	}
+}
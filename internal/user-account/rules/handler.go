@@ -0,0 +1,205 @@
+package rules
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/repository"
+	"github.com/c0dered273/automation-remote-controller/pkg/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+func usernameFromToken(c echo.Context) string {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(*auth.JwtCustomClaims)
+	return claims.Username
+}
+
+func ruleIDParam(c echo.Context) (int64, error) {
+	return strconv.ParseInt(c.Param("ruleID"), 10, 64)
+}
+
+// FindRules godoc
+//
+//	@Tags			rule
+//	@Summary		Возвращает правила автоматизации пользователя.
+//	@Description	Возвращает все правила пользователя вместе с условиями и действиями.
+//	@ID				findRules
+//	@Produce		json
+//	@Success		200	{array}		rules.Rule
+//	@Failure		500	{string}	string	"Internal Server Error"
+//	@Router			/rules [get]
+func FindRules(service RuleService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		result, err := service.FindRules(c.Request().Context(), usernameFromToken(c))
+		if err != nil {
+			c.Logger().Error(err)
+			return echo.ErrInternalServerError
+		}
+
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// FindRule godoc
+//
+//	@Tags			rule
+//	@Summary		Возвращает правило автоматизации пользователя.
+//	@Description	Возвращает правило пользователя по идентификатору.
+//	@ID				findRule
+//	@Produce		json
+//	@Param			rule_id	path		int	true	"Rule id"
+//	@Success		200			{object}	rules.Rule
+//	@Failure		404			{string}	string	"Rule not found"
+//	@Failure		500			{string}	string	"Internal Server Error"
+//	@Router			/rules/{rule_id} [get]
+func FindRule(service RuleService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		id, err := ruleIDParam(c)
+		if err != nil {
+			return echo.ErrBadRequest
+		}
+
+		rule, err := service.FindRule(c.Request().Context(), usernameFromToken(c), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return echo.NewHTTPError(http.StatusNotFound, "Rule not found")
+			}
+			c.Logger().Error(err)
+			return echo.ErrInternalServerError
+		}
+
+		return c.JSON(http.StatusOK, rule)
+	}
+}
+
+// NewRule godoc
+//
+//	@Tags			rule
+//	@Summary		Создает правило автоматизации.
+//	@Description	Создает правило: при событии хаба, удовлетворяющем условиям, по порядку выполняются действия. Пользователь должен иметь доступ ко всем упомянутым хабам и сценариям.
+//	@ID				newRule
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		rules.RuleRequest	true	"New rule request"
+//	@Success		201		{object}	rules.Rule
+//	@Failure		400		{string}	string	"Bad Request"
+//	@Failure		403		{string}	string	"Forbidden"
+//	@Failure		409		{string}	string	"Rule already exists"
+//	@Failure		500		{string}	string	"Internal Server Error"
+//	@Router			/rules [post]
+func NewRule(service RuleService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		req := RuleRequest{}
+		if err := c.Bind(&req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		if err := c.Validate(req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		rule, err := service.NewRule(c.Request().Context(), usernameFromToken(c), req)
+		if err != nil {
+			return ruleError(c, err)
+		}
+
+		return c.JSON(http.StatusCreated, rule)
+	}
+}
+
+// UpdateRule godoc
+//
+//	@Tags			rule
+//	@Summary		Изменяет правило автоматизации.
+//	@Description	Заменяет условия и действия правила, сбрасывает время последнего срабатывания.
+//	@ID				updateRule
+//	@Accept			json
+//	@Produce		json
+//	@Param			rule_id	path		int					true	"Rule id"
+//	@Param			request		body		rules.RuleRequest	true	"Rule request"
+//	@Success		200			{object}	rules.Rule
+//	@Failure		400			{string}	string	"Bad Request"
+//	@Failure		403			{string}	string	"Forbidden"
+//	@Failure		404			{string}	string	"Rule not found"
+//	@Failure		409			{string}	string	"Rule already exists"
+//	@Failure		500			{string}	string	"Internal Server Error"
+//	@Router			/rules/{rule_id} [put]
+func UpdateRule(service RuleService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		id, err := ruleIDParam(c)
+		if err != nil {
+			return echo.ErrBadRequest
+		}
+
+		req := RuleRequest{}
+		if err := c.Bind(&req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		if err := c.Validate(req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		rule, err := service.UpdateRule(c.Request().Context(), usernameFromToken(c), id, req)
+		if err != nil {
+			return ruleError(c, err)
+		}
+
+		return c.JSON(http.StatusOK, rule)
+	}
+}
+
+// DeleteRule godoc
+//
+//	@Tags			rule
+//	@Summary		Удаляет правило автоматизации.
+//	@Description	Удаляет правило пользователя.
+//	@ID				deleteRule
+//	@Param			rule_id	path	int	true	"Rule id"
+//	@Success		204
+//	@Failure		404	{string}	string	"Rule not found"
+//	@Failure		500	{string}	string	"Internal Server Error"
+//	@Router			/rules/{rule_id} [delete]
+func DeleteRule(service RuleService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		id, err := ruleIDParam(c)
+		if err != nil {
+			return echo.ErrBadRequest
+		}
+
+		err = service.DeleteRule(c.Request().Context(), usernameFromToken(c), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return echo.NewHTTPError(http.StatusNotFound, "Rule not found")
+			}
+			c.Logger().Error(err)
+			return echo.ErrInternalServerError
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// ruleError приводит ошибки сервиса к ответам http
+func ruleError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidRule):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, hubs.ErrForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	case errors.Is(err, repository.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Rule not found")
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return echo.NewHTTPError(http.StatusConflict, "Rule already exists")
+	}
+	c.Logger().Error(err)
+	return echo.ErrInternalServerError
+}
@@ -0,0 +1,41 @@
+package rules
+
+import (
+	"time"
+
+	"github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// Rule правило автоматизации пользователя
+type Rule struct {
+	ID          int64               `db:"id" json:"id"`
+	Name        string              `db:"name" json:"name"`
+	Enabled     bool                `db:"enabled" json:"enabled"`
+	Condition   model.RuleCondition `db:"condition" json:"condition"`
+	Actions     model.RuleActions   `db:"actions" json:"actions"`
+	CooldownSec int                 `db:"cooldown_sec" json:"cooldown_sec"`
+	LastFiredAt *time.Time          `db:"last_fired_at" json:"last_fired_at,omitempty"`
+}
+
+// RuleRequest запрос создания или изменения правила
+type RuleRequest struct {
+	Name        string              `json:"name" validate:"required,max=64"`
+	Enabled     *bool               `json:"enabled"`
+	Condition   model.RuleCondition `json:"condition"`
+	Actions     []model.RuleAction  `json:"actions" validate:"required,min=1,dive"`
+	CooldownSec int                 `json:"cooldown_sec" validate:"gte=0"`
+}
+
+func (r RuleRequest) toRule() Rule {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return Rule{
+		Name:        r.Name,
+		Enabled:     enabled,
+		Condition:   r.Condition,
+		Actions:     r.Actions,
+		CooldownSec: r.CooldownSec,
+	}
+}
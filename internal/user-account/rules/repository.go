@@ -0,0 +1,118 @@
+package rules
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/repository"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// RuleRepository описывает методы работы с правилами автоматизации пользователя
+type RuleRepository interface {
+	// SaveRule сохраняет новое правило
+	SaveRule(ctx context.Context, username string, rule Rule) (int64, error)
+	// UpdateRule заменяет параметры правила, время последнего срабатывания сбрасывается
+	UpdateRule(ctx context.Context, username string, rule Rule) error
+	// FindRulesByUsername возвращает правила пользователя
+	FindRulesByUsername(ctx context.Context, username string) ([]Rule, error)
+	// FindRuleByUsername возвращает правило пользователя по идентификатору
+	FindRuleByUsername(ctx context.Context, username string, id int64) (Rule, error)
+	// DeleteRuleByUsername удаляет правило пользователя
+	DeleteRuleByUsername(ctx context.Context, username string, id int64) error
+}
+
+// SQLRuleRepo для хранения данных используется стандартный пакет database/sql c оберткой sqlx
+type SQLRuleRepo struct {
+	db *sqlx.DB
+}
+
+const selectRules = `SELECT r.id, r.name, r.enabled, r.condition, r.actions, r.cooldown_sec, r.last_fired_at
+FROM rules r
+         JOIN users u ON u.id = r.user_id`
+
+func (r SQLRuleRepo) SaveRule(ctx context.Context, username string, rule Rule) (int64, error) {
+	const sqlQuery = `INSERT INTO rules(user_id, name, enabled, condition, actions, cooldown_sec)
+			VALUES((SELECT id FROM users WHERE username = $1), $2, $3, $4, $5, $6)
+			RETURNING id`
+
+	var id int64
+	err := r.db.GetContext(ctx, &id, sqlQuery, username, rule.Name, rule.Enabled, rule.Condition, rule.Actions, rule.CooldownSec)
+	if err != nil {
+		return 0, mapRuleErr(err)
+	}
+
+	return id, nil
+}
+
+func (r SQLRuleRepo) UpdateRule(ctx context.Context, username string, rule Rule) error {
+	const sqlQuery = `UPDATE rules r SET name = $3, enabled = $4, condition = $5, actions = $6, cooldown_sec = $7, last_fired_at = NULL
+			FROM users u
+			WHERE r.user_id = u.id AND u.username = $1 AND r.id = $2`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, username, rule.ID, rule.Name, rule.Enabled, rule.Condition, rule.Actions, rule.CooldownSec)
+	if err != nil {
+		return mapRuleErr(err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r SQLRuleRepo) FindRulesByUsername(ctx context.Context, username string) ([]Rule, error) {
+	const sqlQuery = selectRules + ` WHERE u.username = $1 ORDER BY r.name`
+
+	result := make([]Rule, 0)
+	err := r.db.SelectContext(ctx, &result, sqlQuery, username)
+	return result, err
+}
+
+func (r SQLRuleRepo) FindRuleByUsername(ctx context.Context, username string, id int64) (Rule, error) {
+	const sqlQuery = selectRules + ` WHERE u.username = $1 AND r.id = $2`
+
+	rule := Rule{}
+	err := r.db.GetContext(ctx, &rule, sqlQuery, username, id)
+	if err != nil {
+		return Rule{}, mapRuleErr(err)
+	}
+
+	return rule, nil
+}
+
+func (r SQLRuleRepo) DeleteRuleByUsername(ctx context.Context, username string, id int64) error {
+	const sqlQuery = `DELETE FROM rules r USING users u WHERE r.user_id = u.id AND u.username = $1 AND r.id = $2`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, username, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// mapRuleErr приводит ошибки БД к ошибкам репозитория
+func mapRuleErr(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return repository.ErrAlreadyExists
+	}
+	return err
+}
+
+func NewRepo(db *sqlx.DB) SQLRuleRepo {
+	return SQLRuleRepo{
+		db: db,
+	}
+}
@@ -0,0 +1,126 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/repository"
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/scenes"
+	"github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+var (
+	ErrInvalidRule = errors.New("rules: invalid rule")
+)
+
+// RuleService сервис обрабатывает запросы с правилами автоматизации пользователя
+type RuleService interface {
+	// NewRule проверяет и сохраняет новое правило
+	NewRule(ctx context.Context, username string, req RuleRequest) (Rule, error)
+	// UpdateRule заменяет параметры правила
+	UpdateRule(ctx context.Context, username string, id int64, req RuleRequest) (Rule, error)
+	// FindRules возвращает все правила пользователя
+	FindRules(ctx context.Context, username string) ([]Rule, error)
+	// FindRule возвращает правило пользователя по идентификатору
+	FindRule(ctx context.Context, username string, id int64) (Rule, error)
+	// DeleteRule удаляет правило пользователя
+	DeleteRule(ctx context.Context, username string, id int64) error
+}
+
+type RuleServiceImpl struct {
+	ruleRepo     RuleRepository
+	hubService   hubs.HubService
+	sceneService scenes.SceneService
+}
+
+// check проверяет условия и действия правила и права пользователя на упомянутые хабы и сценарии
+func (s RuleServiceImpl) check(ctx context.Context, username string, rule Rule) error {
+	if err := rule.Condition.Validate(); err != nil {
+		return fmt.Errorf("%w, %s", ErrInvalidRule, err)
+	}
+
+	watched := append([]string{}, rule.Condition.HubsOnline...)
+	watched = append(watched, rule.Condition.HubsOffline...)
+	if len(rule.Condition.Hub) != 0 {
+		watched = append(watched, rule.Condition.Hub)
+	}
+	for _, hubName := range watched {
+		if _, err := s.hubService.Authorize(ctx, username, hubName, model.ViewStatus); err != nil {
+			return err
+		}
+	}
+
+	for i, action := range rule.Actions {
+		if err := action.Validate(); err != nil {
+			return fmt.Errorf("%w, action %d: %s", ErrInvalidRule, i+1, err)
+		}
+		switch action.Type {
+		case model.RuleActionDevice, model.RuleActionNotify:
+			if _, err := s.hubService.Authorize(ctx, username, action.Hub, model.SendActions); err != nil {
+				return err
+			}
+		case model.RuleActionScene:
+			_, err := s.sceneService.FindScene(ctx, username, action.SceneID)
+			if errors.Is(err, repository.ErrNotFound) {
+				return fmt.Errorf("%w, action %d: scene %d not found", ErrInvalidRule, i+1, action.SceneID)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s RuleServiceImpl) NewRule(ctx context.Context, username string, req RuleRequest) (Rule, error) {
+	rule := req.toRule()
+	if err := s.check(ctx, username, rule); err != nil {
+		return Rule{}, err
+	}
+
+	id, err := s.ruleRepo.SaveRule(ctx, username, rule)
+	if err != nil {
+		return Rule{}, fmt.Errorf("save rule: %w", err)
+	}
+	rule.ID = id
+
+	return rule, nil
+}
+
+func (s RuleServiceImpl) UpdateRule(ctx context.Context, username string, id int64, req RuleRequest) (Rule, error) {
+	rule := req.toRule()
+	rule.ID = id
+	if err := s.check(ctx, username, rule); err != nil {
+		return Rule{}, err
+	}
+
+	err := s.ruleRepo.UpdateRule(ctx, username, rule)
+	if err != nil {
+		return Rule{}, fmt.Errorf("update rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (s RuleServiceImpl) FindRules(ctx context.Context, username string) ([]Rule, error) {
+	return s.ruleRepo.FindRulesByUsername(ctx, username)
+}
+
+func (s RuleServiceImpl) FindRule(ctx context.Context, username string, id int64) (Rule, error) {
+	return s.ruleRepo.FindRuleByUsername(ctx, username, id)
+}
+
+func (s RuleServiceImpl) DeleteRule(ctx context.Context, username string, id int64) error {
+	return s.ruleRepo.DeleteRuleByUsername(ctx, username, id)
+}
+
+func NewRuleService(ruleRepo RuleRepository, hubService hubs.HubService, sceneService scenes.SceneService) RuleServiceImpl {
+	return RuleServiceImpl{
+		ruleRepo:     ruleRepo,
+		hubService:   hubService,
+		sceneService: sceneService,
+	}
+}
@@ -0,0 +1,206 @@
+package scenes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/repository"
+	"github.com/c0dered273/automation-remote-controller/pkg/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+func usernameFromToken(c echo.Context) string {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(*auth.JwtCustomClaims)
+	return claims.Username
+}
+
+func sceneIDParam(c echo.Context) (int64, error) {
+	return strconv.ParseInt(c.Param("sceneID"), 10, 64)
+}
+
+// FindScenes godoc
+//
+//	@Tags			scene
+//	@Summary		Возвращает сценарии пользователя.
+//	@Description	Возвращает все сценарии пользователя вместе с шагами.
+//	@ID				findScenes
+//	@Produce		json
+//	@Success		200	{array}		scenes.Scene
+//	@Failure		500	{string}	string	"Internal Server Error"
+//	@Router			/scenes [get]
+func FindScenes(service SceneService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		result, err := service.FindScenes(c.Request().Context(), usernameFromToken(c))
+		if err != nil {
+			c.Logger().Error(err)
+			return echo.ErrInternalServerError
+		}
+
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// FindScene godoc
+//
+//	@Tags			scene
+//	@Summary		Возвращает сценарий пользователя.
+//	@Description	Возвращает сценарий пользователя вместе с шагами.
+//	@ID				findScene
+//	@Produce		json
+//	@Param			scene_id	path		int	true	"Scene id"
+//	@Success		200			{object}	scenes.Scene
+//	@Failure		404			{string}	string	"Scene not found"
+//	@Failure		500			{string}	string	"Internal Server Error"
+//	@Router			/scenes/{scene_id} [get]
+func FindScene(service SceneService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		id, err := sceneIDParam(c)
+		if err != nil {
+			return echo.ErrBadRequest
+		}
+
+		scene, err := service.FindScene(c.Request().Context(), usernameFromToken(c), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return echo.NewHTTPError(http.StatusNotFound, "Scene not found")
+			}
+			c.Logger().Error(err)
+			return echo.ErrInternalServerError
+		}
+
+		return c.JSON(http.StatusOK, scene)
+	}
+}
+
+// NewScene godoc
+//
+//	@Tags			scene
+//	@Summary		Создает сценарий.
+//	@Description	Создает сценарий для хаба пользователя. Шаги выполняются в указанном порядке.
+//	@ID				newScene
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		scenes.SceneRequest	true	"New scene request"
+//	@Success		201		{object}	scenes.Scene
+//	@Failure		400		{string}	string	"Bad Request"
+//	@Failure		403		{string}	string	"Forbidden"
+//	@Failure		404		{string}	string	"Client not found"
+//	@Failure		409		{string}	string	"Scene already exists"
+//	@Failure		500		{string}	string	"Internal Server Error"
+//	@Router			/scenes [post]
+func NewScene(service SceneService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		req := SceneRequest{}
+		if err := c.Bind(&req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		if err := c.Validate(req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		scene, err := service.NewScene(c.Request().Context(), usernameFromToken(c), req)
+		if err != nil {
+			return sceneError(c, err)
+		}
+
+		return c.JSON(http.StatusCreated, scene)
+	}
+}
+
+// UpdateScene godoc
+//
+//	@Tags			scene
+//	@Summary		Изменяет сценарий.
+//	@Description	Заменяет параметры и шаги сценария пользователя.
+//	@ID				updateScene
+//	@Accept			json
+//	@Produce		json
+//	@Param			scene_id	path		int					true	"Scene id"
+//	@Param			request		body		scenes.SceneRequest	true	"Scene request"
+//	@Success		200			{object}	scenes.Scene
+//	@Failure		400			{string}	string	"Bad Request"
+//	@Failure		403			{string}	string	"Forbidden"
+//	@Failure		404			{string}	string	"Scene not found"
+//	@Failure		409			{string}	string	"Scene already exists"
+//	@Failure		500			{string}	string	"Internal Server Error"
+//	@Router			/scenes/{scene_id} [put]
+func UpdateScene(service SceneService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		id, err := sceneIDParam(c)
+		if err != nil {
+			return echo.ErrBadRequest
+		}
+
+		req := SceneRequest{}
+		if err := c.Bind(&req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		if err := c.Validate(req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		scene, err := service.UpdateScene(c.Request().Context(), usernameFromToken(c), id, req)
+		if err != nil {
+			return sceneError(c, err)
+		}
+
+		return c.JSON(http.StatusOK, scene)
+	}
+}
+
+// DeleteScene godoc
+//
+//	@Tags			scene
+//	@Summary		Удаляет сценарий.
+//	@Description	Удаляет сценарий пользователя.
+//	@ID				deleteScene
+//	@Param			scene_id	path	int	true	"Scene id"
+//	@Success		204
+//	@Failure		404	{string}	string	"Scene not found"
+//	@Failure		500	{string}	string	"Internal Server Error"
+//	@Router			/scenes/{scene_id} [delete]
+func DeleteScene(service SceneService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		id, err := sceneIDParam(c)
+		if err != nil {
+			return echo.ErrBadRequest
+		}
+
+		err = service.DeleteScene(c.Request().Context(), usernameFromToken(c), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return echo.NewHTTPError(http.StatusNotFound, "Scene not found")
+			}
+			c.Logger().Error(err)
+			return echo.ErrInternalServerError
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// sceneError приводит ошибки сервиса к ответам http
+func sceneError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidStep):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, hubs.ErrForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	case errors.Is(err, repository.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Scene or client not found")
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return echo.NewHTTPError(http.StatusConflict, "Scene already exists")
+	}
+	c.Logger().Error(err)
+	return echo.ErrInternalServerError
+}
@@ -0,0 +1,36 @@
+package scenes
+
+// Scene описывает сценарий - именованную группу команд для одного хаба
+type Scene struct {
+	ID            int64  `db:"id" json:"id"`
+	ClientName    string `db:"client_name" json:"client_name"`
+	Name          string `db:"name" json:"name"`
+	StopOnFailure bool   `db:"stop_on_failure" json:"stop_on_failure"`
+	Steps         []Step `db:"-" json:"steps"`
+}
+
+// Step шаг сценария
+type Step struct {
+	SceneID  int64  `db:"scene_id" json:"-"`
+	Position int    `db:"position" json:"-"`
+	DeviceID string `db:"device_id" json:"device_id" validate:"required"`
+	Action   string `db:"action" json:"action" validate:"required"`
+	DelayMs  int64  `db:"delay_ms" json:"delay_ms" validate:"gte=0"`
+}
+
+// SceneRequest запрос создания или изменения сценария
+type SceneRequest struct {
+	ClientName    string `json:"client_name" validate:"required"`
+	Name          string `json:"name" validate:"required,max=64"`
+	StopOnFailure bool   `json:"stop_on_failure"`
+	Steps         []Step `json:"steps" validate:"required,min=1,dive"`
+}
+
+func (r SceneRequest) toScene() Scene {
+	return Scene{
+		ClientName:    r.ClientName,
+		Name:          r.Name,
+		StopOnFailure: r.StopOnFailure,
+		Steps:         r.Steps,
+	}
+}
@@ -0,0 +1,184 @@
+package scenes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/repository"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// SceneRepository описывает методы работы со сценариями пользователя
+type SceneRepository interface {
+	// SaveScene сохраняет новый сценарий вместе с шагами
+	SaveScene(ctx context.Context, username string, scene Scene) (int64, error)
+	// UpdateScene заменяет параметры и шаги существующего сценария
+	UpdateScene(ctx context.Context, username string, scene Scene) error
+	// FindScenesByUsername возвращает сценарии пользователя вместе с шагами
+	FindScenesByUsername(ctx context.Context, username string) ([]Scene, error)
+	// FindSceneByUsername возвращает сценарий пользователя по идентификатору
+	FindSceneByUsername(ctx context.Context, username string, id int64) (Scene, error)
+	// DeleteSceneByUsername удаляет сценарий пользователя
+	DeleteSceneByUsername(ctx context.Context, username string, id int64) error
+}
+
+// SQLSceneRepo для хранения данных используется стандартный пакет database/sql c оберткой sqlx
+type SQLSceneRepo struct {
+	db *sqlx.DB
+}
+
+const selectScenes = `SELECT s.id, c.name AS client_name, s.name, s.stop_on_failure
+FROM scenes s
+         JOIN users u ON u.id = s.user_id
+         JOIN clients c ON c.id = s.client_id`
+
+func (r SQLSceneRepo) saveSteps(ctx context.Context, tx *sqlx.Tx, sceneID int64, steps []Step) error {
+	const sqlQuery = `INSERT INTO scene_steps(scene_id, position, device_id, action, delay_ms)
+			VALUES(:scene_id, :position, :device_id, :action, :delay_ms)`
+
+	for i, step := range steps {
+		step.SceneID = sceneID
+		step.Position = i
+		if _, err := tx.NamedExecContext(ctx, sqlQuery, step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r SQLSceneRepo) findSteps(ctx context.Context, sceneID int64) ([]Step, error) {
+	const sqlQuery = `SELECT scene_id, position, device_id, action, delay_ms FROM scene_steps WHERE scene_id = $1 ORDER BY position`
+
+	steps := make([]Step, 0)
+	err := r.db.SelectContext(ctx, &steps, sqlQuery, sceneID)
+	return steps, err
+}
+
+func (r SQLSceneRepo) SaveScene(ctx context.Context, username string, scene Scene) (int64, error) {
+	const sqlQuery = `INSERT INTO scenes(user_id, client_id, name, stop_on_failure)
+			SELECT u.id, c.id, $3, $4
+			FROM users u JOIN hub_members m ON m.user_id = u.id JOIN clients c ON c.id = m.client_id
+			WHERE u.username = $1 AND c.name = $2
+			RETURNING id`
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var id int64
+	err = tx.GetContext(ctx, &id, sqlQuery, username, scene.ClientName, scene.Name, scene.StopOnFailure)
+	if err != nil {
+		return 0, mapSceneErr(err)
+	}
+	if err := r.saveSteps(ctx, tx, id, scene.Steps); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+func (r SQLSceneRepo) UpdateScene(ctx context.Context, username string, scene Scene) error {
+	const sqlQuery = `UPDATE scenes s SET name = $3, stop_on_failure = $4, client_id = c.id
+			FROM users u JOIN hub_members m ON m.user_id = u.id JOIN clients c ON c.id = m.client_id
+			WHERE s.user_id = u.id AND u.username = $1 AND s.id = $2 AND c.name = $5
+			RETURNING s.id`
+	const deleteSteps = `DELETE FROM scene_steps WHERE scene_id = $1`
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var id int64
+	err = tx.GetContext(ctx, &id, sqlQuery, username, scene.ID, scene.Name, scene.StopOnFailure, scene.ClientName)
+	if err != nil {
+		return mapSceneErr(err)
+	}
+	if _, err := tx.ExecContext(ctx, deleteSteps, id); err != nil {
+		return err
+	}
+	if err := r.saveSteps(ctx, tx, id, scene.Steps); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r SQLSceneRepo) FindScenesByUsername(ctx context.Context, username string) ([]Scene, error) {
+	const sqlQuery = selectScenes + ` WHERE u.username = $1 ORDER BY s.name`
+
+	result := make([]Scene, 0)
+	err := r.db.SelectContext(ctx, &result, sqlQuery, username)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Steps, err = r.findSteps(ctx, result[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (r SQLSceneRepo) FindSceneByUsername(ctx context.Context, username string, id int64) (Scene, error) {
+	const sqlQuery = selectScenes + ` WHERE u.username = $1 AND s.id = $2`
+
+	scene := Scene{}
+	err := r.db.GetContext(ctx, &scene, sqlQuery, username, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Scene{}, repository.ErrNotFound
+		}
+		return Scene{}, err
+	}
+	scene.Steps, err = r.findSteps(ctx, id)
+	if err != nil {
+		return Scene{}, err
+	}
+
+	return scene, nil
+}
+
+func (r SQLSceneRepo) DeleteSceneByUsername(ctx context.Context, username string, id int64) error {
+	const sqlQuery = `DELETE FROM scenes s USING users u WHERE s.user_id = u.id AND u.username = $1 AND s.id = $2`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, username, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// mapSceneErr приводит ошибки БД к ошибкам репозитория
+func mapSceneErr(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return repository.ErrAlreadyExists
+	}
+	return err
+}
+
+func NewRepo(db *sqlx.DB) SQLSceneRepo {
+	return SQLSceneRepo{
+		db: db,
+	}
+}
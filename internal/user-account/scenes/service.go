@@ -0,0 +1,100 @@
+package scenes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/hubs"
+	"github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+var (
+	ErrInvalidStep = errors.New("scenes: invalid step")
+)
+
+// SceneService сервис обрабатывает запросы со сценариями пользователя
+type SceneService interface {
+	// NewScene проверяет и сохраняет новый сценарий
+	NewScene(ctx context.Context, username string, req SceneRequest) (Scene, error)
+	// UpdateScene заменяет параметры и шаги сценария
+	UpdateScene(ctx context.Context, username string, id int64, req SceneRequest) (Scene, error)
+	// FindScenes возвращает все сценарии пользователя
+	FindScenes(ctx context.Context, username string) ([]Scene, error)
+	// FindScene возвращает сценарий пользователя по идентификатору
+	FindScene(ctx context.Context, username string, id int64) (Scene, error)
+	// DeleteScene удаляет сценарий пользователя
+	DeleteScene(ctx context.Context, username string, id int64) error
+}
+
+type SceneServiceImpl struct {
+	sceneRepo  SceneRepository
+	hubService hubs.HubService
+}
+
+// normalizeSteps проверяет команды шагов и приводит их к каноническому виду
+func normalizeSteps(steps []Step) error {
+	for i, step := range steps {
+		action, err := model.NewAction(step.Action)
+		if err != nil {
+			return fmt.Errorf("%w %d, %s", ErrInvalidStep, i+1, err)
+		}
+		steps[i].Action = action.String()
+	}
+	return nil
+}
+
+func (s SceneServiceImpl) NewScene(ctx context.Context, username string, req SceneRequest) (Scene, error) {
+	scene := req.toScene()
+	if err := normalizeSteps(scene.Steps); err != nil {
+		return Scene{}, err
+	}
+	if _, err := s.hubService.Authorize(ctx, username, scene.ClientName, model.SendActions); err != nil {
+		return Scene{}, err
+	}
+
+	id, err := s.sceneRepo.SaveScene(ctx, username, scene)
+	if err != nil {
+		return Scene{}, fmt.Errorf("save scene: %w", err)
+	}
+	scene.ID = id
+
+	return scene, nil
+}
+
+func (s SceneServiceImpl) UpdateScene(ctx context.Context, username string, id int64, req SceneRequest) (Scene, error) {
+	scene := req.toScene()
+	scene.ID = id
+	if err := normalizeSteps(scene.Steps); err != nil {
+		return Scene{}, err
+	}
+	if _, err := s.hubService.Authorize(ctx, username, scene.ClientName, model.SendActions); err != nil {
+		return Scene{}, err
+	}
+
+	err := s.sceneRepo.UpdateScene(ctx, username, scene)
+	if err != nil {
+		return Scene{}, fmt.Errorf("update scene: %w", err)
+	}
+
+	return scene, nil
+}
+
+func (s SceneServiceImpl) FindScenes(ctx context.Context, username string) ([]Scene, error) {
+	return s.sceneRepo.FindScenesByUsername(ctx, username)
+}
+
+func (s SceneServiceImpl) FindScene(ctx context.Context, username string, id int64) (Scene, error) {
+	return s.sceneRepo.FindSceneByUsername(ctx, username, id)
+}
+
+func (s SceneServiceImpl) DeleteScene(ctx context.Context, username string, id int64) error {
+	return s.sceneRepo.DeleteSceneByUsername(ctx, username, id)
+}
+
+func NewSceneService(sceneRepo SceneRepository, hubService hubs.HubService) SceneServiceImpl {
+	return SceneServiceImpl{
+		sceneRepo:  sceneRepo,
+		hubService: hubService,
+	}
+}
@@ -4,10 +4,17 @@ import (
 	"os"
 	"time"
 
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/audit"
 	"github.com/c0dered273/automation-remote-controller/internal/user-account/clients"
 	"github.com/c0dered273/automation-remote-controller/internal/user-account/configs"
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/control"
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/devices"
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/rules"
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/scenes"
 	"github.com/c0dered273/automation-remote-controller/internal/user-account/storage"
 	"github.com/c0dered273/automation-remote-controller/internal/user-account/users"
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/webhooks"
 	"github.com/c0dered273/automation-remote-controller/pkg/auth"
 	"github.com/c0dered273/automation-remote-controller/pkg/loggers"
 	"github.com/c0dered273/automation-remote-controller/pkg/validators"
@@ -30,6 +37,14 @@ var (
 type Services struct {
 	UserService   users.UserService
 	ClientService clients.ClientService
+	SceneService  scenes.SceneService
+	HubService    hubs.HubService
+	PolicyService devices.PolicyService
+	AuditService  audit.AuditService
+	RuleService   rules.RuleService
+	TokenService  webhooks.TokenService
+	// ControlService управление устройствами через rc-tg-bot, nil если адрес бота не задан
+	ControlService control.ControlService
 }
 
 // NewServices настраивает сервисы
@@ -46,9 +61,46 @@ func NewServices(config *configs.UserAccountConfig, logger zerolog.Logger) (Serv
 	clientRepo := clients.NewRepo(db)
 	clientService := clients.NewClientService(clientRepo, userRepo, config.Client)
 
+	// Hubs
+	hubRepo := hubs.NewRepo(db)
+	hubService := hubs.NewHubService(hubRepo)
+
+	// Devices
+	policyRepo := devices.NewRepo(db)
+	policyService := devices.NewPolicyService(policyRepo, hubService)
+
+	// Audit
+	auditRepo := audit.NewRepo(db)
+	auditService := audit.NewAuditService(auditRepo)
+
+	// Scenes
+	sceneRepo := scenes.NewRepo(db)
+	sceneService := scenes.NewSceneService(sceneRepo, hubService)
+
+	// Rules
+	ruleRepo := rules.NewRepo(db)
+	ruleService := rules.NewRuleService(ruleRepo, hubService, sceneService)
+
+	// Webhooks
+	tokenRepo := webhooks.NewRepo(db)
+	tokenService := webhooks.NewTokenService(tokenRepo, hubService)
+
+	// Control
+	var controlService control.ControlService
+	if len(config.Bot.APIUrl) != 0 {
+		controlService = control.NewControlService(userRepo, control.NewBotClient(config.Bot))
+	}
+
 	return Services{
-		UserService:   userService,
-		ClientService: clientService,
+		UserService:    userService,
+		ClientService:  clientService,
+		SceneService:   sceneService,
+		HubService:     hubService,
+		PolicyService:  policyService,
+		AuditService:   auditService,
+		RuleService:    ruleService,
+		TokenService:   tokenService,
+		ControlService: controlService,
 	}, db
 }
 
@@ -64,6 +116,11 @@ func ReadConfig() *configs.UserAccountConfig {
 	return config
 }
 
+// isEventStream поток событий не ограничивается по времени и не сжимается
+func isEventStream(c echo.Context) bool {
+	return c.Path() == "/events"
+}
+
 // NewEchoServer возвращает настроенный сервер
 func NewEchoServer(s Services, config *configs.UserAccountConfig, logger zerolog.Logger, validator validators.Validator) *echo.Echo {
 	caKeyPair, err := auth.LoadKeyPair(config.CertFile, config.PKeyFile)
@@ -76,12 +133,14 @@ func NewEchoServer(s Services, config *configs.UserAccountConfig, logger zerolog
 	e.Validator = validator
 	e.Use(middleware.BodyLimit("10M"))
 	e.Use(middleware.TimeoutWithConfig(middleware.TimeoutConfig{
+		Skipper:      isEventStream,
 		Timeout:      15 * time.Second,
 		ErrorMessage: "Connection timeout",
 	}))
 	e.Use(middleware.Decompress())
 	e.Use(middleware.GzipWithConfig(middleware.GzipConfig{
-		Level: 5,
+		Skipper: isEventStream,
+		Level:   5,
 	}))
 	e.Use(loggers.RequestLoggerMiddleware(logger))
 	e.Use(middleware.Recover())
@@ -95,6 +154,35 @@ func NewEchoServer(s Services, config *configs.UserAccountConfig, logger zerolog
 	r := e.Group("/")
 	r.Use(echojwt.WithConfig(auth.GetJWTConfig(config.ApiSecret)))
 	r.PUT("clients/:clientName/register", clients.RegisterNewClient(s.ClientService, caKeyPair))
+	r.GET("scenes", scenes.FindScenes(s.SceneService))
+	r.POST("scenes", scenes.NewScene(s.SceneService))
+	r.GET("scenes/:sceneID", scenes.FindScene(s.SceneService))
+	r.PUT("scenes/:sceneID", scenes.UpdateScene(s.SceneService))
+	r.DELETE("scenes/:sceneID", scenes.DeleteScene(s.SceneService))
+	r.GET("hubs", hubs.FindHubs(s.HubService))
+	r.GET("hubs/:hubName/members", hubs.FindMembers(s.HubService))
+	r.PUT("hubs/:hubName/members/:username", hubs.SetMemberRole(s.HubService))
+	r.DELETE("hubs/:hubName/members/:username", hubs.RemoveMember(s.HubService))
+	r.POST("hubs/:hubName/invitations", hubs.NewInvitation(s.HubService))
+	r.POST("invitations/:code", hubs.AcceptInvitation(s.HubService))
+	r.GET("hubs/:hubName/devices", devices.FindPolicies(s.PolicyService))
+	r.PUT("hubs/:hubName/devices/:deviceID/policy", devices.SetPolicy(s.PolicyService))
+	r.DELETE("hubs/:hubName/devices/:deviceID/policy", devices.DeletePolicy(s.PolicyService))
+	r.GET("history", audit.FindHistory(s.AuditService))
+	r.GET("rules", rules.FindRules(s.RuleService))
+	r.POST("rules", rules.NewRule(s.RuleService))
+	r.GET("rules/:ruleID", rules.FindRule(s.RuleService))
+	r.PUT("rules/:ruleID", rules.UpdateRule(s.RuleService))
+	r.DELETE("rules/:ruleID", rules.DeleteRule(s.RuleService))
+	r.GET("webhook-tokens", webhooks.FindTokens(s.TokenService))
+	r.POST("hubs/:hubName/webhook-tokens", webhooks.NewToken(s.TokenService))
+	r.DELETE("webhook-tokens/:tokenID", webhooks.DeleteToken(s.TokenService))
+	if s.ControlService != nil {
+		r.GET("control/hubs", control.FindHubs(s.ControlService))
+		r.GET("control/hubs/:hubName/devices", control.ReadState(s.ControlService))
+		r.POST("control/hubs/:hubName/devices/:deviceID/actions", control.Execute(s.ControlService))
+		r.GET("events", control.Events(s.ControlService))
+	}
 
 	return e
 }
@@ -0,0 +1,118 @@
+package webhooks
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/hubs"
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/repository"
+	"github.com/c0dered273/automation-remote-controller/pkg/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+func usernameFromToken(c echo.Context) string {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(*auth.JwtCustomClaims)
+	return claims.Username
+}
+
+// FindTokens godoc
+//
+//	@Tags			webhook
+//	@Summary		Возвращает токены входящих webhook.
+//	@Description	Возвращает токены пользователя без значений.
+//	@ID				findWebhookTokens
+//	@Produce		json
+//	@Success		200	{array}		webhooks.Token
+//	@Failure		500	{string}	string	"Internal Server Error"
+//	@Router			/webhook-tokens [get]
+func FindTokens(service TokenService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		result, err := service.FindTokens(c.Request().Context(), usernameFromToken(c))
+		if err != nil {
+			c.Logger().Error(err)
+			return echo.ErrInternalServerError
+		}
+
+		return c.JSON(http.StatusOK, result)
+	}
+}
+
+// NewToken godoc
+//
+//	@Tags			webhook
+//	@Summary		Создает токен входящего webhook.
+//	@Description	Создает токен, с которым внешние системы (Alertmanager и другие) отправляют оповещения получателям хаба.
+//	@Description	Токен передается в заголовке Authorization: Bearer и возвращается только в этом ответе.
+//	@ID				newWebhookToken
+//	@Accept			json
+//	@Produce		json
+//	@Param			hub_name	path		string					true	"Hub name"
+//	@Param			request		body		webhooks.TokenRequest	true	"Token request"
+//	@Success		201			{object}	webhooks.Token
+//	@Failure		400			{string}	string	"Bad Request"
+//	@Failure		403			{string}	string	"Forbidden"
+//	@Failure		409			{string}	string	"Token already exists"
+//	@Failure		500			{string}	string	"Internal Server Error"
+//	@Router			/hubs/{hub_name}/webhook-tokens [post]
+func NewToken(service TokenService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		req := TokenRequest{}
+		if err := c.Bind(&req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		if err := c.Validate(req); err != nil {
+			c.Logger().Error(err)
+			return echo.ErrBadRequest
+		}
+
+		token, err := service.NewToken(c.Request().Context(), usernameFromToken(c), c.Param("hubName"), req)
+		if err != nil {
+			switch {
+			case errors.Is(err, hubs.ErrForbidden):
+				return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+			case errors.Is(err, repository.ErrAlreadyExists):
+				return echo.NewHTTPError(http.StatusConflict, "Token already exists")
+			}
+			c.Logger().Error(err)
+			return echo.ErrInternalServerError
+		}
+
+		return c.JSON(http.StatusCreated, token)
+	}
+}
+
+// DeleteToken godoc
+//
+//	@Tags			webhook
+//	@Summary		Отзывает токен входящего webhook.
+//	@Description	Удаляет токен, запросы с ним больше не принимаются.
+//	@ID				deleteWebhookToken
+//	@Param			token_id	path	int	true	"Token id"
+//	@Success		204
+//	@Failure		404	{string}	string	"Token not found"
+//	@Failure		500	{string}	string	"Internal Server Error"
+//	@Router			/webhook-tokens/{token_id} [delete]
+func DeleteToken(service TokenService) func(ctx echo.Context) error {
+	return func(c echo.Context) error {
+		id, err := strconv.ParseInt(c.Param("tokenID"), 10, 64)
+		if err != nil {
+			return echo.ErrBadRequest
+		}
+
+		err = service.DeleteToken(c.Request().Context(), usernameFromToken(c), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return echo.NewHTTPError(http.StatusNotFound, "Token not found")
+			}
+			c.Logger().Error(err)
+			return echo.ErrInternalServerError
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
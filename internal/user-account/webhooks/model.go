@@ -0,0 +1,37 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// tokenPrefix префикс токена, помогает опознать токен в конфигурации внешней системы
+const tokenPrefix = "rcw_"
+
+// Token токен входящего webhook, оповещения доставляются получателям хаба
+type Token struct {
+	ID        int64     `db:"id" json:"id"`
+	HubName   string    `db:"client_name" json:"hub"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	// Token значение токена, возвращается только при создании
+	Token string `db:"-" json:"token,omitempty"`
+}
+
+// TokenRequest запрос создания токена
+type TokenRequest struct {
+	Name string `json:"name" validate:"required,max=64"`
+}
+
+// newToken создает случайный токен и его хэш для хранения в БД
+func newToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := tokenPrefix + hex.EncodeToString(b)
+	sum := sha256.Sum256([]byte(token))
+	return token, hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,80 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/repository"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// TokenRepository описывает методы работы с токенами входящих webhook
+type TokenRepository interface {
+	// SaveToken сохраняет хэш нового токена
+	SaveToken(ctx context.Context, username string, hubID int64, name string, hash string) (Token, error)
+	// FindTokensByUsername возвращает токены пользователя
+	FindTokensByUsername(ctx context.Context, username string) ([]Token, error)
+	// DeleteTokenByUsername удаляет токен пользователя
+	DeleteTokenByUsername(ctx context.Context, username string, id int64) error
+}
+
+// SQLTokenRepo для хранения данных используется стандартный пакет database/sql c оберткой sqlx
+type SQLTokenRepo struct {
+	db *sqlx.DB
+}
+
+func (r SQLTokenRepo) SaveToken(ctx context.Context, username string, hubID int64, name string, hash string) (Token, error) {
+	const sqlQuery = `WITH t AS (
+			INSERT INTO inbound_tokens(user_id, client_id, name, token_hash)
+			VALUES((SELECT id FROM users WHERE username = $1), $2, $3, $4)
+			RETURNING id, client_id, name, created_at
+		)
+		SELECT t.id, c.name AS client_name, t.name, t.created_at FROM t JOIN clients c ON c.id = t.client_id`
+
+	token := Token{}
+	err := r.db.GetContext(ctx, &token, sqlQuery, username, hubID, name, hash)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return Token{}, repository.ErrAlreadyExists
+		}
+		return Token{}, err
+	}
+
+	return token, nil
+}
+
+func (r SQLTokenRepo) FindTokensByUsername(ctx context.Context, username string) ([]Token, error) {
+	const sqlQuery = `SELECT t.id, c.name AS client_name, t.name, t.created_at
+		FROM inbound_tokens t
+		         JOIN users u ON u.id = t.user_id
+		         JOIN clients c ON c.id = t.client_id
+		WHERE u.username = $1
+		ORDER BY t.name`
+
+	result := make([]Token, 0)
+	err := r.db.SelectContext(ctx, &result, sqlQuery, username)
+	return result, err
+}
+
+func (r SQLTokenRepo) DeleteTokenByUsername(ctx context.Context, username string, id int64) error {
+	const sqlQuery = `DELETE FROM inbound_tokens t USING users u WHERE t.user_id = u.id AND u.username = $1 AND t.id = $2`
+
+	res, err := r.db.ExecContext(ctx, sqlQuery, username, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func NewRepo(db *sqlx.DB) SQLTokenRepo {
+	return SQLTokenRepo{
+		db: db,
+	}
+}
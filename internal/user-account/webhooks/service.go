@@ -0,0 +1,58 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/c0dered273/automation-remote-controller/internal/user-account/hubs"
+	"github.com/c0dered273/automation-remote-controller/pkg/model"
+)
+
+// TokenService сервис управления токенами входящих webhook
+type TokenService interface {
+	// NewToken создает токен для хаба, пользователь должен иметь право отправлять команды хабу
+	NewToken(ctx context.Context, username string, hubName string, req TokenRequest) (Token, error)
+	// FindTokens возвращает токены пользователя без значений
+	FindTokens(ctx context.Context, username string) ([]Token, error)
+	// DeleteToken отзывает токен
+	DeleteToken(ctx context.Context, username string, id int64) error
+}
+
+type TokenServiceImpl struct {
+	tokenRepo  TokenRepository
+	hubService hubs.HubService
+}
+
+func (s TokenServiceImpl) NewToken(ctx context.Context, username string, hubName string, req TokenRequest) (Token, error) {
+	hub, err := s.hubService.Authorize(ctx, username, hubName, model.SendActions)
+	if err != nil {
+		return Token{}, err
+	}
+
+	value, hash, err := newToken()
+	if err != nil {
+		return Token{}, err
+	}
+	token, err := s.tokenRepo.SaveToken(ctx, username, hub.ID, req.Name, hash)
+	if err != nil {
+		return Token{}, fmt.Errorf("save token: %w", err)
+	}
+	token.Token = value
+
+	return token, nil
+}
+
+func (s TokenServiceImpl) FindTokens(ctx context.Context, username string) ([]Token, error) {
+	return s.tokenRepo.FindTokensByUsername(ctx, username)
+}
+
+func (s TokenServiceImpl) DeleteToken(ctx context.Context, username string, id int64) error {
+	return s.tokenRepo.DeleteTokenByUsername(ctx, username, id)
+}
+
+func NewTokenService(tokenRepo TokenRepository, hubService hubs.HubService) TokenServiceImpl {
+	return TokenServiceImpl{
+		tokenRepo:  tokenRepo,
+		hubService: hubService,
+	}
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS schedules;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,21 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS timezone varchar(64) NOT NULL DEFAULT 'UTC';
+
+CREATE TABLE IF NOT EXISTS schedules
+(
+    id          int GENERATED ALWAYS AS IDENTITY,
+    user_id     int         NOT NULL,
+    client_id   int         NOT NULL,
+    device_id   varchar(64) NOT NULL,
+    action      varchar(32) NOT NULL,
+    kind        varchar(8)  NOT NULL,
+    spec        varchar(64) NOT NULL,
+    misfire     varchar(8)  NOT NULL,
+    next_run_at timestamptz NOT NULL,
+    last_run_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_clients FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS schedules_next_run_at_idx ON schedules (next_run_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS scene_steps;
+DROP TABLE IF EXISTS scenes;
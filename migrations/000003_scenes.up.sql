@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS scenes
+(
+    id              int GENERATED ALWAYS AS IDENTITY,
+    user_id         int         NOT NULL,
+    client_id       int         NOT NULL,
+    name            varchar(64) NOT NULL,
+    stop_on_failure boolean     NOT NULL DEFAULT false,
+    PRIMARY KEY (id),
+    UNIQUE (user_id, name),
+    CONSTRAINT fk_users FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_clients FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS scene_steps
+(
+    id        int GENERATED ALWAYS AS IDENTITY,
+    scene_id  int         NOT NULL,
+    position  int         NOT NULL,
+    device_id varchar(64) NOT NULL,
+    action    varchar(32) NOT NULL,
+    delay_ms  int         NOT NULL DEFAULT 0,
+    PRIMARY KEY (id),
+    UNIQUE (scene_id, position),
+    CONSTRAINT fk_scenes FOREIGN KEY (scene_id) REFERENCES scenes (id) ON DELETE CASCADE
+);
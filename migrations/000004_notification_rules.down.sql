@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS alert_mutes;
+DROP TABLE IF EXISTS notification_prefs;
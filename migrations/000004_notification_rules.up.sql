@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS notification_prefs
+(
+    user_id     int NOT NULL,
+    quiet_start varchar(5),
+    quiet_end   varchar(5),
+    PRIMARY KEY (user_id),
+    CONSTRAINT fk_users FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS alert_mutes
+(
+    user_id     int         NOT NULL,
+    alert_key   varchar(16) NOT NULL,
+    alert_text  varchar(256) NOT NULL,
+    muted_until timestamptz NOT NULL,
+    PRIMARY KEY (user_id, alert_key),
+    CONSTRAINT fk_users FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
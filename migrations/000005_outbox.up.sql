@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS outbox
+(
+    id         bigint GENERATED ALWAYS AS IDENTITY,
+    chat_id    bigint      NOT NULL,
+    text       text        NOT NULL,
+    alert_key  varchar(16) NOT NULL DEFAULT '',
+    silent     boolean     NOT NULL DEFAULT false,
+    count      int         NOT NULL DEFAULT 1,
+    attempts   int         NOT NULL DEFAULT 0,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS hub_subscriptions
+(
+    client_id  int          NOT NULL,
+    chat_id    bigint       NOT NULL,
+    title      varchar(128) NOT NULL DEFAULT '',
+    added_by   int          NOT NULL,
+    created_at timestamptz  NOT NULL DEFAULT now(),
+    PRIMARY KEY (client_id, chat_id),
+    CONSTRAINT fk_clients FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE CASCADE,
+    CONSTRAINT fk_users FOREIGN KEY (added_by) REFERENCES users (id) ON DELETE CASCADE
+);
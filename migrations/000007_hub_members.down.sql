@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS hub_invitations;
+DROP TABLE IF EXISTS hub_members;
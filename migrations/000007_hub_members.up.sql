@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS hub_members
+(
+    client_id  int         NOT NULL,
+    user_id    int         NOT NULL,
+    role       varchar(16) NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (client_id, user_id),
+    CONSTRAINT fk_clients FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE CASCADE,
+    CONSTRAINT fk_users FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT chk_role CHECK (role IN ('owner', 'operator', 'viewer'))
+);
+
+INSERT INTO hub_members(client_id, user_id, role)
+SELECT id, user_id, 'owner'
+FROM clients
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS hub_invitations
+(
+    code       varchar(32) NOT NULL,
+    client_id  int         NOT NULL,
+    role       varchar(16) NOT NULL,
+    created_by int         NOT NULL,
+    expires_at timestamptz NOT NULL,
+    PRIMARY KEY (code),
+    CONSTRAINT fk_clients FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE CASCADE,
+    CONSTRAINT fk_users FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT chk_role CHECK (role IN ('owner', 'operator', 'viewer'))
+);
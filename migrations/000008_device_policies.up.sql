@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS device_policies
+(
+    client_id  int          NOT NULL,
+    device_id  varchar(64)  NOT NULL,
+    min_role   varchar(16)  NOT NULL DEFAULT 'operator',
+    confirm    boolean      NOT NULL DEFAULT false,
+    pin        varchar(128),
+    updated_at timestamptz  NOT NULL DEFAULT now(),
+    PRIMARY KEY (client_id, device_id),
+    CONSTRAINT fk_clients FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE CASCADE,
+    CONSTRAINT chk_min_role CHECK (min_role IN ('owner', 'operator'))
+);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id         bigint GENERATED ALWAYS AS IDENTITY,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    kind       varchar(16) NOT NULL,
+    user_id    int,
+    chat_id    bigint,
+    client_id  int,
+    device_id  varchar(64) NOT NULL DEFAULT '',
+    action     varchar(32) NOT NULL DEFAULT '',
+    source     varchar(16) NOT NULL DEFAULT '',
+    result     varchar(16) NOT NULL,
+    error      text        NOT NULL DEFAULT '',
+    latency_ms int         NOT NULL DEFAULT 0,
+    text       text        NOT NULL DEFAULT '',
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT fk_clients FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE CASCADE,
+    CONSTRAINT chk_kind CHECK (kind IN ('action', 'notification'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_client ON audit_log (client_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
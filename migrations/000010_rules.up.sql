@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS rules
+(
+    id            int GENERATED ALWAYS AS IDENTITY,
+    user_id       int         NOT NULL,
+    name          varchar(64) NOT NULL,
+    enabled       boolean     NOT NULL DEFAULT true,
+    condition     jsonb       NOT NULL,
+    actions       jsonb       NOT NULL,
+    cooldown_sec  int         NOT NULL DEFAULT 0,
+    last_fired_at timestamptz,
+    PRIMARY KEY (id),
+    UNIQUE (user_id, name),
+    CONSTRAINT fk_users FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT chk_cooldown CHECK (cooldown_sec >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rules_event ON rules ((condition ->> 'event')) WHERE enabled;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS notify_channels
+(
+    id         int GENERATED ALWAYS AS IDENTITY,
+    user_id    int          NOT NULL,
+    kind       varchar(16)  NOT NULL,
+    address    varchar(512) NOT NULL DEFAULT '',
+    secret     varchar(64)  NOT NULL DEFAULT '',
+    severities varchar(32)  NOT NULL DEFAULT 'low,normal,critical',
+    created_at timestamptz  NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    UNIQUE (user_id, kind, address),
+    CONSTRAINT fk_users FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT chk_kind CHECK (kind IN ('telegram', 'email', 'webhook'))
+);
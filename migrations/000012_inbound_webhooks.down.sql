@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS inbound_alerts;
+DROP TABLE IF EXISTS inbound_tokens;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS inbound_tokens
+(
+    id         int GENERATED ALWAYS AS IDENTITY,
+    user_id    int         NOT NULL,
+    client_id  int         NOT NULL,
+    name       varchar(64) NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    UNIQUE (token_hash),
+    UNIQUE (user_id, name),
+    CONSTRAINT fk_users FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_clients FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS inbound_alerts
+(
+    token_id   int          NOT NULL,
+    alert_id   varchar(128) NOT NULL,
+    firing_at  timestamptz  NOT NULL DEFAULT now(),
+    PRIMARY KEY (token_id, alert_id),
+    CONSTRAINT fk_inbound_tokens FOREIGN KEY (token_id) REFERENCES inbound_tokens (id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS language varchar(8) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE device_policies DROP CONSTRAINT IF EXISTS chk_device_id_lower;
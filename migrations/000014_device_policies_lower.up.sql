@@ -0,0 +1,21 @@
+-- Клиентское приложение сопоставляет идентификаторы устройств без учета регистра,
+-- поэтому правила хранятся с идентификатором в нижнем регистре.
+-- Из правил, отличающихся только регистром идентификатора, остается самое строгое.
+DELETE FROM device_policies p
+USING (SELECT client_id,
+              device_id,
+              row_number() OVER (
+                  PARTITION BY client_id, lower(device_id)
+                  ORDER BY min_role = 'owner' DESC, pin IS NOT NULL DESC, confirm DESC, updated_at DESC
+                  ) AS n
+       FROM device_policies) d
+WHERE p.client_id = d.client_id
+  AND p.device_id = d.device_id
+  AND d.n > 1;
+
+UPDATE device_policies
+SET device_id = lower(device_id)
+WHERE device_id <> lower(device_id);
+
+ALTER TABLE device_policies
+    ADD CONSTRAINT chk_device_id_lower CHECK (device_id = lower(device_id));
@@ -0,0 +1,3 @@
+ALTER TABLE schedules
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS failed_at;
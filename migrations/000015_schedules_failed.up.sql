@@ -0,0 +1,3 @@
+ALTER TABLE schedules
+    ADD COLUMN IF NOT EXISTS failed_at  timestamptz,
+    ADD COLUMN IF NOT EXISTS last_error varchar(256);
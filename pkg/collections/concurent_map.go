@@ -20,6 +20,15 @@ func (m *ConcurrentMap[T, E]) Put(key T, value E) {
 	m.storageMap[key] = value
 }
 
+// Take возвращает значение и удаляет его из хранилища
+func (m *ConcurrentMap[T, E]) Take(key T) (E, bool) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	v, ok := m.storageMap[key]
+	delete(m.storageMap, key)
+	return v, ok
+}
+
 func (m *ConcurrentMap[T, E]) IterateKeys() <-chan T {
 	c := make(chan T)
 	go func() {
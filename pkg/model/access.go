@@ -0,0 +1,65 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role роль пользователя в хабе
+type Role string
+
+const (
+	// RoleOwner полный доступ, включая управление участниками хаба
+	RoleOwner Role = "owner"
+	// RoleOperator просмотр состояния, уведомления и отправка команд устройствам
+	RoleOperator Role = "operator"
+	// RoleViewer только просмотр состояния и уведомления
+	RoleViewer Role = "viewer"
+)
+
+// Permission действие, доступ к которому определяется ролью
+type Permission uint8
+
+const (
+	ViewStatus Permission = iota
+	ReceiveNotifications
+	SendActions
+	ManageHub
+)
+
+var permissions = map[Role][]Permission{
+	RoleOwner:    {ViewStatus, ReceiveNotifications, SendActions, ManageHub},
+	RoleOperator: {ViewStatus, ReceiveNotifications, SendActions},
+	RoleViewer:   {ViewStatus, ReceiveNotifications},
+}
+
+// Can проверяет, разрешено ли действие для роли
+func (r Role) Can(p Permission) bool {
+	for _, allowed := range permissions[r] {
+		if allowed == p {
+			return true
+		}
+	}
+	return false
+}
+
+// ranks старшинство ролей
+var ranks = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleOwner:    3,
+}
+
+// AtLeast проверяет, что роль не ниже указанной
+func (r Role) AtLeast(min Role) bool {
+	return ranks[r] >= ranks[min]
+}
+
+// NewRole создает роль из строки
+func NewRole(s string) (Role, error) {
+	r := Role(strings.ToLower(s))
+	if _, ok := permissions[r]; !ok {
+		return "", fmt.Errorf("roles: failed to parse role <%s>", s)
+	}
+	return r, nil
+}
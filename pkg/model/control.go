@@ -0,0 +1,23 @@
+package model
+
+// InternalTokenHeader заголовок с общим секретом внутреннего API между user-account-api и rc-tg-bot
+const InternalTokenHeader = "X-Internal-Token"
+
+// HubStatus хаб пользователя и состояние подключения клиентского приложения
+type HubStatus struct {
+	Name   string `json:"name"`
+	Role   Role   `json:"role"`
+	Online bool   `json:"online"`
+}
+
+// CommandRequest команда устройству через HTTP API
+type CommandRequest struct {
+	// Action название команды, например SwitchON
+	Action string `json:"action" validate:"required"`
+	// Confirmed подтверждение команды для устройств, требующих подтверждения
+	Confirmed bool `json:"confirmed,omitempty"`
+	// PIN PIN-код устройства, если он задан
+	PIN string `json:"pin,omitempty"`
+	// Value значение для команды SetValue
+	Value *float64 `json:"value,omitempty"`
+}
@@ -1,7 +1,10 @@
 package model
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 )
 
@@ -13,6 +16,10 @@ const (
 	SwitchON
 	SwitchOFF
 	Toggle
+	// Pulse кратковременное включение выхода, например для импульсного реле или привода ворот
+	Pulse
+	// SetValue запись числового значения, например уставки температуры
+	SetValue
 )
 
 var actions = []string{
@@ -20,6 +27,8 @@ var actions = []string{
 	"SwitchON",
 	"SwitchOFF",
 	"Toggle",
+	"Pulse",
+	"SetValue",
 }
 
 func (t Action) String() string {
@@ -36,13 +45,126 @@ func NewAction(s string) (Action, error) {
 	return 0, fmt.Errorf("actions: failed to parse action <%s>", s)
 }
 
+// Severity уровень важности уведомления
+type Severity string
+
+const (
+	// SeverityLow уведомление отправляется без звука
+	SeverityLow Severity = "low"
+	// SeverityNormal уровень по умолчанию
+	SeverityNormal Severity = "normal"
+	// SeverityCritical уведомление отправляется даже в часы тишины
+	SeverityCritical Severity = "critical"
+)
+
+// NewSeverity создает уровень важности из строки, пустая строка соответствует SeverityNormal
+func NewSeverity(s string) (Severity, error) {
+	switch Severity(strings.ToLower(s)) {
+	case "", SeverityNormal:
+		return SeverityNormal, nil
+	case SeverityLow:
+		return SeverityLow, nil
+	case SeverityCritical:
+		return SeverityCritical, nil
+	}
+	return "", fmt.Errorf("severity: failed to parse severity <%s>", s)
+}
+
 // NotifyEvent payload для события уведомления
 type NotifyEvent struct {
-	Text string `json:"text"`
+	// AlertID идентификатор источника уведомления, например адрес тега
+	AlertID  string   `json:"alert_id,omitempty"`
+	Text     string   `json:"text"`
+	Severity Severity `json:"severity,omitempty"`
 }
 
 // ActionEvent payload для события действия
 type ActionEvent struct {
+	// ID идентификатор события, передается в заголовке события и возвращается в подтверждении
+	ID       string `json:"-"`
 	DeviceID string `json:"device_id"`
 	Action   Action `json:"action"`
+	// Value значение для команды SetValue в единицах устройства
+	Value *float64 `json:"value,omitempty"`
+}
+
+// AckEvent payload подтверждения выполнения команды клиентским приложением
+type AckEvent struct {
+	// ID идентификатор подтверждаемого события
+	ID       string `json:"-"`
+	DeviceID string `json:"device_id"`
+	Action   Action `json:"action"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+}
+
+// StateRequest payload запроса состояния устройств
+type StateRequest struct {
+	ID string `json:"-"`
+	// DeviceIDs список устройств, пустой список означает все устройства хаба
+	DeviceIDs []string `json:"device_ids,omitempty"`
+}
+
+// DeviceState состояние устройства, значение регистра контроллера в строковом виде
+type DeviceState struct {
+	DeviceID string `json:"device_id"`
+	// Room помещение, в котором установлено устройство
+	Room string `json:"room,omitempty"`
+	// Actions команды, доступные устройству
+	Actions []string `json:"actions,omitempty"`
+	Value   string   `json:"value,omitempty"`
+	// Setpoint допустимые значения для команды SetValue
+	Setpoint *Setpoint `json:"setpoint,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Setpoint диапазон и шаг значений уставки, Step 0 - шаг не ограничен
+type Setpoint struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Step float64 `json:"step,omitempty"`
+	Unit string  `json:"unit,omitempty"`
+}
+
+var (
+	ErrSetpointRange = errors.New("setpoint: value is out of range")
+	ErrSetpointStep  = errors.New("setpoint: value does not match step")
+)
+
+// setpointEpsilon допустимая погрешность при сравнении значения с границами и шагом уставки
+const setpointEpsilon = 1e-9
+
+// Check проверяет, что значение входит в диапазон уставки и кратно шагу от минимального значения
+func (s Setpoint) Check(v float64) error {
+	if math.IsNaN(v) || math.IsInf(v, 0) || v < s.Min-setpointEpsilon || v > s.Max+setpointEpsilon {
+		return fmt.Errorf("%w: %s, allowed %s..%s", ErrSetpointRange, FormatValue(v), FormatValue(s.Min), FormatValue(s.Max))
+	}
+	if s.Step > 0 {
+		n := (v - s.Min) / s.Step
+		if math.Abs(n-math.Round(n)) > setpointEpsilon*math.Max(1, math.Abs(n)) {
+			return fmt.Errorf("%w: %s, step %s", ErrSetpointStep, FormatValue(v), FormatValue(s.Step))
+		}
+	}
+	return nil
+}
+
+// Snap округляет значение до ближайшего допустимого значения уставки
+func (s Setpoint) Snap(v float64) float64 {
+	if s.Step > 0 {
+		v = s.Min + math.Round((v-s.Min)/s.Step)*s.Step
+	}
+	// убирает погрешность вычислений с плавающей точкой, например 20.300000000000004
+	v = math.Round(v*1e6) / 1e6
+	return math.Max(s.Min, math.Min(s.Max, v))
+}
+
+// FormatValue значение уставки в кратком виде, без лишних нулей и погрешности вычислений
+func FormatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 12, 64)
+}
+
+// StateEvent payload ответа на запрос состояния устройств
+type StateEvent struct {
+	ID      string        `json:"-"`
+	Devices []DeviceState `json:"devices"`
 }
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSetpointCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		setpoint Setpoint
+		value    float64
+		want     error
+	}{
+		{name: "min", setpoint: Setpoint{Min: 5, Max: 30, Step: 0.5}, value: 5},
+		{name: "max", setpoint: Setpoint{Min: 5, Max: 30, Step: 0.5}, value: 30},
+		{name: "below min", setpoint: Setpoint{Min: 5, Max: 30}, value: 4.99, want: ErrSetpointRange},
+		{name: "above max", setpoint: Setpoint{Min: 5, Max: 30}, value: 30.01, want: ErrSetpointRange},
+		{name: "max within epsilon", setpoint: Setpoint{Min: 5, Max: 30}, value: 30 + 1e-10},
+		{name: "NaN", setpoint: Setpoint{Min: 5, Max: 30}, value: math.NaN(), want: ErrSetpointRange},
+		{name: "infinity", setpoint: Setpoint{Min: 5, Max: 30}, value: math.Inf(1), want: ErrSetpointRange},
+		{name: "negative range", setpoint: Setpoint{Min: -20, Max: -5, Step: 5}, value: -15},
+		{name: "on step", setpoint: Setpoint{Min: 5, Max: 30, Step: 0.5}, value: 21.5},
+		{name: "off step", setpoint: Setpoint{Min: 5, Max: 30, Step: 0.5}, value: 21.3, want: ErrSetpointStep},
+		{name: "step counted from min", setpoint: Setpoint{Min: 0.2, Max: 10, Step: 1}, value: 3.2},
+		{name: "step not counted from zero", setpoint: Setpoint{Min: 0.2, Max: 10, Step: 1}, value: 3, want: ErrSetpointStep},
+		{name: "decimal step with float error", setpoint: Setpoint{Min: 0, Max: 1, Step: 0.1}, value: 0.3},
+		{name: "large value on step", setpoint: Setpoint{Min: 0, Max: 1e6, Step: 0.01}, value: 999999.99},
+		{name: "without step", setpoint: Setpoint{Min: 5, Max: 30}, value: 21.3456},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.setpoint.Check(tt.value)
+			if tt.want == nil && err != nil {
+				t.Fatalf("Check(%v) error = %v", tt.value, err)
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Check(%v) error = %v, want %v", tt.value, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetpointSnap(t *testing.T) {
+	tests := []struct {
+		name     string
+		setpoint Setpoint
+		value    float64
+		want     float64
+	}{
+		{name: "rounds to nearest step", setpoint: Setpoint{Min: 5, Max: 30, Step: 0.5}, value: 21.3, want: 21.5},
+		{name: "step counted from min", setpoint: Setpoint{Min: 0.2, Max: 10, Step: 1}, value: 3, want: 3.2},
+		{name: "removes float error", setpoint: Setpoint{Min: 0, Max: 30, Step: 0.1}, value: 20.3, want: 20.3},
+		{name: "clamps to min", setpoint: Setpoint{Min: 5, Max: 30, Step: 0.5}, value: -10, want: 5},
+		{name: "clamps to max", setpoint: Setpoint{Min: 5, Max: 30, Step: 0.5}, value: 100, want: 30},
+		{name: "without step", setpoint: Setpoint{Min: 5, Max: 30}, value: 21.3456, want: 21.3456},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.setpoint.Snap(tt.value)
+			if got != tt.want {
+				t.Errorf("Snap(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+			if err := tt.setpoint.Check(got); err != nil {
+				t.Errorf("Check(Snap(%v)) error = %v", tt.value, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RuleEvent тип события хаба, на которое срабатывает правило автоматизации
+type RuleEvent string
+
+const (
+	// EventNotification уведомление от клиентского приложения
+	EventNotification RuleEvent = "notification"
+	// EventHubOnline клиентское приложение подключилось
+	EventHubOnline RuleEvent = "hub_online"
+	// EventHubOffline клиентское приложение отключилось
+	EventHubOffline RuleEvent = "hub_offline"
+	// EventState ответ хаба с состоянием устройств, правила на это событие не срабатывают
+	EventState RuleEvent = "state"
+	// EventCommand результат отправки команды устройству, правила на это событие не срабатывают
+	EventCommand RuleEvent = "command"
+)
+
+// ruleClockLayout формат времени окна срабатывания правила
+const ruleClockLayout = "15:04"
+
+// RuleCondition условия срабатывания правила, пустые поля не проверяются
+type RuleCondition struct {
+	Event RuleEvent `json:"event" validate:"required,oneof=notification hub_online hub_offline"`
+	// Hub хаб - источник события
+	Hub string `json:"hub,omitempty"`
+	// TextContains подстрока текста уведомления, регистр не учитывается
+	TextContains string `json:"text_contains,omitempty"`
+	// TextRegexp регулярное выражение для текста уведомления
+	TextRegexp string `json:"text_regexp,omitempty"`
+	// AlertID шаблон идентификатора источника уведомления (тега), например leak_*
+	AlertID string `json:"alert_id,omitempty"`
+	// From, To окно времени ЧЧ:ММ в часовом поясе владельца правила, интервал может переходить через полночь
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// HubsOnline хабы, которые должны быть подключены в момент события
+	HubsOnline []string `json:"hubs_online,omitempty"`
+	// HubsOffline хабы, которые должны быть отключены в момент события
+	HubsOffline []string `json:"hubs_offline,omitempty"`
+}
+
+// Validate проверяет регулярное выражение, шаблон тега и окно времени
+func (c RuleCondition) Validate() error {
+	switch c.Event {
+	case EventNotification, EventHubOnline, EventHubOffline:
+	default:
+		return fmt.Errorf("rules: unknown event <%s>", c.Event)
+	}
+	if len(c.TextRegexp) != 0 {
+		if _, err := regexp.Compile(c.TextRegexp); err != nil {
+			return fmt.Errorf("rules: invalid text_regexp, %w", err)
+		}
+	}
+	if _, err := path.Match(c.AlertID, ""); err != nil {
+		return fmt.Errorf("rules: invalid alert_id pattern <%s>", c.AlertID)
+	}
+	if (len(c.From) == 0) != (len(c.To) == 0) {
+		return errors.New("rules: time window requires both from and to")
+	}
+	for _, clock := range []string{c.From, c.To} {
+		if len(clock) == 0 {
+			continue
+		}
+		if _, err := time.Parse(ruleClockLayout, clock); err != nil {
+			return fmt.Errorf("rules: invalid time <%s>, expected HH:MM", clock)
+		}
+	}
+	return nil
+}
+
+// MatchNotify проверяет условия на текст и источник уведомления
+func (c RuleCondition) MatchNotify(e NotifyEvent) bool {
+	if len(c.TextContains) != 0 && !strings.Contains(strings.ToLower(e.Text), strings.ToLower(c.TextContains)) {
+		return false
+	}
+	if len(c.TextRegexp) != 0 {
+		re, err := regexp.Compile(c.TextRegexp)
+		if err != nil || !re.MatchString(e.Text) {
+			return false
+		}
+	}
+	if len(c.AlertID) != 0 {
+		if ok, _ := path.Match(c.AlertID, e.AlertID); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// InWindow проверяет, попадает ли момент now в окно времени правила
+func (c RuleCondition) InWindow(now time.Time, loc *time.Location) bool {
+	if len(c.From) == 0 || len(c.To) == 0 {
+		return true
+	}
+	start, err := time.Parse(ruleClockLayout, c.From)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse(ruleClockLayout, c.To)
+	if err != nil {
+		return false
+	}
+
+	local := now.In(loc)
+	minutes := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from <= to {
+		return minutes >= from && minutes < to
+	}
+	return minutes >= from || minutes < to
+}
+
+// Value сохраняет условия в БД в формате json
+func (c RuleCondition) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Scan читает условия из json
+func (c *RuleCondition) Scan(src any) error {
+	return scanJSON(src, c)
+}
+
+// RuleActionType тип действия правила
+type RuleActionType string
+
+const (
+	// RuleActionDevice команда устройству хаба
+	RuleActionDevice RuleActionType = "device"
+	// RuleActionNotify уведомление получателям хаба, включая подписанные группы
+	RuleActionNotify RuleActionType = "notify"
+	// RuleActionScene запуск сценария владельца правила
+	RuleActionScene RuleActionType = "scene"
+)
+
+// RuleAction действие, выполняемое при срабатывании правила
+type RuleAction struct {
+	Type RuleActionType `json:"type" validate:"required,oneof=device notify scene"`
+	// Hub хаб, которому отправляется команда или получателям которого отправляется уведомление
+	Hub      string `json:"hub,omitempty"`
+	DeviceID string `json:"device_id,omitempty"`
+	Action   string `json:"action,omitempty"`
+	// Text текст уведомления, если не указан, пересылается текст исходного уведомления
+	Text     string   `json:"text,omitempty"`
+	Severity Severity `json:"severity,omitempty"`
+	SceneID  int64    `json:"scene_id,omitempty"`
+}
+
+// Validate проверяет обязательные для типа действия поля
+func (a RuleAction) Validate() error {
+	switch a.Type {
+	case RuleActionDevice:
+		if len(a.Hub) == 0 || len(a.DeviceID) == 0 {
+			return errors.New("rules: device action requires hub and device_id")
+		}
+		if _, err := NewAction(a.Action); err != nil {
+			return err
+		}
+	case RuleActionNotify:
+		if len(a.Hub) == 0 {
+			return errors.New("rules: notify action requires hub")
+		}
+		if _, err := NewSeverity(string(a.Severity)); err != nil {
+			return err
+		}
+	case RuleActionScene:
+		if a.SceneID <= 0 {
+			return errors.New("rules: scene action requires scene_id")
+		}
+	default:
+		return fmt.Errorf("rules: unknown action type <%s>", a.Type)
+	}
+	return nil
+}
+
+// RuleActions список действий правила, выполняются по порядку
+type RuleActions []RuleAction
+
+// Value сохраняет действия в БД в формате json
+func (a RuleActions) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+// Scan читает действия из json
+func (a *RuleActions) Scan(src any) error {
+	return scanJSON(src, a)
+}
+
+func scanJSON(src any, dst any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, dst)
+	case string:
+		return json.Unmarshal([]byte(v), dst)
+	case nil:
+		return nil
+	}
+	return fmt.Errorf("rules: unsupported json source %T", src)
+}